@@ -51,3 +51,8 @@ func (c *Client) DeleteConnectCluster(ctx context.Context, clusterId string) err
 	_, err := c.Delete(ctx, fmt.Sprintf(connectClusterItemPath, clusterId))
 	return err
 }
+
+// ListConnectClusters returns every Kafka Connect cluster in the environment.
+func (c *Client) ListConnectClusters(ctx context.Context, query map[string]string) ([]ConnectClusterVO, error) {
+	return listAll[ConnectClusterVO](ctx, c, connectClusterCollectionPath, query)
+}
@@ -57,7 +57,7 @@ func (c *Client) DeleteConnector(ctx context.Context, connectorId string) error
 }
 
 func (c *Client) PauseConnector(ctx context.Context, connectorId string) (*ConnectorVO, error) {
-	body, err := c.Post(ctx, fmt.Sprintf(connectorPausePath, connectorId), struct{}{})
+	body, err := c.postIdempotent(ctx, fmt.Sprintf(connectorPausePath, connectorId), struct{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +69,7 @@ func (c *Client) PauseConnector(ctx context.Context, connectorId string) (*Conne
 }
 
 func (c *Client) ResumeConnector(ctx context.Context, connectorId string) (*ConnectorVO, error) {
-	body, err := c.Post(ctx, fmt.Sprintf(connectorResumePath, connectorId), struct{}{})
+	body, err := c.postIdempotent(ctx, fmt.Sprintf(connectorResumePath, connectorId), struct{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -79,3 +79,8 @@ func (c *Client) ResumeConnector(ctx context.Context, connectorId string) (*Conn
 	}
 	return &result, nil
 }
+
+// ListConnectors returns every connector in the environment.
+func (c *Client) ListConnectors(ctx context.Context, query map[string]string) ([]ConnectorVO, error) {
+	return listAll[ConnectorVO](ctx, c, connectorCollectionPath, query)
+}
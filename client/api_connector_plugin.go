@@ -43,3 +43,8 @@ func (c *Client) DeleteConnectPlugin(ctx context.Context, pluginId string) error
 	_, err := c.Delete(ctx, fmt.Sprintf(pluginItemPath, pluginId))
 	return err
 }
+
+// ListConnectPlugins returns every connector plugin in the environment.
+func (c *Client) ListConnectPlugins(ctx context.Context, query map[string]string) ([]ConnectPluginVO, error) {
+	return listAll[ConnectPluginVO](ctx, c, pluginCollectionPath, query)
+}
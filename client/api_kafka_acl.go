@@ -28,7 +28,7 @@ func (c *Client) CreateKafkaAcls(ctx context.Context, instanceId string, param K
 }
 
 func (c *Client) DeleteKafkaAcls(ctx context.Context, instanceId string, param KafkaAclBindingParams) error {
-	_, err := c.Post(ctx, fmt.Sprintf(DeleteAclPath, instanceId), param)
+	_, err := c.postIdempotent(ctx, fmt.Sprintf(DeleteAclPath, instanceId), param)
 	if err != nil {
 		return err
 	}
@@ -61,3 +61,8 @@ func (c *Client) GetKafkaAcls(ctx context.Context, instanceId string, aclId stri
 	}
 	return &acl.List[0], nil
 }
+
+// ListKafkaAcls returns every ACL binding of a Kafka instance.
+func (c *Client) ListKafkaAcls(ctx context.Context, instanceId string, query map[string]string) ([]KafkaAclBindingVO, error) {
+	return listAll[KafkaAclBindingVO](ctx, c, fmt.Sprintf(KafkaAclPath, instanceId), query)
+}
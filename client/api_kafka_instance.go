@@ -44,22 +44,14 @@ func (c *Client) GetKafkaInstance(ctx context.Context, instanceId string) (*Inst
 func (c *Client) GetKafkaInstanceByName(ctx context.Context, name string) (*InstanceVO, error) {
 	queryParams := make(map[string]string)
 	queryParams["keyword"] = name
-	body, err := c.Get(ctx, InstancePath, queryParams)
+	instances, err := c.ListKafkaInstances(ctx, queryParams)
 	if err != nil {
 		return nil, err
 	}
-	instances := PageNumResultInstanceVO{}
-	err = json.Unmarshal(body, &instances)
-	if err != nil {
-		return nil, err
-	}
-	if len(instances.List) > 0 {
-		for _, item := range instances.List {
-			if *item.Name == name {
-				return &item, nil
-			}
+	for _, item := range instances {
+		if item.Name != nil && *item.Name == name {
+			return &item, nil
 		}
-		return nil, &ErrorResponse{Code: 404, ErrorMessage: "kafka instance not found"}
 	}
 	return nil, &ErrorResponse{Code: 404, ErrorMessage: "kafka instance not found"}
 }
@@ -112,3 +104,8 @@ func (c *Client) updateInstance(ctx context.Context, instanceId string, updatePa
 
 	return nil
 }
+
+// ListKafkaInstances returns every Kafka instance in the environment.
+func (c *Client) ListKafkaInstances(ctx context.Context, query map[string]string) ([]InstanceVO, error) {
+	return listAll[InstanceVO](ctx, c, InstancePath, query)
+}
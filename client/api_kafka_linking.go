@@ -39,16 +39,13 @@ func (c *Client) GetKafkaLink(ctx context.Context, instanceID, linkID string) (*
 	return link, nil
 }
 
+// ListKafkaLinks returns every link of a Kafka instance.
 func (c *Client) ListKafkaLinks(ctx context.Context, instanceID string, query map[string]string) (*PageNumResultKafkaLinkVO, error) {
-	body, err := c.Get(ctx, fmt.Sprintf(kafkaLinksPath, instanceID), query)
+	items, err := listAll[KafkaLinkVO](ctx, c, fmt.Sprintf(kafkaLinksPath, instanceID), query)
 	if err != nil {
 		return nil, err
 	}
-	result := &PageNumResultKafkaLinkVO{}
-	if err := json.Unmarshal(body, result); err != nil {
-		return nil, err
-	}
-	return result, nil
+	return &PageNumResultKafkaLinkVO{List: items}, nil
 }
 
 func (c *Client) DeleteKafkaLink(ctx context.Context, instanceID, linkID string) error {
@@ -71,17 +68,13 @@ func (c *Client) CreateKafkaLinkMirrorTopics(ctx context.Context, instanceID, li
 	return result, nil
 }
 
+// ListKafkaLinkMirrorTopics returns every mirror topic of a link.
 func (c *Client) ListKafkaLinkMirrorTopics(ctx context.Context, instanceID, linkID string, query map[string]string) (*PageNumResultMirrorTopicVO, error) {
-	path := fmt.Sprintf(kafkaLinkMirrorTopicsPath, instanceID, linkID)
-	body, err := c.Get(ctx, path, query)
+	items, err := listAll[MirrorTopicVO](ctx, c, fmt.Sprintf(kafkaLinkMirrorTopicsPath, instanceID, linkID), query)
 	if err != nil {
 		return nil, err
 	}
-	result := &PageNumResultMirrorTopicVO{}
-	if err := json.Unmarshal(body, result); err != nil {
-		return nil, err
-	}
-	return result, nil
+	return &PageNumResultMirrorTopicVO{List: items}, nil
 }
 
 func (c *Client) UpdateKafkaLinkMirrorTopic(ctx context.Context, instanceID, linkID, topicID string, param KafkaLinkMirrorTopicsUpdateParam) error {
@@ -113,17 +106,13 @@ func (c *Client) CreateKafkaLinkMirrorGroups(ctx context.Context, instanceID, li
 	return result, nil
 }
 
+// ListKafkaLinkMirrorGroups returns every mirror consumer group of a link.
 func (c *Client) ListKafkaLinkMirrorGroups(ctx context.Context, instanceID, linkID string, query map[string]string) (*PageNumResultMirrorConsumerGroupVO, error) {
-	path := fmt.Sprintf(kafkaLinkMirrorGroupsPath, instanceID, linkID)
-	body, err := c.Get(ctx, path, query)
+	items, err := listAll[MirrorConsumerGroupVO](ctx, c, fmt.Sprintf(kafkaLinkMirrorGroupsPath, instanceID, linkID), query)
 	if err != nil {
 		return nil, err
 	}
-	result := &PageNumResultMirrorConsumerGroupVO{}
-	if err := json.Unmarshal(body, result); err != nil {
-		return nil, err
-	}
-	return result, nil
+	return &PageNumResultMirrorConsumerGroupVO{List: items}, nil
 }
 
 func (c *Client) DeleteKafkaLinkMirrorGroup(ctx context.Context, instanceID, linkID, groupID string) error {
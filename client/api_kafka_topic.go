@@ -71,3 +71,8 @@ func (c *Client) GetKafkaTopic(ctx context.Context, instanceId string, topicId s
 	}
 	return &topic, nil
 }
+
+// ListKafkaTopics returns every topic of a Kafka instance.
+func (c *Client) ListKafkaTopics(ctx context.Context, instanceId string, query map[string]string) ([]TopicVO, error) {
+	return listAll[TopicVO](ctx, c, fmt.Sprintf(TopicPath, instanceId), query)
+}
@@ -36,21 +36,21 @@ func (c *Client) DeleteKafkaUser(ctx context.Context, instanceId string, userNam
 }
 
 func (c *Client) GetKafkaUser(ctx context.Context, instanceId string, userName string) (*KafkaUserVO, error) {
-	path := fmt.Sprintf(KafkaUserPath, instanceId)
 	queryParams := make(map[string]string)
 	queryParams["userNames"] = userName
-	body, err := c.Get(ctx, path, queryParams)
-	if err != nil {
-		return nil, err
-	}
-	userPage := PageNumResultKafkaUserVO{}
-	err = json.Unmarshal(body, &userPage)
+	users, err := c.ListKafkaUsers(ctx, instanceId, queryParams)
 	if err != nil {
 		return nil, err
 	}
-	if len(userPage.List) == 0 {
-		return nil, &ErrorResponse{Code: 404, ErrorMessage: "user not found"}
+	for _, user := range users {
+		if user.Name == userName {
+			return &user, nil
+		}
 	}
-	user := userPage.List[0]
-	return &user, nil
+	return nil, &ErrorResponse{Code: 404, ErrorMessage: "user not found"}
+}
+
+// ListKafkaUsers returns every user of a Kafka instance.
+func (c *Client) ListKafkaUsers(ctx context.Context, instanceId string, query map[string]string) ([]KafkaUserVO, error) {
+	return listAll[KafkaUserVO](ctx, c, fmt.Sprintf(KafkaUserPath, instanceId), query)
 }
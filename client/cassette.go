@@ -0,0 +1,301 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cassetteHeaders are the only headers written to cassettes. Signatures,
+// security tokens, dates and trace context change on every request and are
+// left out, so cassettes never hold credentials and replay deterministically.
+var cassetteHeaders = []string{
+	"Content-Type",
+	"X-Automq-Environment-Id",
+	"X-Automq-Request-Id",
+	"Retry-After",
+}
+
+// Cassette is a recording of control plane interactions. It is stored as
+// YAML with the values of secret fields replaced with "***".
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Interaction is one recorded request and the response it got.
+type Interaction struct {
+	Request  CassetteRequest  `yaml:"request"`
+	Response CassetteResponse `yaml:"response"`
+}
+
+// CassetteRequest is a recorded request. Query holds the sorted query
+// parameters and Body the scrubbed, indented JSON payload.
+type CassetteRequest struct {
+	Method  string            `yaml:"method"`
+	Path    string            `yaml:"path"`
+	Query   string            `yaml:"query,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+}
+
+// CassetteResponse is the recorded response to a CassetteRequest.
+type CassetteResponse struct {
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+}
+
+// CassetteTransport is an http.RoundTripper that either records the traffic
+// of a Client to a cassette file or serves a Client from one, without
+// network access:
+//
+//	recorder := client.NewRecordingTransport("testdata/create.yaml", nil)
+//	c.HTTPClient.Transport = recorder
+//	... // run the requests against a real control plane
+//	err := recorder.Save()
+//
+//	replayer, err := client.NewReplayTransport("testdata/create.yaml")
+//	c.HTTPClient.Transport = replayer
+//
+// When replaying, a request is answered with the first unused interaction of
+// the same method, path and query whose body is the same JSON value once
+// secrets are scrubbed. A request without such an interaction fails, which
+// makes cassettes a check of the exact payloads a Client sends.
+type CassetteTransport struct {
+	path string
+	next http.RoundTripper // nil when replaying
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecordingTransport returns a CassetteTransport that sends requests
+// through next, or http.DefaultTransport when next is nil, and records them.
+// Save writes the recording to path.
+func NewRecordingTransport(path string, next http.RoundTripper) *CassetteTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &CassetteTransport{path: path, next: next}
+}
+
+// NewReplayTransport returns a CassetteTransport that answers requests from
+// the cassette at path.
+func NewReplayTransport(path string) (*CassetteTransport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	t := &CassetteTransport{path: path}
+	if err := yaml.Unmarshal(data, &t.cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	t.used = make([]bool, len(t.cassette.Interactions))
+	return t, nil
+}
+
+func (t *CassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := newCassetteRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.next == nil {
+		return t.replay(req, request)
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(data))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Request: request,
+		Response: CassetteResponse{
+			Status:  res.StatusCode,
+			Headers: cassetteHeaderValues(res.Header),
+			Body:    scrubBody(data),
+		},
+	})
+	return res, nil
+}
+
+func (t *CassetteTransport) replay(req *http.Request, request CassetteRequest) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var candidate *CassetteRequest
+	for i, interaction := range t.cassette.Interactions {
+		recorded := interaction.Request
+		if t.used[i] || recorded.Method != request.Method || recorded.Path != request.Path || recorded.Query != request.Query {
+			continue
+		}
+		if !sameBody(recorded.Body, request.Body) {
+			if candidate == nil {
+				candidate = &t.cassette.Interactions[i].Request
+			}
+			continue
+		}
+		t.used[i] = true
+
+		res := &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+			StatusCode:    interaction.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}
+		for name, value := range interaction.Response.Headers {
+			res.Header.Set(name, value)
+		}
+		return res, nil
+	}
+
+	if candidate != nil {
+		return nil, fmt.Errorf("cassette %s: body of %s %s does not match the recording\nrecorded: %s\nsent:     %s",
+			t.path, request.Method, request.Path, compactJSON(candidate.Body), compactJSON(request.Body))
+	}
+	return nil, fmt.Errorf("cassette %s: no unused interaction for %s %s", t.path, request.Method, request.URI())
+}
+
+// Save writes the recorded interactions to the cassette file, creating its
+// directory if needed.
+func (t *CassetteTransport) Save() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(t.cassette); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, data.Bytes(), 0o600)
+}
+
+// Unused returns the method and URI of the interactions that have not been
+// replayed, so that tests can check every recorded request was sent.
+func (t *CassetteTransport) Unused() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var unused []string
+	for i, interaction := range t.cassette.Interactions {
+		if !t.used[i] {
+			unused = append(unused, interaction.Request.Method+" "+interaction.Request.URI())
+		}
+	}
+	return unused
+}
+
+// URI returns the path and query of r.
+func (r CassetteRequest) URI() string {
+	if r.Query == "" {
+		return r.Path
+	}
+	return r.Path + "?" + r.Query
+}
+
+func newCassetteRequest(req *http.Request) (CassetteRequest, error) {
+	request := CassetteRequest{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Query:   canonicalQuery(req.URL.RawQuery),
+		Headers: cassetteHeaderValues(req.Header),
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return request, err
+		}
+		data, err := io.ReadAll(body)
+		_ = body.Close()
+		if err != nil {
+			return request, err
+		}
+		request.Body = scrubBody(data)
+	}
+	return request, nil
+}
+
+// canonicalQuery sorts the query parameters so that the order a Client adds
+// them in does not matter.
+func canonicalQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
+func cassetteHeaderValues(header http.Header) map[string]string {
+	var values map[string]string
+	for _, name := range cassetteHeaders {
+		if value := header.Get(name); value != "" {
+			if values == nil {
+				values = map[string]string{}
+			}
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// scrubBody returns a JSON body indented and with the values of
+// redactedFields replaced. Bodies that are not JSON are kept as they are.
+func scrubBody(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return string(data)
+	}
+	scrubbed, err := json.MarshalIndent(redactValue(body), "", "  ")
+	if err != nil {
+		return string(data)
+	}
+	return string(scrubbed) + "\n"
+}
+
+// sameBody reports whether two scrubbed bodies hold the same JSON value, or
+// the same text when they are not JSON.
+func sameBody(a, b string) bool {
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
+
+func compactJSON(body string) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(body)); err != nil {
+		return strings.TrimSpace(body)
+	}
+	return buf.String()
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Automq-Request-Id", "req-1")
+		w.Header().Set("Set-Cookie", "session=cookie-secret")
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"name":"alice","password":"response-secret"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"ResourceNotFound","message":"user bob not found"}}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "users.yaml")
+	ctx := context.WithValue(context.Background(), EnvIdKey, "env-1")
+	creds := AuthCredentials{AccessKeyID: "ak", SecretAccessKey: "sk", SessionToken: "token-secret"}
+
+	recorder := NewRecordingTransport(path, nil)
+	c, _ := NewClient(ctx, server.URL, creds, func(c *Client) { c.HTTPClient.Transport = recorder })
+	if _, err := c.CreateKafkaUser(ctx, "kf-1", InstanceUserCreateParam{Name: "alice", Password: "request-secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetKafkaUser(ctx, "kf-1", "bob"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetKafkaUser() error = %v, want ErrNotFound", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"request-secret", "response-secret", "token-secret", "cookie-secret", "AUTOMQ-HMAC-SHA256", "X-Automq-Date"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+
+	replayer, err := NewReplayTransport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ = NewClient(ctx, "http://replay.invalid", AuthCredentials{AccessKeyID: "other", SecretAccessKey: "other"},
+		func(c *Client) { c.HTTPClient.Transport = replayer })
+	// Scrubbed fields match whatever value is sent.
+	user, err := c.CreateKafkaUser(ctx, "kf-1", InstanceUserCreateParam{Name: "alice", Password: "another-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "alice" {
+		t.Errorf("replayed user = %+v", user)
+	}
+	var apiErr *ErrorResponse
+	if _, err := c.GetKafkaUser(ctx, "kf-1", "bob"); !errors.As(err, &apiErr) || apiErr.RequestID != "req-1" || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("replayed GetKafkaUser() error = %v, want ErrNotFound with request ID", err)
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("Unused() = %v", unused)
+	}
+}
+
+func TestCassetteReplayRejectsDifferentPayload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "topic.yaml")
+	cassette := `interactions:
+  - request:
+      method: POST
+      path: /api/v1/instances/kf-1/topics
+      body: |
+        {"name": "orders", "partition": 16, "compactStrategy": "DELETE"}
+    response:
+      status: 200
+      body: '{"topicId":"t-1","name":"orders","partition":16}'
+`
+	if err := os.WriteFile(path, []byte(cassette), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := NewReplayTransport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), EnvIdKey, "env-1")
+	c, _ := NewClient(ctx, "http://replay.invalid", AuthCredentials{AccessKeyID: "ak", SecretAccessKey: "sk"},
+		func(c *Client) { c.HTTPClient.Transport = replayer; c.MaxRetries = 0 })
+
+	_, err = c.CreateKafkaTopic(ctx, "kf-1", TopicCreateParam{Name: "orders", Partition: 32, CompactStrategy: "DELETE"})
+	if err == nil || !strings.Contains(err.Error(), "does not match the recording") {
+		t.Fatalf("CreateKafkaTopic() error = %v, want a payload mismatch", err)
+	}
+	if got := replayer.Unused(); len(got) != 1 {
+		t.Fatalf("Unused() = %v, want the unmatched interaction", got)
+	}
+
+	topic, err := c.CreateKafkaTopic(ctx, "kf-1", TopicCreateParam{Name: "orders", Partition: 16, CompactStrategy: "DELETE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.TopicId != "t-1" {
+		t.Errorf("TopicId = %q, want t-1", topic.TopicId)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"terraform-provider-automq/client/signer"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 type Client struct {
@@ -18,9 +20,30 @@ type Client struct {
 	HTTPClient  *http.Client
 	Token       string
 	Credentials AuthCredentials
-	Signer      *signer.Signer
-	MaxRetries  int
-	RetryDelay  time.Duration
+	// CredentialsProvider, when set, is asked for credentials before each
+	// request so that short-lived credentials are refreshed during long
+	// runs. Credentials is used otherwise.
+	CredentialsProvider CredentialsProvider
+	Signer              *signer.Signer
+	MaxRetries          int
+	// RetryDelay is the base delay of the exponential backoff between retries.
+	RetryDelay time.Duration
+	// MaxBackoff caps the delay between two retries.
+	MaxBackoff time.Duration
+	// RequestTimeout bounds a single HTTP attempt, not the whole retry loop.
+	RequestTimeout time.Duration
+	// MaxRequestsPerSecond and MaxConcurrentRequests limit the traffic sent
+	// to each environment. Zero disables the corresponding limit. They are
+	// read once by NewClient.
+	MaxRequestsPerSecond  float64
+	MaxConcurrentRequests int
+	// WaitScale multiplies the initial delay and poll interval of the state
+	// waits run with this client. Zero leaves them unchanged; tests against
+	// the in-memory control plane lower it.
+	WaitScale float64
+
+	throttle  *requestThrottle
+	clockSkew clockSkew
 }
 
 type EnvironmentID string
@@ -30,6 +53,11 @@ const EnvIdKey EnvironmentID = "environment_id"
 type AuthCredentials struct {
 	AccessKeyID     string
 	SecretAccessKey string
+	// SessionToken is set for short-lived credentials.
+	SessionToken string
+	// Expires is when short-lived credentials stop being valid. It is zero
+	// for long-lived Service Account keys.
+	Expires time.Time
 }
 
 type ErrorResponse struct {
@@ -37,6 +65,15 @@ type ErrorResponse struct {
 	ErrorMessage string   `json:"error_message"`
 	APIError     APIError `json:"api_error"`
 	Err          error
+	// RequestID is the control plane's ID of the failed request. AutoMQ
+	// support can use it to trace the request.
+	RequestID string
+	// ClockSkew is how far the control plane's clock is ahead of the local
+	// clock (negative when behind), set when the request was rejected for
+	// being signed outside the accepted time window.
+	ClockSkew time.Duration
+
+	retryAfter time.Duration
 }
 
 type APIError struct {
@@ -65,31 +102,57 @@ func (e *ErrorResponse) Error() string {
 	if e.Err != nil {
 		errMsg.WriteString(fmt.Sprintf("Error: %s\n", e.Err.Error()))
 	}
+	if e.RequestID != "" {
+		errMsg.WriteString(fmt.Sprintf("Request ID: %s\n", e.RequestID))
+	}
+	if e.ClockSkew != 0 {
+		direction := "behind"
+		if e.ClockSkew < 0 {
+			direction = "ahead of"
+		}
+		errMsg.WriteString(fmt.Sprintf("Clock Skew: the local clock is %s %s the control plane clock. Synchronize the system clock (e.g. with NTP).\n", e.ClockSkew.Abs(), direction))
+	}
 	return errMsg.String()
 }
 
-func NewClient(ctx context.Context, host string, credentials AuthCredentials) (*Client, error) {
+func NewClient(ctx context.Context, host string, credentials AuthCredentials, options ...func(*Client)) (*Client, error) {
 	c := &Client{
-		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		HTTPClient:  &http.Client{},
 		HostURL:     host,
 		Credentials: credentials,
 		Signer: signer.NewSigner(signer.Credentials{
 			AccessKeyID:     credentials.AccessKeyID,
 			SecretAccessKey: credentials.SecretAccessKey,
+			SessionToken:    credentials.SessionToken,
 		}),
-		MaxRetries: 3,
-		RetryDelay: 500 * time.Millisecond,
+		MaxRetries:     DefaultMaxRetries,
+		RetryDelay:     DefaultRetryDelay,
+		MaxBackoff:     DefaultMaxBackoff,
+		RequestTimeout: DefaultRequestTimeout,
+	}
+	for _, option := range options {
+		option(c)
 	}
+	c.throttle = newRequestThrottle(c.MaxRequestsPerSecond, c.MaxConcurrentRequests)
 	return c, nil
 }
 
-func (c *Client) retryOperation(operation func() ([]byte, error)) ([]byte, error) {
+// retryOperation runs operation until it succeeds, fails with an error that
+// is not retryable, exhausts MaxRetries or ctx is done. The last error is
+// returned unchanged so callers can still inspect the *ErrorResponse.
+func (c *Client) retryOperation(ctx context.Context, idempotent bool, operation func() ([]byte, error)) ([]byte, error) {
 	var lastErr error
-	for i := 0; i <= c.MaxRetries; i++ {
-		if i > 0 {
-			time.Sleep(c.RetryDelay)
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := c.backoff(attempt, lastErr)
+			tflog.Debug(ctx, "Retrying AutoMQ API request", map[string]interface{}{
+				"attempt": attempt,
+				"delay":   delay.String(),
+				"error":   lastErr.Error(),
+			})
+			if err := sleepWithContext(ctx, delay); err != nil {
+				return nil, lastErr
+			}
 		}
 
 		result, err := operation()
@@ -98,53 +161,75 @@ func (c *Client) retryOperation(operation func() ([]byte, error)) ([]byte, error
 		}
 
 		lastErr = err
-		// only retry on server errors (5xx) or network errors
-		if e, ok := err.(*ErrorResponse); ok {
-			if e.Code < 500 && e.Code != 0 {
-				return nil, err
-			}
+		if !shouldRetry(ctx, idempotent, err) {
+			return nil, err
 		}
 	}
-	return nil, fmt.Errorf("after %d retries: %v", c.MaxRetries, lastErr)
+	return nil, lastErr
 }
 
 func (c *Client) Post(ctx context.Context, path string, body interface{}) ([]byte, error) {
-	b, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
-	}
-	return c.doRequest(ctx, "POST", path, strings.NewReader(string(b)))
+	return c.send(ctx, http.MethodPost, path, body, false)
+}
+
+// postIdempotent sends a POST for actions the control plane treats as
+// idempotent (pause, resume, batch delete), so it may be retried like a GET.
+func (c *Client) postIdempotent(ctx context.Context, path string, body interface{}) ([]byte, error) {
+	return c.send(ctx, http.MethodPost, path, body, true)
 }
 
 func (c *Client) Get(ctx context.Context, path string, queryParams map[string]string) ([]byte, error) {
 	if queryParams != nil {
 		path += "?" + buildQueryParams(queryParams)
 	}
-
-	operation := func() ([]byte, error) {
-		return c.doRequest(ctx, "GET", path, nil)
-	}
-	return c.retryOperation(operation)
+	return c.send(ctx, http.MethodGet, path, nil, true)
 }
 
 func (c *Client) Delete(ctx context.Context, path string) ([]byte, error) {
-	return c.doRequest(ctx, "DELETE", path, nil)
+	return c.send(ctx, http.MethodDelete, path, nil, true)
 }
 
 func (c *Client) Put(ctx context.Context, path string, body interface{}) ([]byte, error) {
-	b, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
-	}
-	return c.doRequest(ctx, "PUT", path, strings.NewReader(string(b)))
+	return c.send(ctx, http.MethodPut, path, body, true)
 }
 
+// Patch requests often start a long running change of the resource, such as
+// a scaling or an upgrade, so they are not retried like a GET.
 func (c *Client) Patch(ctx context.Context, path string, body interface{}) ([]byte, error) {
-	b, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
+	return c.send(ctx, http.MethodPatch, path, body, false)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body interface{}, idempotent bool) ([]byte, error) {
+	var payload []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = b
 	}
-	return c.doRequest(ctx, "PATCH", path, strings.NewReader(string(b)))
+	skewCorrected, reauthenticated := false, false
+	operation := func() ([]byte, error) {
+		data, err := c.doRequest(ctx, method, path, payload)
+		// A request rejected for clock skew was never acted on, so it is
+		// signed again with the corrected time once, whatever its method.
+		if err != nil && !skewCorrected && c.correctClockSkew(err) {
+			skewCorrected = true
+			tflog.Warn(ctx, "Retrying AutoMQ API request with corrected clock skew", map[string]interface{}{
+				"clock_skew": c.clockSkew.get().String(),
+			})
+			data, err = c.doRequest(ctx, method, path, payload)
+		}
+		// Neither was a request rejected as unauthenticated, it is signed
+		// again once with credentials retrieved anew.
+		if err != nil && !reauthenticated && c.invalidateCredentials(err) {
+			reauthenticated = true
+			tflog.Warn(ctx, "Retrying AutoMQ API request with refreshed credentials")
+			data, err = c.doRequest(ctx, method, path, payload)
+		}
+		return data, err
+	}
+	return c.retryOperation(ctx, idempotent, operation)
 }
 
 func buildQueryParams(queryParams map[string]string) string {
@@ -155,31 +240,62 @@ func buildQueryParams(queryParams map[string]string) string {
 	return query.Encode()
 }
 
-func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader) ([]byte, error) {
-	req, err := http.NewRequest(method, c.HostURL+path, body)
+// requestSigner returns the signer for the next request, carrying the
+// current credentials when a CredentialsProvider is configured. The copy
+// shares the signing key cache of c.Signer.
+func (c *Client) requestSigner(ctx context.Context) (signer.Signer, error) {
+	s := *c.Signer
+	if c.CredentialsProvider == nil {
+		return s, nil
+	}
+	creds, err := c.CredentialsProvider.Retrieve(ctx)
 	if err != nil {
-		return nil, err
+		return s, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept-Language", "en")
+	s.Credential = signer.Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+	}
+	return s, nil
+}
+
+func (c *Client) doRequest(ctx context.Context, method, path string, payload []byte) (data []byte, err error) {
 	environmentID, ok := ctx.Value(EnvIdKey).(string)
-	if ok {
-		req.Header.Set("X-automq-environment-id", environmentID)
-	} else {
+	if !ok {
 		return nil, &ErrorResponse{Code: 0, ErrorMessage: "Error getting environment ID from context"}
 	}
+	ctx, span := startRequestSpan(ctx, method, c.HostURL, path, environmentID)
+	defer func() { endRequestSpan(span, err) }()
 
-	var seeker io.ReadSeeker
-	if sr, ok := body.(io.ReadSeeker); ok {
-		seeker = sr
-	} else if rc, ok := body.(io.ReadCloser); ok {
-		data, err := io.ReadAll(rc)
-		if err != nil {
-			return nil, &ErrorResponse{Code: 0, ErrorMessage: "Error reading request body", Err: err}
-		}
-		seeker = bytes.NewReader(data)
+	release, err := c.throttle.acquire(ctx, environmentID)
+	if err != nil {
+		return nil, &ErrorResponse{Code: 0, ErrorMessage: "Error waiting for a request slot", Err: err}
+	}
+	defer release()
+	if c.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+		defer cancel()
 	}
-	_, err = c.Signer.Sign(req, seeker, "cmp", "private", time.Now())
+
+	var body io.ReadSeeker
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.HostURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "en")
+	req.Header.Set("X-automq-environment-id", environmentID)
+
+	reqSigner, err := c.requestSigner(ctx)
+	if err != nil {
+		return nil, &ErrorResponse{Code: 0, ErrorMessage: "Error retrieving credentials", Err: err}
+	}
+	_, err = reqSigner.Sign(req, body, "cmp", "private", c.clockSkew.now())
 	if err != nil {
 		return nil, &ErrorResponse{Code: 0, ErrorMessage: "Error signing request", Err: err}
 	}
@@ -188,6 +304,8 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body io.Rea
 	if err != nil {
 		return nil, &ErrorResponse{Code: 0, ErrorMessage: "Error sending request", Err: err}
 	}
+	skew := c.clockSkew.observe(res, time.Now())
+	recordResponse(span, res)
 	defer func() {
 		closeErr := res.Body.Close()
 		if closeErr != nil {
@@ -195,18 +313,25 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body io.Rea
 		}
 	}()
 
-	data, err := io.ReadAll(res.Body)
+	data, err = io.ReadAll(res.Body)
 	if err != nil {
-		return nil, &ErrorResponse{Code: res.StatusCode, ErrorMessage: "Error reading response body", Err: err}
+		return nil, &ErrorResponse{Code: res.StatusCode, ErrorMessage: "Error reading response body", Err: err, RequestID: requestID(res.Header)}
 	}
 
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		apiError := APIError{}
-		err = json.Unmarshal(data, &apiError)
-		if err != nil {
-			return nil, &ErrorResponse{Code: res.StatusCode, ErrorMessage: string(data), Err: err}
+		errResp := &ErrorResponse{
+			Code:       res.StatusCode,
+			RequestID:  requestID(res.Header),
+			retryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+		}
+		if err := json.Unmarshal(data, &errResp.APIError); err != nil {
+			errResp.ErrorMessage = string(data)
+			errResp.Err = err
+		}
+		if isClockSkewError(errResp) {
+			errResp.ClockSkew = skew
 		}
-		return nil, &ErrorResponse{Code: res.StatusCode, APIError: apiError}
+		return nil, errResp
 	}
 	return data, nil
 }
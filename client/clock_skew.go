@@ -0,0 +1,89 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// clockSkewThreshold is the smallest difference between the local clock and
+// the control plane's Date header that is treated as skew. Smaller offsets
+// are network latency and Date's one-second resolution.
+const clockSkewThreshold = time.Minute
+
+// clockSkewErrorCodes are the ErrorModel codes the control plane returns when
+// a request's X-Automq-Date is outside the accepted signing window.
+var clockSkewErrorCodes = map[string]struct{}{
+	"RequestTimeTooSkewed": {},
+	"RequestExpired":       {},
+	"SignatureExpired":     {},
+	"InvalidSignatureDate": {},
+	"SIGNATURE_EXPIRED":    {},
+	"REQUEST_TIME_SKEWED":  {},
+}
+
+// clockSkew is the offset added to the local clock when signing requests. It
+// is shared by all requests of a Client.
+type clockSkew struct {
+	offset atomic.Int64
+}
+
+func (s *clockSkew) now() time.Time {
+	return time.Now().Add(s.get())
+}
+
+func (s *clockSkew) get() time.Duration {
+	return time.Duration(s.offset.Load())
+}
+
+func (s *clockSkew) set(d time.Duration) {
+	s.offset.Store(int64(d))
+}
+
+// observe records the offset between the control plane's Date header and the
+// local time a response was received at, when it is large enough to matter.
+// It returns the measured offset, or zero if the header is missing.
+func (s *clockSkew) observe(res *http.Response, received time.Time) time.Duration {
+	serverTime, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return 0
+	}
+	offset := serverTime.Sub(received).Truncate(time.Second)
+	if offset.Abs() >= clockSkewThreshold {
+		s.set(offset)
+	} else if s.get() != 0 && offset.Abs() < clockSkewThreshold/2 {
+		// The local clock has been fixed (or was never skewed).
+		s.set(0)
+	}
+	return offset
+}
+
+// isClockSkewError reports whether err is the control plane rejecting a
+// request signed with a time outside its signing window.
+func isClockSkewError(err error) bool {
+	e, ok := err.(*ErrorResponse)
+	if !ok || (e.Code != http.StatusUnauthorized && e.Code != http.StatusForbidden) {
+		return false
+	}
+	if _, ok := clockSkewErrorCodes[e.APIError.ErrorModel.Code]; ok {
+		return true
+	}
+	message := strings.ToLower(e.APIError.ErrorModel.Message)
+	return strings.Contains(message, "skew") || strings.Contains(message, "request has expired") || strings.Contains(message, "signature expired")
+}
+
+// correctClockSkew adopts the offset measured on a clock skew rejection so
+// the request can be signed again with the control plane's time. It reports
+// whether re-signing is worthwhile.
+func (c *Client) correctClockSkew(err error) bool {
+	if !isClockSkewError(err) {
+		return false
+	}
+	e := err.(*ErrorResponse)
+	if e.ClockSkew == 0 {
+		return false
+	}
+	c.clockSkew.set(e.ClockSkew)
+	return true
+}
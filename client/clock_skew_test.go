@@ -0,0 +1,92 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// skewedServer behaves like a control plane whose clock runs ahead of the
+// local one and that rejects requests signed more than 5 minutes off.
+func skewedServer(t *testing.T, ahead time.Duration, calls *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		serverNow := time.Now().Add(ahead)
+		w.Header().Set("Date", serverNow.UTC().Format(http.TimeFormat))
+
+		signed, err := time.Parse("20060102T150405Z", r.Header.Get("X-Automq-Date"))
+		if err != nil {
+			t.Errorf("parse X-Automq-Date: %v", err)
+		}
+		if serverNow.Sub(signed).Abs() > 5*time.Minute {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":"RequestTimeTooSkewed","message":"The difference between the request time and the server's time is too large."}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}
+}
+
+func TestClockSkewIsCorrectedAndRequestResigned(t *testing.T) {
+	var calls int32
+	c, ctx := newTestClient(t, skewedServer(t, 20*time.Minute, &calls))
+
+	if _, err := c.Post(ctx, "/api/v1/instances", struct{}{}); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2 (rejected + re-signed)", calls)
+	}
+	if skew := c.clockSkew.get(); skew < 19*time.Minute || skew > 21*time.Minute {
+		t.Fatalf("clock skew = %s, want ~20m", skew)
+	}
+
+	// Later requests are signed with the corrected time straight away.
+	if _, err := c.Get(ctx, "/api/v1/instances", nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestClockSkewErrorMentionsSkew(t *testing.T) {
+	var calls int32
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Date", time.Now().Add(-2*time.Hour).UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":"SignatureExpired","message":"signature expired"}}`))
+	})
+
+	_, err := c.Get(ctx, "/api/v1/instances", nil)
+	e, ok := err.(*ErrorResponse)
+	if !ok {
+		t.Fatalf("Get() error = %v, want *ErrorResponse", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want exactly one skew retry", calls)
+	}
+	if e.ClockSkew > -119*time.Minute || !strings.Contains(e.Error(), "ahead of the control plane clock") {
+		t.Fatalf("error = %q (skew %s), want it to report the local clock 2h ahead", e.Error(), e.ClockSkew)
+	}
+}
+
+func TestForbiddenWithoutSkewIsNotRetried(t *testing.T) {
+	var calls int32
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":"AccessDenied","message":"denied"}}`))
+	})
+
+	_, err := c.Get(ctx, "/api/v1/instances", nil)
+	if e, ok := err.(*ErrorResponse); !ok || e.ClockSkew != 0 || strings.Contains(e.Error(), "Clock Skew") {
+		t.Fatalf("Get() error = %v, want plain 403", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
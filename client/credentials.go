@@ -0,0 +1,364 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	EnvAccessKey             = "AUTOMQ_BYOC_ACCESS_KEY"
+	EnvAccessKeyID           = "AUTOMQ_BYOC_ACCESS_KEY_ID"
+	EnvSecretKey             = "AUTOMQ_BYOC_SECRET_KEY"
+	EnvSessionToken          = "AUTOMQ_BYOC_SESSION_TOKEN"
+	EnvProfile               = "AUTOMQ_PROFILE"
+	EnvSharedCredentialsFile = "AUTOMQ_SHARED_CREDENTIALS_FILE"
+
+	DefaultProfile = "default"
+)
+
+// ErrCredentialsNotFound is returned by a CredentialsProvider that has no
+// credentials to offer. A ChainCredentialsProvider moves on to the next
+// provider on this error and stops on any other.
+var ErrCredentialsNotFound = errors.New("no AutoMQ credentials found")
+
+// CredentialsProvider supplies the Service Account access key used to sign
+// control plane requests.
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (AuthCredentials, error)
+}
+
+// StaticCredentialsProvider returns fixed credentials, typically taken from
+// the provider block.
+type StaticCredentialsProvider struct {
+	Credentials AuthCredentials
+}
+
+func (p StaticCredentialsProvider) Retrieve(ctx context.Context) (AuthCredentials, error) {
+	if p.Credentials.AccessKeyID == "" || p.Credentials.SecretAccessKey == "" {
+		return AuthCredentials{}, fmt.Errorf("static credentials: %w", ErrCredentialsNotFound)
+	}
+	return p.Credentials, nil
+}
+
+// EnvCredentialsProvider reads AUTOMQ_BYOC_ACCESS_KEY (or
+// AUTOMQ_BYOC_ACCESS_KEY_ID), AUTOMQ_BYOC_SECRET_KEY and the optional
+// AUTOMQ_BYOC_SESSION_TOKEN.
+type EnvCredentialsProvider struct{}
+
+func (EnvCredentialsProvider) Retrieve(ctx context.Context) (AuthCredentials, error) {
+	accessKey := os.Getenv(EnvAccessKey)
+	if accessKey == "" {
+		accessKey = os.Getenv(EnvAccessKeyID)
+	}
+	secretKey := os.Getenv(EnvSecretKey)
+	if accessKey == "" || secretKey == "" {
+		return AuthCredentials{}, fmt.Errorf("environment variables: %w", ErrCredentialsNotFound)
+	}
+	return AuthCredentials{AccessKeyID: accessKey, SecretAccessKey: secretKey, SessionToken: os.Getenv(EnvSessionToken)}, nil
+}
+
+// SharedCredentialsProvider reads a profile from an INI credentials file,
+// ~/.automq/credentials by default:
+//
+//	[default]
+//	access_key_id     = ...
+//	secret_access_key = ...
+//	session_token     = ... (optional)
+//
+//	[prod]
+//	credential_process = vault-automq-creds prod
+//
+// A profile either holds the key pair or names a credential_process.
+type SharedCredentialsProvider struct {
+	// Filename defaults to AUTOMQ_SHARED_CREDENTIALS_FILE, then
+	// ~/.automq/credentials.
+	Filename string
+	// Profile defaults to AUTOMQ_PROFILE, then "default".
+	Profile string
+}
+
+func (p SharedCredentialsProvider) Retrieve(ctx context.Context) (AuthCredentials, error) {
+	filename, err := p.filename()
+	if err != nil {
+		return AuthCredentials{}, err
+	}
+	profile := p.profile()
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && p.Filename == "" && p.Profile == "" {
+			return AuthCredentials{}, fmt.Errorf("shared credentials file %s: %w", filename, ErrCredentialsNotFound)
+		}
+		return AuthCredentials{}, fmt.Errorf("failed to read shared credentials file: %w", err)
+	}
+	sections, err := parseINI(data)
+	if err != nil {
+		return AuthCredentials{}, fmt.Errorf("failed to parse shared credentials file %s: %w", filename, err)
+	}
+	values, ok := sections[profile]
+	if !ok {
+		if p.Profile == "" && os.Getenv(EnvProfile) == "" {
+			return AuthCredentials{}, fmt.Errorf("profile %q in %s: %w", profile, filename, ErrCredentialsNotFound)
+		}
+		return AuthCredentials{}, fmt.Errorf("profile %q not found in shared credentials file %s", profile, filename)
+	}
+
+	if command := values["credential_process"]; command != "" {
+		return ProcessCredentialsProvider{Command: command}.Retrieve(ctx)
+	}
+	creds := AuthCredentials{
+		AccessKeyID:     values["access_key_id"],
+		SecretAccessKey: values["secret_access_key"],
+		SessionToken:    values["session_token"],
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return AuthCredentials{}, fmt.Errorf("profile %q in %s must set access_key_id and secret_access_key, or credential_process", profile, filename)
+	}
+	return creds, nil
+}
+
+func (p SharedCredentialsProvider) filename() (string, error) {
+	if p.Filename != "" {
+		return expandHome(p.Filename)
+	}
+	if filename := os.Getenv(EnvSharedCredentialsFile); filename != "" {
+		return expandHome(filename)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate home directory for shared credentials file: %w", err)
+	}
+	return filepath.Join(home, ".automq", "credentials"), nil
+}
+
+func (p SharedCredentialsProvider) profile() string {
+	if p.Profile != "" {
+		return p.Profile
+	}
+	if profile := os.Getenv(EnvProfile); profile != "" {
+		return profile
+	}
+	return DefaultProfile
+}
+
+// ProcessCredentialsProvider runs an external command and reads credentials
+// from its standard output as JSON:
+//
+//	{"Version": 1, "AccessKeyId": "...", "SecretAccessKey": "..."}
+//
+// Short-lived credentials add "SessionToken" and an RFC 3339 "Expiration".
+type ProcessCredentialsProvider struct {
+	Command string
+}
+
+type processCredentialsOutput struct {
+	Version         int        `json:"Version"`
+	AccessKeyID     string     `json:"AccessKeyId"`
+	SecretAccessKey string     `json:"SecretAccessKey"`
+	SessionToken    string     `json:"SessionToken"`
+	Expiration      *time.Time `json:"Expiration"`
+}
+
+func (p ProcessCredentialsProvider) Retrieve(ctx context.Context) (AuthCredentials, error) {
+	if strings.TrimSpace(p.Command) == "" {
+		return AuthCredentials{}, fmt.Errorf("credential_process: %w", ErrCredentialsNotFound)
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd.exe", "/C", p.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", p.Command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+	if err := cmd.Run(); err != nil {
+		return AuthCredentials{}, fmt.Errorf("credential_process failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	out := processCredentialsOutput{}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return AuthCredentials{}, fmt.Errorf("credential_process returned invalid JSON: %w", err)
+	}
+	if out.Version != 1 {
+		return AuthCredentials{}, fmt.Errorf("credential_process returned unsupported Version %d, expected 1", out.Version)
+	}
+	if out.AccessKeyID == "" || out.SecretAccessKey == "" {
+		return AuthCredentials{}, errors.New("credential_process output must contain AccessKeyId and SecretAccessKey")
+	}
+	creds := AuthCredentials{
+		AccessKeyID:     out.AccessKeyID,
+		SecretAccessKey: out.SecretAccessKey,
+		SessionToken:    out.SessionToken,
+	}
+	if out.Expiration != nil {
+		creds.Expires = *out.Expiration
+	}
+	return creds, nil
+}
+
+// ChainCredentialsProvider returns the credentials of the first provider
+// that has some.
+type ChainCredentialsProvider struct {
+	Providers []CredentialsProvider
+}
+
+func (c ChainCredentialsProvider) Retrieve(ctx context.Context) (AuthCredentials, error) {
+	var notFound []string
+	for _, provider := range c.Providers {
+		creds, err := provider.Retrieve(ctx)
+		if err == nil {
+			return creds, nil
+		}
+		if !errors.Is(err, ErrCredentialsNotFound) {
+			return AuthCredentials{}, err
+		}
+		notFound = append(notFound, strings.TrimSuffix(err.Error(), ": "+ErrCredentialsNotFound.Error()))
+	}
+	return AuthCredentials{}, fmt.Errorf("%w, checked: %s", ErrCredentialsNotFound, strings.Join(notFound, ", "))
+}
+
+// DefaultExpiryWindow is how long before expiry CredentialsCache refreshes
+// short-lived credentials, so that a request signed just before expiry is
+// not rejected in flight.
+const DefaultExpiryWindow = 5 * time.Minute
+
+// CredentialsCache wraps a CredentialsProvider and reuses its credentials
+// until they are about to expire. Credentials without an expiry are
+// retrieved once.
+type CredentialsCache struct {
+	Provider     CredentialsProvider
+	ExpiryWindow time.Duration
+
+	mu     sync.Mutex
+	creds  AuthCredentials
+	cached bool
+	now    func() time.Time
+}
+
+// NewCredentialsCache returns a CredentialsCache using DefaultExpiryWindow.
+func NewCredentialsCache(provider CredentialsProvider) *CredentialsCache {
+	return &CredentialsCache{Provider: provider, ExpiryWindow: DefaultExpiryWindow}
+}
+
+func (c *CredentialsCache) Retrieve(ctx context.Context) (AuthCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached && !c.expired() {
+		return c.creds, nil
+	}
+	creds, err := c.Provider.Retrieve(ctx)
+	if err != nil {
+		return AuthCredentials{}, err
+	}
+	tflog.Debug(ctx, "Retrieved AutoMQ credentials", map[string]interface{}{
+		"access_key_id": creds.AccessKeyID,
+		"expires":       creds.Expires,
+	})
+	c.creds = creds
+	c.cached = true
+	return creds, nil
+}
+
+// Invalidate drops the cached credentials so the next Retrieve asks the
+// wrapped provider again. Client calls it when the control plane rejects
+// the cached credentials.
+func (c *CredentialsCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cached = false
+}
+
+// invalidateCredentials drops the cached credentials when the control plane
+// rejected a request as unauthenticated, so that rotated or revoked keys are
+// not used until they expire. It reports whether signing the request again
+// is worthwhile.
+func (c *Client) invalidateCredentials(err error) bool {
+	e, ok := err.(*ErrorResponse)
+	if !ok || (e.Code != http.StatusUnauthorized && e.Code != http.StatusForbidden) || isClockSkewError(err) {
+		return false
+	}
+	cache, ok := c.CredentialsProvider.(*CredentialsCache)
+	if !ok {
+		return false
+	}
+	cache.Invalidate()
+	return true
+}
+
+func (c *CredentialsCache) expired() bool {
+	if c.creds.Expires.IsZero() {
+		return false
+	}
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	return !now().Add(c.ExpiryWindow).Before(c.creds.Expires)
+}
+
+// parseINI reads the minimal INI dialect of the shared credentials file:
+// [section] headers, key = value pairs and ; or # comments. "[profile name]"
+// is accepted as an alias of "[name]".
+func parseINI(data []byte) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	var current map[string]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section header", lineNo)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty section name", lineNo)
+			}
+			if _, ok := sections[name]; !ok {
+				sections[name] = make(map[string]string)
+			}
+			current = sections[name]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: key outside of a section", lineNo)
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return sections, scanner.Err()
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
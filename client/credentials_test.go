@@ -0,0 +1,258 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCredentialsFile = `
+# shared AutoMQ credentials
+[default]
+access_key_id     = default-ak
+secret_access_key = default-sk
+
+[profile prod]
+access_key_id = prod-ak
+secret_access_key = prod-sk
+
+[ci]
+credential_process = echo '{"Version": 1, "AccessKeyId": "ci-ak", "SecretAccessKey": "ci-sk"}'
+
+[broken]
+access_key_id = only-ak
+`
+
+func writeCredentialsFile(t *testing.T) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(filename, []byte(testCredentialsFile), 0o600); err != nil {
+		t.Fatalf("write credentials file: %v", err)
+	}
+	return filename
+}
+
+func clearCredentialsEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{EnvAccessKey, EnvAccessKeyID, EnvSecretKey, EnvProfile, EnvSharedCredentialsFile} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestSharedCredentialsProviderProfiles(t *testing.T) {
+	clearCredentialsEnv(t)
+	filename := writeCredentialsFile(t)
+
+	cases := map[string]string{
+		"":      "default-ak",
+		"prod":  "prod-ak",
+		"ci":    "ci-ak",
+		"other": "",
+	}
+	if runtime.GOOS == "windows" {
+		delete(cases, "ci")
+	}
+	for profile, wantAK := range cases {
+		creds, err := SharedCredentialsProvider{Filename: filename, Profile: profile}.Retrieve(context.Background())
+		if wantAK == "" {
+			if err == nil || errors.Is(err, ErrCredentialsNotFound) {
+				t.Errorf("profile %q: error = %v, want a hard error for an explicit missing profile", profile, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("profile %q: Retrieve() error = %v", profile, err)
+			continue
+		}
+		if creds.AccessKeyID != wantAK {
+			t.Errorf("profile %q: AccessKeyID = %q, want %q", profile, creds.AccessKeyID, wantAK)
+		}
+	}
+
+	if _, err := (SharedCredentialsProvider{Filename: filename, Profile: "broken"}).Retrieve(context.Background()); err == nil {
+		t.Error("profile broken: expected an error for a profile without secret_access_key")
+	}
+}
+
+func TestSharedCredentialsProviderUsesEnvironmentProfile(t *testing.T) {
+	clearCredentialsEnv(t)
+	t.Setenv(EnvSharedCredentialsFile, writeCredentialsFile(t))
+	t.Setenv(EnvProfile, "prod")
+
+	creds, err := SharedCredentialsProvider{}.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if creds.AccessKeyID != "prod-ak" || creds.SecretAccessKey != "prod-sk" {
+		t.Fatalf("creds = %+v, want prod profile", creds)
+	}
+}
+
+func TestChainCredentialsProviderOrder(t *testing.T) {
+	clearCredentialsEnv(t)
+	t.Setenv(EnvSharedCredentialsFile, filepath.Join(t.TempDir(), "missing"))
+	chain := ChainCredentialsProvider{Providers: []CredentialsProvider{
+		StaticCredentialsProvider{},
+		EnvCredentialsProvider{},
+		SharedCredentialsProvider{},
+		ProcessCredentialsProvider{},
+	}}
+
+	_, err := chain.Retrieve(context.Background())
+	if !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("Retrieve() error = %v, want ErrCredentialsNotFound", err)
+	}
+
+	t.Setenv(EnvAccessKeyID, "env-ak")
+	t.Setenv(EnvSecretKey, "env-sk")
+	creds, err := chain.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if creds.AccessKeyID != "env-ak" {
+		t.Fatalf("AccessKeyID = %q, want env-ak", creds.AccessKeyID)
+	}
+
+	chain.Providers[0] = StaticCredentialsProvider{Credentials: AuthCredentials{AccessKeyID: "static-ak", SecretAccessKey: "static-sk"}}
+	creds, err = chain.Retrieve(context.Background())
+	if err != nil || creds.AccessKeyID != "static-ak" {
+		t.Fatalf("Retrieve() = %+v, %v, want static credentials first", creds, err)
+	}
+}
+
+func TestProcessCredentialsProviderRejectsBadOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	cases := map[string]string{
+		"echo not-json":         "invalid JSON",
+		`echo '{"Version": 2}'`: "unsupported Version",
+		`echo '{"Version": 1}'`: "must contain AccessKeyId",
+		"echo oops >&2; exit 3": "oops",
+	}
+	for command, want := range cases {
+		_, err := ProcessCredentialsProvider{Command: command}.Retrieve(context.Background())
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error = %v, want it to contain %q", command, err, want)
+		}
+	}
+}
+
+func TestParseINIErrors(t *testing.T) {
+	for _, input := range []string{"key = value", "[unterminated", "[default]\nno-equals"} {
+		if _, err := parseINI([]byte(input)); err == nil {
+			t.Errorf("parseINI(%q) expected error", input)
+		}
+	}
+}
+
+type countingCredentialsProvider struct {
+	calls   int
+	expires time.Time
+}
+
+func (p *countingCredentialsProvider) Retrieve(ctx context.Context) (AuthCredentials, error) {
+	p.calls++
+	return AuthCredentials{AccessKeyID: fmt.Sprintf("ak-%d", p.calls), SecretAccessKey: "sk", SessionToken: "token", Expires: p.expires}, nil
+}
+
+func TestCredentialsCacheRefreshesBeforeExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := &countingCredentialsProvider{expires: now.Add(time.Hour)}
+	cache := NewCredentialsCache(provider)
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		creds, err := cache.Retrieve(context.Background())
+		if err != nil || creds.AccessKeyID != "ak-1" {
+			t.Fatalf("Retrieve() = %+v, %v, want cached ak-1", creds, err)
+		}
+	}
+
+	now = now.Add(56 * time.Minute)
+	provider.expires = now.Add(time.Hour)
+	creds, err := cache.Retrieve(context.Background())
+	if err != nil || creds.AccessKeyID != "ak-2" {
+		t.Fatalf("Retrieve() = %+v, %v, want refreshed ak-2 inside the expiry window", creds, err)
+	}
+
+	cache.Invalidate()
+	if creds, _ := cache.Retrieve(context.Background()); creds.AccessKeyID != "ak-3" {
+		t.Fatalf("AccessKeyID = %q after Invalidate, want ak-3", creds.AccessKeyID)
+	}
+}
+
+func TestProcessCredentialsProviderSessionCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	command := `echo '{"Version": 1, "AccessKeyId": "ak", "SecretAccessKey": "sk", "SessionToken": "tok", "Expiration": "2024-01-01T13:30:00Z"}'`
+	creds, err := ProcessCredentialsProvider{Command: command}.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if creds.SessionToken != "tok" || !creds.Expires.Equal(time.Date(2024, 1, 1, 13, 30, 0, 0, time.UTC)) {
+		t.Fatalf("creds = %+v, want session token and expiration", creds)
+	}
+}
+
+func TestClientSignsWithRefreshedCredentials(t *testing.T) {
+	var tokens []string
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("X-Automq-Security-Token"))
+		_, _ = w.Write([]byte(`{}`))
+	})
+	provider := &countingCredentialsProvider{}
+	c.CredentialsProvider = provider
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(ctx, "/api/v1/instances", nil); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+	if provider.calls != 2 || fmt.Sprint(tokens) != "[token token]" {
+		t.Fatalf("provider calls = %d, tokens = %v", provider.calls, tokens)
+	}
+}
+
+func TestClientRetrievesCredentialsAgainWhenRejected(t *testing.T) {
+	var keys []string
+	rejected := map[string]bool{"ak-1": true}
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(strings.Fields(r.Header.Get("Authorization"))[1], "Credential=")
+		key = strings.Split(key, "/")[0]
+		keys = append(keys, key)
+		if rejected[key] {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code": "InvalidAccessKeyId", "message": "access key revoked"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	provider := &countingCredentialsProvider{}
+	c.CredentialsProvider = NewCredentialsCache(provider)
+
+	if _, err := c.Get(ctx, "/api/v1/instances", nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if provider.calls != 2 || fmt.Sprint(keys) != "[ak-1 ak-2]" {
+		t.Fatalf("provider calls = %d, keys = %v, want the request signed again with new credentials", provider.calls, keys)
+	}
+
+	// Credentials that are still rejected are retrieved again only once.
+	keys = nil
+	rejected["ak-2"], rejected["ak-3"] = true, true
+	if _, err := c.Get(ctx, "/api/v1/instances", nil); err == nil {
+		t.Fatal("Get() error = nil, want the authentication error")
+	}
+	if fmt.Sprint(keys) != "[ak-2 ak-3]" {
+		t.Fatalf("keys = %v, want one more attempt", keys)
+	}
+}
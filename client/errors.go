@@ -0,0 +1,110 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Kinds of control plane errors. An *ErrorResponse matches one of them with
+// errors.Is, so callers do not have to inspect status or error codes:
+//
+//	if errors.Is(err, client.ErrNotFound) { ... }
+//
+// Use errors.As with a *ErrorResponse to get the code, message and request ID.
+var (
+	ErrNotFound         = errors.New("resource not found")
+	ErrConflict         = errors.New("resource already exists")
+	ErrThrottled        = errors.New("request throttled")
+	ErrInvalidParameter = errors.New("invalid parameter")
+	// ErrStateConflict is returned when the resource is not in a state that
+	// allows the operation, e.g. updating an instance that is still changing.
+	ErrStateConflict = errors.New("resource state does not allow the operation")
+)
+
+// requestIDHeaders are the response headers the control plane returns the
+// request ID in, in order of preference.
+var requestIDHeaders = []string{"X-Automq-Request-Id", "X-Request-Id"}
+
+// errorCodeKinds maps fragments of ErrorModel.Code to an error kind. Codes
+// are compared lower-cased with '_', '-' and '.' removed, so that
+// "Topic.AlreadyExists" and "TOPIC_ALREADY_EXISTS" both match "alreadyexist".
+// State conflicts are checked before plain conflicts. Not-found codes are
+// deliberately absent: Read drops resources from state on ErrNotFound, and
+// codes such as "ENVIRONMENT_NOT_FOUND" on a 400 or 403 do not mean the
+// resource is gone.
+var errorCodeKinds = []struct {
+	fragment string
+	kind     error
+}{
+	{"invalidstate", ErrStateConflict},
+	{"invalidstatus", ErrStateConflict},
+	{"incorrectstate", ErrStateConflict},
+	{"illegalstate", ErrStateConflict},
+	{"stateconflict", ErrStateConflict},
+	{"notallowedinstate", ErrStateConflict},
+	{"alreadyexist", ErrConflict},
+	{"duplicate", ErrConflict},
+	{"conflict", ErrConflict},
+	{"throttl", ErrThrottled},
+	{"toomanyrequests", ErrThrottled},
+	{"ratelimit", ErrThrottled},
+	{"invalidparam", ErrInvalidParameter},
+	{"invalidargument", ErrInvalidParameter},
+	{"illegalargument", ErrInvalidParameter},
+	{"missingparam", ErrInvalidParameter},
+	{"validation", ErrInvalidParameter},
+}
+
+// statusKinds is the fallback when the error code is unknown.
+var statusKinds = map[int]error{
+	http.StatusBadRequest:          ErrInvalidParameter,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusConflict:            ErrConflict,
+	http.StatusUnprocessableEntity: ErrInvalidParameter,
+	http.StatusTooManyRequests:     ErrThrottled,
+}
+
+// Kind returns the kind of the error (ErrNotFound, ErrConflict, ...), or nil
+// when it is none of them. ErrNotFound is only ever a 404; otherwise the error
+// code takes precedence over the HTTP status.
+func (e *ErrorResponse) Kind() error {
+	if e.Code == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if e.Code == 0 {
+		return nil
+	}
+	if code := normalizeErrorCode(e.APIError.ErrorModel.Code); code != "" {
+		for _, k := range errorCodeKinds {
+			if strings.Contains(code, k.fragment) {
+				return k.kind
+			}
+		}
+	}
+	return statusKinds[e.Code]
+}
+
+// Is reports whether target is the kind of the error.
+func (e *ErrorResponse) Is(target error) bool {
+	kind := e.Kind()
+	return kind != nil && kind == target
+}
+
+// Unwrap returns the underlying transport or decoding error, if any.
+func (e *ErrorResponse) Unwrap() error {
+	return e.Err
+}
+
+func normalizeErrorCode(code string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(code))
+}
+
+func requestID(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
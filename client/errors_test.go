@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestErrorResponseKind(t *testing.T) {
+	cases := []struct {
+		status int
+		code   string
+		want   error
+	}{
+		{http.StatusNotFound, "", ErrNotFound},
+		{http.StatusNotFound, "InvalidParameter", ErrNotFound},
+		{http.StatusBadRequest, "ENVIRONMENT_NOT_FOUND", ErrInvalidParameter},
+		{http.StatusUnauthorized, "ACCESS_KEY_NOT_FOUND", nil},
+		{http.StatusForbidden, "Instance.NotExist", nil},
+		{http.StatusBadRequest, "Topic.AlreadyExists", ErrConflict},
+		{http.StatusConflict, "", ErrConflict},
+		{http.StatusConflict, "InstanceStateConflict", ErrStateConflict},
+		{http.StatusBadRequest, "Instance.InvalidStatus", ErrStateConflict},
+		{http.StatusTooManyRequests, "", ErrThrottled},
+		{http.StatusBadRequest, "Throttling.User", ErrThrottled},
+		{http.StatusBadRequest, "InvalidParameter.Partition", ErrInvalidParameter},
+		{http.StatusBadRequest, "", ErrInvalidParameter},
+		{http.StatusInternalServerError, "InternalError", nil},
+		{0, "", nil},
+	}
+	kinds := []error{ErrNotFound, ErrConflict, ErrThrottled, ErrInvalidParameter, ErrStateConflict}
+	for _, tc := range cases {
+		e := &ErrorResponse{Code: tc.status, APIError: APIError{ErrorModel: ErrorModel{Code: tc.code}}}
+		if got := e.Kind(); got != tc.want {
+			t.Errorf("Kind(%d, %q) = %v, want %v", tc.status, tc.code, got, tc.want)
+		}
+		for _, kind := range kinds {
+			if errors.Is(e, kind) != (kind == tc.want) {
+				t.Errorf("errors.Is(%d %q, %v) = %v", tc.status, tc.code, kind, !(kind == tc.want))
+			}
+		}
+	}
+}
+
+func TestErrorResponseCarriesRequestID(t *testing.T) {
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-42")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":{"code":"TOPIC_ALREADY_EXISTS","message":"topic exists"}}`))
+	})
+
+	_, err := c.Post(ctx, "/api/v1/instances/kf-1/topics", map[string]string{"name": "orders"})
+	wrapped := fmt.Errorf("create topic: %w", err)
+	if !errors.Is(wrapped, ErrConflict) {
+		t.Fatalf("errors.Is(%v, ErrConflict) = false", err)
+	}
+	var apiErr *ErrorResponse
+	if !errors.As(wrapped, &apiErr) || apiErr.RequestID != "req-42" {
+		t.Fatalf("errors.As() = %+v, want RequestID req-42", apiErr)
+	}
+	if !strings.Contains(err.Error(), "Request ID: req-42") {
+		t.Fatalf("Error() = %q, want the request ID", err.Error())
+	}
+}
+
+func TestErrorResponseUnwrapsTransportError(t *testing.T) {
+	err := &ErrorResponse{ErrorMessage: "Error sending request", Err: context.DeadlineExceeded}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("errors.Is(err, context.DeadlineExceeded) = false")
+	}
+	if err.Kind() != nil {
+		t.Fatalf("Kind() = %v, want nil for transport errors", err.Kind())
+	}
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// LogSubsystem is the tflog subsystem HTTP wire logs are written to. Its level
+// follows TF_LOG_PROVIDER and can be overridden with
+// TF_LOG_PROVIDER_AUTOMQ_HTTP.
+const LogSubsystem = "automq_http"
+
+const (
+	redactedValue = "***"
+	// maxLoggedBodySize caps the size of a logged request or response body.
+	maxLoggedBodySize = 16 * 1024
+)
+
+// redactedFields are the JSON fields whose values never appear in the logs,
+// compared case-insensitively at any depth of a request or response body.
+var redactedFields = toLowerSet(
+	"password",
+	"keyPassword",
+	"privateKey",
+	"keystoreKey",
+	"token",
+	"sessionToken",
+	"secretAccessKey",
+	"credential",
+	"keytabFile",
+	"connectorConfigSensitive",
+)
+
+// redactedHeaders are the HTTP headers whose values never appear in the logs.
+var redactedHeaders = toLowerSet(
+	"Authorization",
+	"X-Automq-Security-Token",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+)
+
+// loggingTransport logs every request and response going through it to the
+// LogSubsystem, with secrets redacted.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+// NewLoggingTransport wraps next, or http.DefaultTransport when next is nil,
+// with a RoundTripper that logs method, path, status, latency, headers and
+// bodies at DEBUG level. Values of known secret fields and headers are
+// replaced with "***".
+func NewLoggingTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &loggingTransport{next: next}
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Reading the bodies to log them is only worth it when the entries are
+	// written, otherwise they are passed through untouched.
+	if !debugEnabled() {
+		return t.next.RoundTrip(req)
+	}
+	ctx := tflog.NewSubsystem(req.Context(), LogSubsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_AUTOMQ", "HTTP"))
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "http_method", req.Method)
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "http_path", req.URL.Path)
+
+	fields := map[string]interface{}{
+		"http_request_headers": redactHeaders(req.Header),
+	}
+	if req.URL.RawQuery != "" {
+		fields["http_query"] = req.URL.RawQuery
+	}
+	if req.GetBody != nil && req.ContentLength != 0 {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			_ = body.Close()
+			fields["http_request_body"] = redactBody(data)
+		}
+	}
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Sending AutoMQ API request", fields)
+
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	latency := time.Since(start)
+	if err != nil {
+		tflog.SubsystemDebug(ctx, LogSubsystem, "AutoMQ API request failed", map[string]interface{}{
+			"http_duration_ms": latency.Milliseconds(),
+			"error":            err.Error(),
+		})
+		return nil, err
+	}
+
+	data, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(data))
+
+	fields = map[string]interface{}{
+		"http_status":           res.StatusCode,
+		"http_duration_ms":      latency.Milliseconds(),
+		"http_response_headers": redactHeaders(res.Header),
+	}
+	if len(data) > 0 {
+		fields["http_response_body"] = redactBody(data)
+	}
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Received AutoMQ API response", fields)
+	return res, nil
+}
+
+// logLevelEnv are the environment variables the level of the LogSubsystem
+// comes from, most specific first: tflog falls back to the level of the
+// provider logger, which Terraform sets from TF_LOG_PROVIDER or TF_LOG.
+var logLevelEnv = []string{"TF_LOG_PROVIDER_AUTOMQ_HTTP", "TF_LOG_PROVIDER_AUTOMQ", "TF_LOG_PROVIDER", "TF_LOG"}
+
+// debugEnabled reports whether the LogSubsystem writes DEBUG entries.
+func debugEnabled() bool {
+	for _, name := range logLevelEnv {
+		switch strings.ToUpper(strings.TrimSpace(os.Getenv(name))) {
+		case "TRACE", "DEBUG", "JSON":
+			return true
+		case "INFO", "WARN", "ERROR", "OFF":
+			return false
+		}
+	}
+	return false
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for name, values := range header {
+		if _, ok := redactedHeaders[strings.ToLower(name)]; ok {
+			redacted[name] = redactedValue
+			continue
+		}
+		redacted[name] = strings.Join(values, ", ")
+	}
+	return redacted
+}
+
+// redactBody returns a JSON body with the values of redactedFields replaced.
+// Bodies that are not JSON are not logged, since secrets in them cannot be
+// located reliably.
+func redactBody(data []byte) string {
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "[non-JSON body omitted]"
+	}
+	redacted, err := json.Marshal(redactValue(body))
+	if err != nil {
+		return "[body omitted]"
+	}
+	if len(redacted) > maxLoggedBodySize {
+		return string(redacted[:maxLoggedBodySize]) + "...[truncated]"
+	}
+	return string(redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if _, ok := redactedFields[strings.ToLower(key)]; ok && item != nil {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func toLowerSet(values ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[strings.ToLower(value)] = struct{}{}
+	}
+	return set
+}
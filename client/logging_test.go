@@ -0,0 +1,136 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLoggingTransportRedactsSecrets(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_AUTOMQ_HTTP", "DEBUG")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=cookie-secret")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"userId":"u-1","Token":"response-secret","features":[{"privateKey":"nested-secret","name":"tls"}]}`))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	body := `{"name":"alice","password":"request-secret","connectorConfigSensitive":{"db.password":"map-secret"}}`
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/api/v1/users?dryRun=true", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "AUTOMQ-HMAC-SHA256 Signature=header-secret")
+
+	res, err := (&http.Client{Transport: NewLoggingTransport(nil)}).Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	defer res.Body.Close()
+	respBody, _ := io.ReadAll(res.Body)
+	if !strings.Contains(string(respBody), "response-secret") {
+		t.Fatalf("response body was not passed through unchanged: %s", respBody)
+	}
+
+	logged := output.String()
+	for _, secret := range []string{"request-secret", "map-secret", "header-secret", "response-secret", "nested-secret", "cookie-secret"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("log contains %q: %s", secret, logged)
+		}
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("decode log: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d log entries, want request and response: %v", len(entries), entries)
+	}
+	response := entries[1]
+	if response["http_method"] != "POST" || response["http_path"] != "/api/v1/users" || response["http_status"] != float64(201) {
+		t.Errorf("response entry = %v", response)
+	}
+	if _, ok := response["http_duration_ms"]; !ok {
+		t.Errorf("response entry has no latency: %v", response)
+	}
+	if requestBody, _ := entries[0]["http_request_body"].(string); !strings.Contains(requestBody, `"name":"alice"`) {
+		t.Errorf("request body = %v, want non-secret fields kept", entries[0]["http_request_body"])
+	}
+}
+
+type stubRoundTripper func(*http.Request) (*http.Response, error)
+
+func (f stubRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestLoggingTransportSkipsBodiesBelowDebug(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_AUTOMQ_HTTP", "INFO")
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	responseBody := io.NopCloser(strings.NewReader(`{"userId":"u-1"}`))
+	next := stubRoundTripper(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: responseBody}, nil
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://automq.example.com/api/v1/users", strings.NewReader(`{"name":"alice"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	getBody := req.GetBody
+	bodyReads := 0
+	req.GetBody = func() (io.ReadCloser, error) {
+		bodyReads++
+		return getBody()
+	}
+
+	res, err := NewLoggingTransport(next).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if res.Body != responseBody {
+		t.Errorf("response body was buffered below DEBUG")
+	}
+	if bodyReads != 0 {
+		t.Errorf("request body was read %d times below DEBUG", bodyReads)
+	}
+	if output.Len() != 0 {
+		t.Errorf("logged below DEBUG: %s", output.String())
+	}
+}
+
+func TestDebugEnabled(t *testing.T) {
+	for _, tt := range []struct {
+		env  map[string]string
+		want bool
+	}{
+		{env: map[string]string{}, want: false},
+		{env: map[string]string{"TF_LOG": "DEBUG"}, want: true},
+		{env: map[string]string{"TF_LOG": "json"}, want: true},
+		{env: map[string]string{"TF_LOG": "TRACE", "TF_LOG_PROVIDER": "INFO"}, want: false},
+		{env: map[string]string{"TF_LOG_PROVIDER_AUTOMQ": "WARN", "TF_LOG_PROVIDER_AUTOMQ_HTTP": "debug"}, want: true},
+		{env: map[string]string{"TF_LOG": "DEBUG", "TF_LOG_PROVIDER_AUTOMQ_HTTP": "OFF"}, want: false},
+		{env: map[string]string{"TF_LOG": "DEBUG", "TF_LOG_PROVIDER_AUTOMQ_HTTP": "verbose"}, want: true},
+	} {
+		for _, name := range logLevelEnv {
+			t.Setenv(name, tt.env[name])
+		}
+		if got := debugEnabled(); got != tt.want {
+			t.Errorf("debugEnabled() with %v = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestRedactBodyOmitsNonJSON(t *testing.T) {
+	if got := redactBody([]byte("password=secret")); strings.Contains(got, "secret") {
+		t.Fatalf("redactBody() = %q", got)
+	}
+}
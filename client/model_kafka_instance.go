@@ -118,6 +118,7 @@ type InstanceFailoverParam struct {
 type InstanceMetricsExporterParam struct {
 	Prometheus *InstancePrometheusExporterParam `json:"prometheus,omitempty"`
 	CloudWatch *InstanceCloudWatchExporterParam `json:"cloudWatch,omitempty"`
+	Oltp       *InstanceOLTPExporterParam       `json:"oltp,omitempty"`
 }
 
 type InstancePrometheusExporterParam struct {
@@ -136,6 +137,13 @@ type InstanceCloudWatchExporterParam struct {
 	Namespace *string `json:"namespace,omitempty"`
 }
 
+// InstanceOLTPExporterParam mirrors InstanceOLTPExporterVO, including the
+// oltp spelling the API uses for the OTLP exporter.
+type InstanceOLTPExporterParam struct {
+	Enabled  *bool   `json:"enabled,omitempty"`
+	EndPoint *string `json:"endPoint,omitempty"`
+}
+
 type MetricsLabelParam struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
@@ -323,6 +331,7 @@ type MetricsLabelVO struct {
 }
 
 type InstanceCloudWatchExporterVO struct {
+	Enabled   *bool   `json:"enabled,omitempty"`
 	Namespace *string `json:"namespace,omitempty"`
 }
 
@@ -350,6 +359,7 @@ type InstanceInboundRuleVO struct {
 }
 
 type InstanceOLTPExporterVO struct {
+	Enabled  *bool   `json:"enabled,omitempty"`
 	EndPoint *string `json:"endPoint,omitempty"`
 }
 
@@ -385,6 +395,7 @@ type SpecificationUpdateParam struct {
 	ReservedNodeCount *int32                 `json:"reservedNodeCount,omitempty"`
 	NodeConfig        *NodeConfigParam       `json:"nodeConfig,omitempty"`
 	FileSystem        *FileSystemUpdateParam `json:"fileSystemForFsWal,omitempty"`
+	ScheduleSpec      *string                `json:"scheduleSpec,omitempty"`
 }
 
 type KafkaInstanceRequestPaymentPlan struct {
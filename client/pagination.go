@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+const (
+	pageQueryKey     = "page"
+	pageSizeQueryKey = "size"
+	defaultPageSize  = 50
+)
+
+// pageNumResult is the envelope the control plane wraps every collection
+// response in. The typed PageNumResult*VO structs share the same layout.
+type pageNumResult[T any] struct {
+	PageNum   *int32 `json:"pageNum,omitempty"`
+	PageSize  *int32 `json:"pageSize,omitempty"`
+	Total     *int64 `json:"total,omitempty"`
+	List      []T    `json:"list,omitempty"`
+	TotalPage *int64 `json:"totalPage,omitempty"`
+}
+
+// pageIterator walks a collection endpoint page by page. It starts at the
+// page given in the query (default 1) and stops after the last page reported
+// by totalPage, or at the first short or empty page when the server does not
+// report one.
+type pageIterator[T any] struct {
+	client *Client
+	path   string
+	query  map[string]string
+
+	page    int
+	size    int
+	fetched int64
+	done    bool
+}
+
+func newPageIterator[T any](c *Client, path string, query map[string]string) *pageIterator[T] {
+	it := &pageIterator[T]{
+		client: c,
+		path:   path,
+		query:  make(map[string]string, len(query)+2),
+		page:   1,
+		size:   defaultPageSize,
+	}
+	for k, v := range query {
+		it.query[k] = v
+	}
+	if page, err := strconv.Atoi(it.query[pageQueryKey]); err == nil && page > 0 {
+		it.page = page
+	}
+	if size, err := strconv.Atoi(it.query[pageSizeQueryKey]); err == nil && size > 0 {
+		it.size = size
+	}
+	return it
+}
+
+// HasNext reports whether another page may be fetched.
+func (it *pageIterator[T]) HasNext() bool {
+	return !it.done
+}
+
+// NextPage fetches the next page of items.
+func (it *pageIterator[T]) NextPage(ctx context.Context) ([]T, error) {
+	if it.done {
+		return nil, nil
+	}
+	it.query[pageQueryKey] = strconv.Itoa(it.page)
+	it.query[pageSizeQueryKey] = strconv.Itoa(it.size)
+
+	body, err := it.client.Get(ctx, it.path, it.query)
+	if err != nil {
+		return nil, err
+	}
+	result := pageNumResult[T]{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	it.fetched += int64(len(result.List))
+	switch {
+	case len(result.List) == 0:
+		it.done = true
+	case result.TotalPage != nil:
+		it.done = int64(it.page) >= *result.TotalPage
+	case result.Total != nil:
+		it.done = it.fetched >= *result.Total
+	default:
+		it.done = len(result.List) < it.size
+	}
+	it.page++
+	return result.List, nil
+}
+
+// listAll collects every item of a collection endpoint.
+func listAll[T any](ctx context.Context, c *Client, path string, query map[string]string) ([]T, error) {
+	it := newPageIterator[T](c, path, query)
+	var items []T
+	for it.HasNext() {
+		page, err := it.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+	}
+	return items, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestListWalksAllPagesUsingTotalPage(t *testing.T) {
+	var pages []string
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		if got := r.URL.Query().Get("keyword"); got != "orders" {
+			t.Errorf("keyword = %q, want orders", got)
+		}
+		_, _ = fmt.Fprintf(w, `{"pageNum":%s,"pageSize":2,"totalPage":3,"list":[{"topicId":"t-%s-a"},{"topicId":"t-%s-b"}]}`, page, page, page)
+	})
+
+	topics, err := c.ListKafkaTopics(ctx, "kf-1", map[string]string{"keyword": "orders", "size": "2"})
+	if err != nil {
+		t.Fatalf("ListKafkaTopics() error = %v", err)
+	}
+	if len(topics) != 6 {
+		t.Fatalf("len(topics) = %d, want 6", len(topics))
+	}
+	if fmt.Sprint(pages) != "[1 2 3]" {
+		t.Fatalf("requested pages = %v, want [1 2 3]", pages)
+	}
+}
+
+func TestListStopsAtShortPageWithoutTotals(t *testing.T) {
+	var calls int
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if size := r.URL.Query().Get("size"); size != strconv.Itoa(defaultPageSize) {
+			t.Errorf("size = %q, want %d", size, defaultPageSize)
+		}
+		if calls == 1 {
+			list := ""
+			for i := 0; i < defaultPageSize; i++ {
+				if i > 0 {
+					list += ","
+				}
+				list += fmt.Sprintf(`{"name":"u%d"}`, i)
+			}
+			_, _ = fmt.Fprintf(w, `{"list":[%s]}`, list)
+			return
+		}
+		_, _ = w.Write([]byte(`{"list":[{"name":"last"}]}`))
+	})
+
+	users, err := c.ListKafkaUsers(ctx, "kf-1", nil)
+	if err != nil {
+		t.Fatalf("ListKafkaUsers() error = %v", err)
+	}
+	if len(users) != defaultPageSize+1 || calls != 2 {
+		t.Fatalf("got %d users in %d calls, want %d in 2", len(users), calls, defaultPageSize+1)
+	}
+}
+
+func TestGetKafkaUserMatchesExactNameAcrossPages(t *testing.T) {
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte(`{"totalPage":2,"list":[{"name":"app-admin"}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"totalPage":2,"list":[{"name":"app"}]}`))
+		}
+	})
+
+	user, err := c.GetKafkaUser(ctx, "kf-1", "app")
+	if err != nil {
+		t.Fatalf("GetKafkaUser() error = %v", err)
+	}
+	if user.Name != "app" {
+		t.Fatalf("user.Name = %q, want app", user.Name)
+	}
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultMaxRetries     = 3
+	DefaultRetryDelay     = 500 * time.Millisecond
+	DefaultMaxBackoff     = 30 * time.Second
+	DefaultRequestTimeout = 30 * time.Second
+)
+
+// shouldRetry reports whether a failed attempt may be sent again. Idempotent
+// requests are retried on throttling, server errors and transient network
+// errors. Other requests are only retried when the control plane cannot have
+// acted on them: throttled or unavailable responses, and connections that
+// failed before the request was written.
+func shouldRetry(ctx context.Context, idempotent bool, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	e, ok := err.(*ErrorResponse)
+	if !ok {
+		return false
+	}
+	switch {
+	case e.Code == http.StatusTooManyRequests, e.Code == http.StatusServiceUnavailable:
+		return true
+	case e.Code >= 500:
+		return idempotent
+	case e.Code == 0 && e.Err != nil:
+		if idempotent {
+			return isRetryableNetworkError(e.Err)
+		}
+		return isDialError(e.Err)
+	}
+	return false
+}
+
+// isRetryableNetworkError classifies transport failures that are worth
+// retrying: timeouts, resets and refused or dropped connections. TLS and URL
+// errors are permanent and are returned to the caller unchanged.
+func isRetryableNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection reset by peer")
+}
+
+// isDialError reports whether the request failed while opening the
+// connection, which guarantees it never reached the control plane.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// backoff returns the delay before the given retry attempt (starting at 1).
+// The delay grows exponentially from RetryDelay up to MaxBackoff with full
+// jitter. A Retry-After value sent by the server takes precedence.
+func (c *Client) backoff(attempt int, err error) time.Duration {
+	if e, ok := err.(*ErrorResponse); ok && e.retryAfter > 0 {
+		return e.retryAfter
+	}
+	base := c.RetryDelay
+	if base <= 0 {
+		base = DefaultRetryDelay
+	}
+	maxBackoff := c.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	delay := maxBackoff
+	if attempt < 32 {
+		if d := base << uint(attempt-1); d > 0 && d < maxBackoff {
+			delay = d
+		}
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// RetryBackoff returns the delay before the given attempt, counted from 1,
+// of an operation retried outside the client, following the same
+// exponential backoff as the client retries. A nil Client uses the defaults.
+func (c *Client) RetryBackoff(attempt int, err error) time.Duration {
+	if c == nil {
+		c = &Client{}
+	}
+	return c.backoff(attempt, err)
+}
+
+// parseRetryAfter reads a Retry-After header in either delay-seconds or
+// HTTP-date form. It returns zero when the header is absent or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// sleepWithContext waits for the given duration or until ctx is done.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
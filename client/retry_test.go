@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, context.Context) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(context.Background(), server.URL, AuthCredentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, func(c *Client) {
+		c.RetryDelay = time.Millisecond
+		c.MaxBackoff = 5 * time.Millisecond
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c, context.WithValue(context.Background(), EnvIdKey, "env-test")
+}
+
+func TestGetRetriesServerErrors(t *testing.T) {
+	var calls int32
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	if _, err := c.Get(ctx, "/api/v1/instances", nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestPostDoesNotRetryServerErrors(t *testing.T) {
+	var calls int32
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := c.Post(ctx, "/api/v1/instances", struct{}{})
+	if e, ok := err.(*ErrorResponse); !ok || e.Code != http.StatusInternalServerError {
+		t.Fatalf("Post() error = %v, want 500 ErrorResponse", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestPatchDoesNotRetryServerErrors(t *testing.T) {
+	var calls int32
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err := c.Patch(ctx, "/api/v1/instances/kf-1", struct{}{})
+	if e, ok := err.(*ErrorResponse); !ok || e.Code != http.StatusBadGateway {
+		t.Fatalf("Patch() error = %v, want 502 ErrorResponse", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestPostRetriesThrottledRequestsWithBody(t *testing.T) {
+	var calls int32
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != int64(len(`{"name":"t"}`)) {
+			t.Errorf("ContentLength = %d on attempt %d", r.ContentLength, calls+1)
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	if _, err := c.Post(ctx, "/api/v1/instances", map[string]string{"name": "t"}); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+}
+
+func TestRetryStopsWhenContextIsCancelled(t *testing.T) {
+	var calls int32
+	c, ctx := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Get(ctx, "/api/v1/instances", nil)
+	if e, ok := err.(*ErrorResponse); !ok || e.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Get() error = %v, want 503 ErrorResponse", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Get() returned after %s, want prompt return on cancellation", elapsed)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 00:00:10 GMT": 10 * time.Second,
+		"Sun, 31 Dec 2023 23:59:00 GMT": 0,
+	}
+	for value, want := range cases {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestBackoffIsCappedByMaxBackoff(t *testing.T) {
+	c := &Client{RetryDelay: time.Second, MaxBackoff: 2 * time.Second}
+	for attempt := 1; attempt <= 40; attempt++ {
+		if d := c.backoff(attempt, nil); d <= 0 || d > 2*time.Second {
+			t.Fatalf("backoff(%d) = %s, want (0, 2s]", attempt, d)
+		}
+	}
+	if d := c.backoff(1, &ErrorResponse{retryAfter: 7 * time.Second}); d != 7*time.Second {
+		t.Fatalf("backoff with Retry-After = %s, want 7s", d)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 AutoMQ HK Limited.
+// SPDX-License-Identifier: Apache-2.0
+
+package signer
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// signingKeyCache memoizes deriveSigningKey. A signing key only depends on
+// the secret, the day, the region and the service, so one key serves every
+// request of a day. Entries of previous days are dropped when a new day's
+// key is stored.
+type signingKeyCache struct {
+	mu      sync.Mutex
+	entries map[signingKeyScope][]byte
+}
+
+type signingKeyScope struct {
+	secret  [sha256.Size]byte
+	day     string
+	region  string
+	service string
+}
+
+func newSigningKeyCache() *signingKeyCache {
+	return &signingKeyCache{entries: make(map[signingKeyScope][]byte)}
+}
+
+// get returns the signing key for the scope, deriving and caching it on a
+// miss. A nil cache derives the key every time.
+func (c *signingKeyCache) get(region, service, secretKey string, dt time.Time) []byte {
+	if c == nil {
+		return deriveSigningKey(region, service, secretKey, dt)
+	}
+	scope := signingKeyScope{
+		secret:  sha256.Sum256([]byte(secretKey)),
+		day:     formatShortTime(dt),
+		region:  region,
+		service: service,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.entries[scope]; ok {
+		return key
+	}
+	for cached := range c.entries {
+		if cached.day != scope.day {
+			delete(c.entries, cached)
+		}
+	}
+	key := deriveSigningKey(region, service, secretKey, dt)
+	c.entries[scope] = key
+	return key
+}
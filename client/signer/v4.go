@@ -21,12 +21,16 @@ import (
 type Credentials struct {
 	AccessKeyID     string
 	SecretAccessKey string
+	// SessionToken is set for short-lived credentials. It is sent, and
+	// signed, as the X-Automq-Security-Token header.
+	SessionToken string
 }
 
 const (
 	authorizationHeader     = "Authorization"
 	authHeaderSignatureElem = "Signature="
 	signatureQueryKey       = "X-Automq-Signature"
+	securityTokenKey        = "X-Automq-Security-Token"
 
 	authHeaderPrefix = "AUTOMQ-HMAC-SHA256"
 	timeFormat       = "20060102T150405Z"
@@ -111,6 +115,11 @@ type Signer struct {
 	// UnsignedPayload will prevent signing of the payload. This will only
 	// work for services that have support for this.
 	UnsignedPayload bool
+
+	// keyCache holds derived signing keys so they are computed once per
+	// day, region and service. It is nil for a zero Signer, which then
+	// derives the key on every request.
+	keyCache *signingKeyCache
 }
 
 // NewSigner returns a Signer pointer configured with the credentials and optional
@@ -119,6 +128,7 @@ type Signer struct {
 func NewSigner(credentials Credentials, options ...func(*Signer)) *Signer {
 	v4 := &Signer{
 		Credential: credentials,
+		keyCache:   newSigningKeyCache(),
 	}
 
 	for _, option := range options {
@@ -139,6 +149,7 @@ type signingCtx struct {
 	SignedHeaderVals http.Header
 
 	credValues      Credentials
+	keyCache        *signingKeyCache
 	isPresign       bool
 	unsignedPayload bool
 
@@ -236,6 +247,7 @@ func (v4 Signer) signWithBody(r *http.Request, body io.ReadSeeker, service, regi
 	}
 
 	ctx.credValues = v4.Credential
+	ctx.keyCache = v4.keyCache
 
 	if err := ctx.build(v4.DisableHeaderHoisting); err != nil {
 		return nil, err
@@ -261,6 +273,7 @@ func (v4 Signer) signWithBody(r *http.Request, body io.ReadSeeker, service, regi
 func (ctx *signingCtx) build(disableHeaderHoisting bool) error {
 	ctx.buildTime()             // no depends
 	ctx.buildCredentialString() // no depends
+	ctx.buildSecurityToken()    // no depends
 
 	if err := ctx.buildBodyDigest(); err != nil {
 		return err
@@ -338,6 +351,18 @@ func (ctx *signingCtx) buildCredentialString() {
 	}
 }
 
+func (ctx *signingCtx) buildSecurityToken() {
+	if ctx.credValues.SessionToken == "" {
+		ctx.Request.Header.Del(securityTokenKey)
+		return
+	}
+	if ctx.isPresign {
+		ctx.Query.Set(securityTokenKey, ctx.credValues.SessionToken)
+	} else {
+		ctx.Request.Header.Set(securityTokenKey, ctx.credValues.SessionToken)
+	}
+}
+
 func buildQuery(r rule, header http.Header) (url.Values, http.Header) {
 	query := url.Values{}
 	unsignedHeaders := http.Header{}
@@ -441,7 +466,7 @@ func (ctx *signingCtx) buildStringToSign() {
 }
 
 func (ctx *signingCtx) buildSignature() {
-	creds := deriveSigningKey(ctx.Region, ctx.ServiceName, ctx.credValues.SecretAccessKey, ctx.Time)
+	creds := ctx.keyCache.get(ctx.Region, ctx.ServiceName, ctx.credValues.SecretAccessKey, ctx.Time)
 	signature := hmacSHA256(creds, []byte(ctx.stringToSign))
 	ctx.signature = hex.EncodeToString(signature)
 }
@@ -487,7 +512,7 @@ func (ctx *signingCtx) isRequestSigned() bool {
 func (ctx *signingCtx) removePresign() {
 	ctx.Query.Del("X-Automq-Algorithm")
 	ctx.Query.Del("X-Automq-Signature")
-	ctx.Query.Del("X-Automq-Security-Token")
+	ctx.Query.Del(securityTokenKey)
 	ctx.Query.Del("X-Automq-Date")
 	ctx.Query.Del("X-Automq-Expires")
 	ctx.Query.Del("X-Automq-Credential")
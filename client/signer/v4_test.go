@@ -537,3 +537,86 @@ func (r *readerSeekerWrapper) Seek(offset int64, whence int) (int64, error) {
 func (r *readerSeekerWrapper) Len() int {
 	return r.r.Len()
 }
+
+func TestSignRequestWithSessionToken(t *testing.T) {
+	req, body := buildRequest("dynamodb", "private", "{}")
+	signer := buildSigner()
+	signer.Credential.SessionToken = "TOKEN"
+	_, err := signer.Sign(req, body, "dynamodb", "private", epochTime())
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := "TOKEN", req.Header.Get("X-Automq-Security-Token"); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if a := req.Header.Get("Authorization"); !strings.Contains(a, ";x-automq-security-token;") {
+		t.Errorf("expect security token to be a signed header, got %v", a)
+	}
+
+	unsignedReq, unsignedBody := buildRequest("dynamodb", "private", "{}")
+	unsigned := buildSigner()
+	if _, err := unsigned.Sign(unsignedReq, unsignedBody, "dynamodb", "private", epochTime()); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if a := unsignedReq.Header.Get("X-Automq-Security-Token"); len(a) != 0 {
+		t.Errorf("expect %v to be empty", a)
+	}
+	if req.Header.Get("Authorization") == unsignedReq.Header.Get("Authorization") {
+		t.Error("expect the session token to change the signature")
+	}
+}
+
+func TestPresignRequestWithSessionToken(t *testing.T) {
+	req, body := buildRequest("dynamodb", "private", "{}")
+	signer := buildSigner()
+	signer.Credential.SessionToken = "TOKEN"
+	_, err := signer.Presign(req, body, "dynamodb", "private", 300*time.Second, epochTime())
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := "TOKEN", req.URL.Query().Get("X-Automq-Security-Token"); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
+
+func TestSigningKeyCache(t *testing.T) {
+	cache := newSigningKeyCache()
+	day1 := epochTime()
+	day2 := day1.Add(24 * time.Hour)
+
+	first := cache.get("private", "cmp", "SECRET", day1)
+	if e, a := deriveSigningKey("private", "cmp", "SECRET", day1), first; !bytes.Equal(e, a) {
+		t.Fatalf("expect %x, got %x", e, a)
+	}
+	if a := cache.get("private", "cmp", "SECRET", day1.Add(time.Hour)); &a[0] != &first[0] {
+		t.Error("expect the cached key to be reused within the same day")
+	}
+	if a := cache.get("private", "cmp", "ROTATED", day1); bytes.Equal(a, first) {
+		t.Error("expect a rotated secret to derive a new key")
+	}
+
+	cache.get("private", "cmp", "SECRET", day2)
+	if e, a := 1, len(cache.entries); e != a {
+		t.Errorf("expect %v entries after the day changed, got %v", e, a)
+	}
+}
+
+func TestSignerWithAndWithoutKeyCacheAgree(t *testing.T) {
+	cachedReq, cachedBody := buildRequest("dynamodb", "private", "{}")
+	cached := NewSigner(Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"})
+	if _, err := cached.Sign(cachedReq, cachedBody, "dynamodb", "private", epochTime()); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	req, body := buildRequest("dynamodb", "private", "{}")
+	signer := buildSigner()
+	if _, err := signer.Sign(req, body, "dynamodb", "private", epochTime()); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := req.Header.Get("Authorization"), cachedReq.Header.Get("Authorization"); e != a {
+		t.Errorf("expect\n%v\nactual\n%v\n", e, a)
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// requestThrottle limits the request rate and the number of in-flight
+// requests sent to the control plane. Limits apply per environment, as each
+// environment is served by its own control plane.
+type requestThrottle struct {
+	requestsPerSecond  float64
+	concurrentRequests int
+
+	mu           sync.Mutex
+	environments map[string]*environmentThrottle
+}
+
+type environmentThrottle struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+// newRequestThrottle returns nil when neither limit is set, which disables
+// throttling entirely.
+func newRequestThrottle(requestsPerSecond float64, concurrentRequests int) *requestThrottle {
+	if requestsPerSecond <= 0 && concurrentRequests <= 0 {
+		return nil
+	}
+	return &requestThrottle{
+		requestsPerSecond:  requestsPerSecond,
+		concurrentRequests: concurrentRequests,
+		environments:       make(map[string]*environmentThrottle),
+	}
+}
+
+func (t *requestThrottle) forEnvironment(environmentID string) *environmentThrottle {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if et, ok := t.environments[environmentID]; ok {
+		return et
+	}
+	et := &environmentThrottle{}
+	if t.requestsPerSecond > 0 {
+		burst := int(math.Ceil(t.requestsPerSecond))
+		et.limiter = rate.NewLimiter(rate.Limit(t.requestsPerSecond), burst)
+	}
+	if t.concurrentRequests > 0 {
+		et.slots = make(chan struct{}, t.concurrentRequests)
+	}
+	t.environments[environmentID] = et
+	return et
+}
+
+// acquire blocks until the environment has a free request slot and a rate
+// token, or ctx is done. The returned function releases the slot.
+func (t *requestThrottle) acquire(ctx context.Context, environmentID string) (func(), error) {
+	if t == nil {
+		return func() {}, nil
+	}
+	et := t.forEnvironment(environmentID)
+
+	release := func() {}
+	if et.slots != nil {
+		select {
+		case et.slots <- struct{}{}:
+			release = func() { <-et.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if et.limiter != nil {
+		if err := et.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrentRequestsAreCappedPerEnvironment(t *testing.T) {
+	var inFlight, peak int32
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	c.throttle = newRequestThrottle(0, 2)
+
+	ctx := context.WithValue(context.Background(), EnvIdKey, "env-a")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Get(ctx, "/api/v1/instances", nil); err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Fatalf("peak in-flight requests = %d, want <= 2", peak)
+	}
+}
+
+func TestThrottleIsKeyedByEnvironment(t *testing.T) {
+	throttle := newRequestThrottle(0, 1)
+
+	releaseA, err := throttle.acquire(context.Background(), "env-a")
+	if err != nil {
+		t.Fatalf("acquire(env-a) error = %v", err)
+	}
+	defer releaseA()
+
+	releaseB, err := throttle.acquire(context.Background(), "env-b")
+	if err != nil {
+		t.Fatalf("acquire(env-b) error = %v, want independent slot", err)
+	}
+	releaseB()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := throttle.acquire(ctx, "env-a"); err == nil {
+		t.Fatal("acquire(env-a) succeeded while the only slot was held")
+	}
+}
+
+func TestRateLimitSpacesRequests(t *testing.T) {
+	throttle := newRequestThrottle(20, 0)
+
+	start := time.Now()
+	for i := 0; i < 25; i++ {
+		release, err := throttle.acquire(context.Background(), "env-a")
+		if err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+		release()
+	}
+	// A burst of 20 passes immediately, the remaining 5 need ~250ms.
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("25 requests at 20 rps took %s, want >= 200ms", elapsed)
+	}
+}
+
+func TestNoThrottleWithoutLimits(t *testing.T) {
+	if throttle := newRequestThrottle(0, 0); throttle != nil {
+		t.Fatalf("newRequestThrottle(0, 0) = %v, want nil", throttle)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the spans created for control
+// plane requests. Spans are recorded by the global OpenTelemetry tracer
+// provider and are no-ops unless the provider enables tracing.
+const TracerName = "terraform-provider-automq/client"
+
+const (
+	semconvHTTPMethod     = attribute.Key("http.request.method")
+	semconvHTTPStatusCode = attribute.Key("http.response.status_code")
+	semconvServerAddress  = attribute.Key("server.address")
+	semconvURLPath        = attribute.Key("url.path")
+	attrEnvironmentID     = attribute.Key("automq.environment_id")
+	attrRequestID         = attribute.Key("automq.request_id")
+	attrErrorCode         = attribute.Key("automq.error_code")
+)
+
+// startRequestSpan starts the client span of a single HTTP attempt. Retries
+// and clock skew re-signing each get their own span.
+func startRequestSpan(ctx context.Context, method, host, path, environmentID string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconvHTTPMethod.String(method),
+		semconvURLPath.String(path),
+		attrEnvironmentID.String(environmentID),
+	}
+	if u, err := url.Parse(host); err == nil && u.Hostname() != "" {
+		attrs = append(attrs, semconvServerAddress.String(u.Hostname()))
+	}
+	return otel.Tracer(TracerName).Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func recordResponse(span trace.Span, res *http.Response) {
+	span.SetAttributes(semconvHTTPStatusCode.Int(res.StatusCode))
+	if id := requestID(res.Header); id != "" {
+		span.SetAttributes(attrRequestID.String(id))
+	}
+}
+
+func endRequestSpan(span trace.Span, err error) {
+	defer span.End()
+	if err == nil {
+		return
+	}
+	var apiErr *ErrorResponse
+	if errors.As(err, &apiErr) {
+		if code := apiErr.APIError.ErrorModel.Code; code != "" {
+			span.SetAttributes(attrErrorCode.String(code))
+		}
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
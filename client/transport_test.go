@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTLSTestServer(t *testing.T, clientAuth tls.ClientAuthType) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: clientAuth}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func serverCAPEM(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+func getWithTransport(t *testing.T, url string, cfg TransportConfig) error {
+	t.Helper()
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+	c, _ := NewClient(context.Background(), url, AuthCredentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, func(c *Client) {
+		c.HTTPClient.Transport = transport
+		c.MaxRetries = 0
+	})
+	_, err = c.Get(context.WithValue(context.Background(), EnvIdKey, "env-test"), "/api/v1/instances", nil)
+	return err
+}
+
+func TestTransportTrustsCABundle(t *testing.T) {
+	server := newTLSTestServer(t, tls.NoClientCert)
+
+	if err := getWithTransport(t, server.URL, TransportConfig{}); err == nil {
+		t.Fatal("expected an unknown authority error without the CA bundle")
+	}
+	if err := getWithTransport(t, server.URL, TransportConfig{CABundle: serverCAPEM(server)}); err != nil {
+		t.Fatalf("Get() with ca_bundle error = %v", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, serverCAPEM(server), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := getWithTransport(t, server.URL, TransportConfig{CABundleFile: caFile}); err != nil {
+		t.Fatalf("Get() with ca_bundle_file error = %v", err)
+	}
+	if err := getWithTransport(t, server.URL, TransportConfig{InsecureSkipVerify: true}); err != nil {
+		t.Fatalf("Get() with insecure_skip_verify error = %v", err)
+	}
+}
+
+func TestTransportPresentsClientCertificate(t *testing.T) {
+	server := newTLSTestServer(t, tls.RequireAnyClientCert)
+	certPEM, keyPEM := selfSignedCertificate(t)
+
+	if err := getWithTransport(t, server.URL, TransportConfig{CABundle: serverCAPEM(server)}); err == nil {
+		t.Fatal("expected the handshake to fail without a client certificate")
+	}
+	cfg := TransportConfig{CABundle: serverCAPEM(server), ClientCertificate: certPEM, ClientKey: keyPEM}
+	if err := getWithTransport(t, server.URL, cfg); err != nil {
+		t.Fatalf("Get() with client certificate error = %v", err)
+	}
+}
+
+func TestTransportUsesProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(proxy.Close)
+
+	if err := getWithTransport(t, "http://control-plane.invalid", TransportConfig{ProxyURL: proxy.URL}); err != nil {
+		t.Fatalf("Get() through proxy error = %v", err)
+	}
+	if proxied != "http://control-plane.invalid/api/v1/instances" {
+		t.Fatalf("proxied URL = %q", proxied)
+	}
+}
+
+func TestNewTransportRejectsInvalidConfig(t *testing.T) {
+	certPEM, _ := selfSignedCertificate(t)
+	for name, cfg := range map[string]TransportConfig{
+		"proxy":       {ProxyURL: "proxy:3128"},
+		"ca bundle":   {CABundle: []byte("not a certificate")},
+		"ca file":     {CABundleFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"missing key": {ClientCertificate: certPEM},
+	} {
+		if _, err := NewTransport(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func selfSignedCertificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"terraform-provider-automq/client"
+)
+
+// errUsage is returned when a command is called with the wrong arguments.
+// The usage has already been printed.
+var errUsage = errors.New("usage error")
+
+type runFunc func(ctx context.Context, a *app, args []string) error
+
+type command struct {
+	resource string
+	action   string
+	args     []string
+	summary  string
+	// setup registers the flags of the command, if any, and returns the
+	// function running it once they are parsed.
+	setup func(fs *flag.FlagSet) runFunc
+}
+
+func (c command) argsUsage() string {
+	var parts []string
+	for _, arg := range c.args {
+		parts = append(parts, "<"+arg+">")
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c command) execute(ctx context.Context, a *app, args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet(c.resource+" "+c.action, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: automqctl %s %s %s\n\n%s.\n", c.resource, c.action, c.argsUsage(), c.summary)
+		fs.PrintDefaults()
+	}
+	run := c.setup(fs)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != len(c.args) {
+		fmt.Fprintf(stderr, "automqctl: %s %s takes %d argument(s), got %d\n\n", c.resource, c.action, len(c.args), len(positional))
+		fs.Usage()
+		return errUsage
+	}
+	return run(ctx, a, positional)
+}
+
+// parseInterspersed parses flags given before, between or after the
+// positional arguments, which the flag package alone stops at.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func findCommand(args []string) (command, []string, error) {
+	if len(args) < 2 {
+		return command{}, nil, errors.New("missing command")
+	}
+	for _, cmd := range commands {
+		if cmd.resource == args[0] && cmd.action == args[1] {
+			return cmd, args[2:], nil
+		}
+	}
+	return command{}, nil, fmt.Errorf("unknown command %q", args[0]+" "+args[1])
+}
+
+// noFlags is the setup of commands without flags.
+func noFlags(run runFunc) func(*flag.FlagSet) runFunc {
+	return func(*flag.FlagSet) runFunc { return run }
+}
+
+// stringMapFlag collects repeated key=value flags.
+type stringMapFlag map[string]string
+
+func (f stringMapFlag) String() string {
+	return ""
+}
+
+func (f stringMapFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	f[key] = val
+	return nil
+}
+
+var commands = []command{
+	// Instances
+	{resource: "instances", action: "list", summary: "List Kafka instances", setup: noFlags(listInstances)},
+	{resource: "instances", action: "get", args: []string{"instance-id"}, summary: "Show a Kafka instance", setup: noFlags(getInstance)},
+	{resource: "instances", action: "endpoints", args: []string{"instance-id"}, summary: "List the bootstrap endpoints of an instance", setup: noFlags(instanceEndpoints)},
+	{resource: "instances", action: "configs", args: []string{"instance-id"}, summary: "List the Kafka configuration of an instance", setup: noFlags(instanceConfigs)},
+
+	// Topics
+	{resource: "topics", action: "list", args: []string{"instance-id"}, summary: "List the topics of an instance", setup: noFlags(listTopics)},
+	{resource: "topics", action: "get", args: []string{"instance-id", "topic-id"}, summary: "Show a topic", setup: noFlags(getTopic)},
+	{resource: "topics", action: "create", args: []string{"instance-id", "name"}, summary: "Create a topic", setup: createTopic},
+	{resource: "topics", action: "delete", args: []string{"instance-id", "topic-id"}, summary: "Delete a topic", setup: noFlags(deleteTopic)},
+
+	// Users
+	{resource: "users", action: "list", args: []string{"instance-id"}, summary: "List the SASL users of an instance", setup: noFlags(listUsers)},
+	{resource: "users", action: "create", args: []string{"instance-id", "name"}, summary: "Create a SASL user", setup: createUser},
+	{resource: "users", action: "delete", args: []string{"instance-id", "name"}, summary: "Delete a SASL user", setup: noFlags(deleteUser)},
+
+	// ACLs
+	{resource: "acls", action: "list", args: []string{"instance-id"}, summary: "List the ACL bindings of an instance", setup: listAcls},
+	{resource: "acls", action: "create", args: []string{"instance-id"}, summary: "Create an ACL binding", setup: aclBinding(createAcl)},
+	{resource: "acls", action: "delete", args: []string{"instance-id"}, summary: "Delete an ACL binding", setup: aclBinding(deleteAcl)},
+
+	// Kafka links
+	{resource: "links", action: "list", args: []string{"instance-id"}, summary: "List the Kafka links of an instance", setup: noFlags(listLinks)},
+	{resource: "links", action: "get", args: []string{"instance-id", "link-id"}, summary: "Show a Kafka link", setup: noFlags(getLink)},
+	{resource: "links", action: "delete", args: []string{"instance-id", "link-id"}, summary: "Delete a Kafka link", setup: noFlags(deleteLink)},
+
+	// Mirror topics
+	{resource: "mirror-topics", action: "list", args: []string{"instance-id", "link-id"}, summary: "List the mirror topics of a Kafka link", setup: noFlags(listMirrorTopics)},
+	{resource: "mirror-topics", action: "pause", args: []string{"instance-id", "link-id", "mirror-topic-id"}, summary: "Pause mirroring a topic", setup: noFlags(setMirrorTopicState("PAUSED", "paused"))},
+	{resource: "mirror-topics", action: "resume", args: []string{"instance-id", "link-id", "mirror-topic-id"}, summary: "Resume mirroring a paused topic", setup: noFlags(setMirrorTopicState("LINKING", "resumed"))},
+	{resource: "mirror-topics", action: "promote", args: []string{"instance-id", "link-id", "mirror-topic-id"}, summary: "Promote a mirror topic to a writable topic", setup: noFlags(setMirrorTopicState("PROMOTED", "promoted"))},
+	{resource: "mirror-topics", action: "delete", args: []string{"instance-id", "link-id", "mirror-topic-id"}, summary: "Stop mirroring a topic", setup: noFlags(deleteMirrorTopic)},
+
+	// Connectors
+	{resource: "connectors", action: "list", summary: "List connectors", setup: listConnectors},
+	{resource: "connectors", action: "get", args: []string{"connector-id"}, summary: "Show a connector", setup: noFlags(getConnector)},
+	{resource: "connectors", action: "pause", args: []string{"connector-id"}, summary: "Pause a connector", setup: noFlags(pauseConnector)},
+	{resource: "connectors", action: "resume", args: []string{"connector-id"}, summary: "Resume a paused connector", setup: noFlags(resumeConnector)},
+	{resource: "connectors", action: "delete", args: []string{"connector-id"}, summary: "Delete a connector", setup: noFlags(deleteConnector)},
+
+	// Terraform
+	{resource: "terraform", action: "generate", summary: "Write the resources of the environment as Terraform configuration with import blocks", setup: generateTerraform},
+}
+
+var instanceColumns = []column[client.InstanceVO]{
+	{"ID", func(in client.InstanceVO) string { return str(in.InstanceId) }},
+	{"NAME", func(in client.InstanceVO) string { return str(in.Name) }},
+	{"STATE", func(in client.InstanceVO) string { return str(in.State) }},
+	{"VERSION", func(in client.InstanceVO) string { return str(in.Version) }},
+	{"CREATED", func(in client.InstanceVO) string {
+		if in.GmtCreate == nil {
+			return "-"
+		}
+		return in.GmtCreate.Format("2006-01-02 15:04:05")
+	}},
+}
+
+func listInstances(ctx context.Context, a *app, _ []string) error {
+	instances, err := a.client.ListKafkaInstances(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return printList(a, instances, instanceColumns...)
+}
+
+func getInstance(ctx context.Context, a *app, args []string) error {
+	instance, err := a.client.GetKafkaInstance(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printItem(a, *instance, instanceColumns...)
+}
+
+func instanceEndpoints(ctx context.Context, a *app, args []string) error {
+	endpoints, err := a.client.GetInstanceEndpoints(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printList(a, endpoints,
+		column[client.InstanceAccessInfoVO]{"NAME", func(e client.InstanceAccessInfoVO) string { return str(e.DisplayName) }},
+		column[client.InstanceAccessInfoVO]{"NETWORK", func(e client.InstanceAccessInfoVO) string { return str(e.NetworkType) }},
+		column[client.InstanceAccessInfoVO]{"PROTOCOL", func(e client.InstanceAccessInfoVO) string { return str(e.Protocol) }},
+		column[client.InstanceAccessInfoVO]{"MECHANISMS", func(e client.InstanceAccessInfoVO) string { return str(e.Mechanisms) }},
+		column[client.InstanceAccessInfoVO]{"BOOTSTRAP SERVERS", func(e client.InstanceAccessInfoVO) string { return str(e.BootstrapServers) }},
+	)
+}
+
+func instanceConfigs(ctx context.Context, a *app, args []string) error {
+	configs, err := a.client.GetInstanceConfigs(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printList(a, configs,
+		column[client.ConfigItemParam]{"KEY", func(c client.ConfigItemParam) string { return str(c.Key) }},
+		column[client.ConfigItemParam]{"VALUE", func(c client.ConfigItemParam) string { return str(c.Value) }},
+	)
+}
+
+var topicColumns = []column[client.TopicVO]{
+	{"ID", func(t client.TopicVO) string { return t.TopicId }},
+	{"NAME", func(t client.TopicVO) string { return t.Name }},
+	{"PARTITIONS", func(t client.TopicVO) string { return fmt.Sprint(t.Partition) }},
+}
+
+func listTopics(ctx context.Context, a *app, args []string) error {
+	topics, err := a.client.ListKafkaTopics(ctx, args[0], nil)
+	if err != nil {
+		return err
+	}
+	return printList(a, topics, topicColumns...)
+}
+
+func getTopic(ctx context.Context, a *app, args []string) error {
+	topic, err := a.client.GetKafkaTopic(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return printItem(a, *topic, topicColumns...)
+}
+
+func createTopic(fs *flag.FlagSet) runFunc {
+	partitions := fs.Int64("partitions", 16, "number of partitions")
+	configs := stringMapFlag{}
+	fs.Var(configs, "config", "topic configuration as key=value, repeatable")
+	return func(ctx context.Context, a *app, args []string) error {
+		param := client.TopicCreateParam{Name: args[1], Partition: *partitions, CompactStrategy: "DELETE"}
+		keys := make([]string, 0, len(configs))
+		for key := range configs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := configs[key]
+			param.Configs = append(param.Configs, client.ConfigItemParam{Key: &key, Value: &value})
+			if key == "cleanup.policy" {
+				param.CompactStrategy = strings.ToUpper(value)
+			}
+		}
+		topic, err := a.client.CreateKafkaTopic(ctx, args[0], param)
+		if err != nil {
+			return err
+		}
+		return printItem(a, *topic, topicColumns...)
+	}
+}
+
+func deleteTopic(ctx context.Context, a *app, args []string) error {
+	if err := a.client.DeleteKafkaTopic(ctx, args[0], args[1]); err != nil {
+		return err
+	}
+	return printDone(a, "topic %s deleted", args[1])
+}
+
+var userColumns = []column[client.KafkaUserVO]{
+	{"NAME", func(u client.KafkaUserVO) string { return u.Name }},
+	{"SASL MECHANISMS", func(u client.KafkaUserVO) string { return strings.Join(u.SupportedSaslMechanisms, ",") }},
+}
+
+func listUsers(ctx context.Context, a *app, args []string) error {
+	users, err := a.client.ListKafkaUsers(ctx, args[0], nil)
+	if err != nil {
+		return err
+	}
+	// Passwords are never printed.
+	for i := range users {
+		users[i].Password = ""
+	}
+	return printList(a, users, userColumns...)
+}
+
+func createUser(fs *flag.FlagSet) runFunc {
+	password := fs.String("password", "", "password of the user")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from the first line of standard input")
+	return func(ctx context.Context, a *app, args []string) error {
+		if *passwordStdin {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("failed to read password from standard input: %w", err)
+			}
+			*password = strings.TrimRight(line, "\r\n")
+		}
+		if *password == "" {
+			return errors.New("a password is required: set -password or -password-stdin")
+		}
+		user, err := a.client.CreateKafkaUser(ctx, args[0], client.InstanceUserCreateParam{Name: args[1], Password: *password})
+		if err != nil {
+			return err
+		}
+		user.Password = ""
+		return printItem(a, *user, userColumns...)
+	}
+}
+
+func deleteUser(ctx context.Context, a *app, args []string) error {
+	if err := a.client.DeleteKafkaUser(ctx, args[0], args[1]); err != nil {
+		return err
+	}
+	return printDone(a, "user %s deleted", args[1])
+}
+
+var aclColumns = []column[client.KafkaAclBindingVO]{
+	{"USER", func(b client.KafkaAclBindingVO) string { return b.AccessControl.User }},
+	{"HOST", func(b client.KafkaAclBindingVO) string { return str(b.AccessControl.Host) }},
+	{"OPERATIONS", func(b client.KafkaAclBindingVO) string { return b.AccessControl.OperationGroup.Name }},
+	{"PERMISSION", func(b client.KafkaAclBindingVO) string { return b.AccessControl.PermissionType }},
+	{"RESOURCE TYPE", func(b client.KafkaAclBindingVO) string { return b.ResourcePattern.ResourceType }},
+	{"RESOURCE", func(b client.KafkaAclBindingVO) string { return b.ResourcePattern.Name }},
+	{"PATTERN", func(b client.KafkaAclBindingVO) string { return b.ResourcePattern.PatternType }},
+}
+
+func listAcls(fs *flag.FlagSet) runFunc {
+	user := fs.String("user", "", "only list the bindings of this user, e.g. alice or User:alice")
+	return func(ctx context.Context, a *app, args []string) error {
+		var query map[string]string
+		if *user != "" {
+			query = map[string]string{"exactUser": principalUser(*user)}
+		}
+		acls, err := a.client.ListKafkaAcls(ctx, args[0], query)
+		if err != nil {
+			return err
+		}
+		bindings := acls[:0]
+		for _, acl := range acls {
+			if acl.AccessControl != nil && acl.ResourcePattern != nil {
+				bindings = append(bindings, acl)
+			}
+		}
+		return printList(a, bindings, aclColumns...)
+	}
+}
+
+// aclBinding registers the flags describing one ACL binding for the create
+// and delete commands.
+func aclBinding(run func(ctx context.Context, a *app, instanceID string, param client.KafkaAclBindingParam) error) func(*flag.FlagSet) runFunc {
+	return func(fs *flag.FlagSet) runFunc {
+		user := fs.String("user", "", "user name or principal, e.g. alice or User:alice (required)")
+		host := fs.String("host", "*", "host the principal connects from")
+		operationGroup := fs.String("operation-group", "ALL", "operation group: ALL, PRODUCE, CONSUME, ...")
+		permission := fs.String("permission", "ALLOW", "permission type: ALLOW or DENY")
+		resourceType := fs.String("resource-type", "TOPIC", "resource type: TOPIC, GROUP, CLUSTER or TRANSACTIONAL_ID")
+		resourceName := fs.String("resource-name", "", "resource name or prefix (required)")
+		patternType := fs.String("pattern-type", "LITERAL", "pattern type: LITERAL or PREFIXED")
+		return func(ctx context.Context, a *app, args []string) error {
+			if *user == "" || *resourceName == "" {
+				return errors.New("-user and -resource-name are required")
+			}
+			return run(ctx, a, args[0], client.KafkaAclBindingParam{
+				AccessControlParam: client.KafkaControlParam{
+					User:           principalUser(*user),
+					Host:           host,
+					OperationGroup: strings.ToUpper(*operationGroup),
+					PermissionType: strings.ToUpper(*permission),
+				},
+				ResourcePatternParam: client.KafkaResourcePatternParam{
+					ResourceType: strings.ToUpper(*resourceType),
+					Name:         *resourceName,
+					PatternType:  strings.ToUpper(*patternType),
+				},
+			})
+		}
+	}
+}
+
+// principalUser returns the user name of a User:name principal. The API
+// takes bare user names; the provider strips the prefix the same way.
+func principalUser(principal string) string {
+	return strings.TrimPrefix(principal, "User:")
+}
+
+func createAcl(ctx context.Context, a *app, instanceID string, param client.KafkaAclBindingParam) error {
+	binding, err := a.client.CreateKafkaAcls(ctx, instanceID, client.KafkaAclBindingParams{Params: []client.KafkaAclBindingParam{param}})
+	if err != nil {
+		return err
+	}
+	return printItem(a, *binding, aclColumns...)
+}
+
+func deleteAcl(ctx context.Context, a *app, instanceID string, param client.KafkaAclBindingParam) error {
+	if err := a.client.DeleteKafkaAcls(ctx, instanceID, client.KafkaAclBindingParams{Params: []client.KafkaAclBindingParam{param}}); err != nil {
+		return err
+	}
+	return printDone(a, "ACL binding deleted")
+}
+
+var linkColumns = []column[client.KafkaLinkVO]{
+	{"ID", func(l client.KafkaLinkVO) string { return l.LinkID }},
+	{"STATUS", func(l client.KafkaLinkVO) string { return str(l.Status) }},
+	{"SOURCE", func(l client.KafkaLinkVO) string {
+		if l.SourceCluster == nil {
+			return "-"
+		}
+		return l.SourceCluster.Endpoint
+	}},
+	{"START OFFSET TIME", func(l client.KafkaLinkVO) string { return l.StartOffsetTime }},
+}
+
+func listLinks(ctx context.Context, a *app, args []string) error {
+	links, err := a.client.ListKafkaLinks(ctx, args[0], nil)
+	if err != nil {
+		return err
+	}
+	return printList(a, links.List, linkColumns...)
+}
+
+func getLink(ctx context.Context, a *app, args []string) error {
+	link, err := a.client.GetKafkaLink(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return printItem(a, *link, linkColumns...)
+}
+
+func deleteLink(ctx context.Context, a *app, args []string) error {
+	if err := a.client.DeleteKafkaLink(ctx, args[0], args[1]); err != nil {
+		return err
+	}
+	return printDone(a, "link %s deleted", args[1])
+}
+
+func listMirrorTopics(ctx context.Context, a *app, args []string) error {
+	topics, err := a.client.ListKafkaLinkMirrorTopics(ctx, args[0], args[1], nil)
+	if err != nil {
+		return err
+	}
+	return printList(a, topics.List,
+		column[client.MirrorTopicVO]{"ID", func(t client.MirrorTopicVO) string { return str(t.MirrorTopicID) }},
+		column[client.MirrorTopicVO]{"SOURCE TOPIC", func(t client.MirrorTopicVO) string { return t.SourceTopicName }},
+		column[client.MirrorTopicVO]{"MIRROR TOPIC", func(t client.MirrorTopicVO) string { return str(t.MirrorTopicName) }},
+		column[client.MirrorTopicVO]{"STATE", func(t client.MirrorTopicVO) string {
+			if t.State == nil {
+				return "-"
+			}
+			return str(t.State.State)
+		}},
+	)
+}
+
+func setMirrorTopicState(state, done string) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		param := client.KafkaLinkMirrorTopicsUpdateParam{State: state}
+		if err := a.client.UpdateKafkaLinkMirrorTopic(ctx, args[0], args[1], args[2], param); err != nil {
+			return err
+		}
+		return printDone(a, "mirror topic %s %s", args[2], done)
+	}
+}
+
+func deleteMirrorTopic(ctx context.Context, a *app, args []string) error {
+	if err := a.client.DeleteKafkaLinkMirrorTopic(ctx, args[0], args[1], args[2]); err != nil {
+		return err
+	}
+	return printDone(a, "mirror topic %s deleted", args[2])
+}
+
+var connectorColumns = []column[client.ConnectorVO]{
+	{"ID", func(c client.ConnectorVO) string { return str(c.Id) }},
+	{"NAME", func(c client.ConnectorVO) string { return str(c.Name) }},
+	{"STATE", func(c client.ConnectorVO) string { return str(c.State) }},
+	{"CONNECT CLUSTER", func(c client.ConnectorVO) string { return str(c.ConnectClusterId) }},
+	{"CLASS", func(c client.ConnectorVO) string { return str(c.ConnectorClass) }},
+	{"TASKS", func(c client.ConnectorVO) string { return num(c.TaskCount) }},
+}
+
+func listConnectors(fs *flag.FlagSet) runFunc {
+	connectClusterID := fs.String("connect-cluster-id", "", "only list the connectors of this Connect cluster")
+	return func(ctx context.Context, a *app, _ []string) error {
+		var query map[string]string
+		if *connectClusterID != "" {
+			query = map[string]string{"connectClusterId": *connectClusterID}
+		}
+		connectors, err := a.client.ListConnectors(ctx, query)
+		if err != nil {
+			return err
+		}
+		return printList(a, connectors, connectorColumns...)
+	}
+}
+
+func getConnector(ctx context.Context, a *app, args []string) error {
+	connector, err := a.client.GetConnector(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printItem(a, *connector, connectorColumns...)
+}
+
+func pauseConnector(ctx context.Context, a *app, args []string) error {
+	connector, err := a.client.PauseConnector(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printItem(a, *connector, connectorColumns...)
+}
+
+func resumeConnector(ctx context.Context, a *app, args []string) error {
+	connector, err := a.client.ResumeConnector(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printItem(a, *connector, connectorColumns...)
+}
+
+func deleteConnector(ctx context.Context, a *app, args []string) error {
+	if err := a.client.DeleteConnector(ctx, args[0]); err != nil {
+		return err
+	}
+	return printDone(a, "connector %s deleted", args[0])
+}
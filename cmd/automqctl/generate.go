@@ -0,0 +1,539 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"terraform-provider-automq/client"
+	"terraform-provider-automq/internal/models"
+	"terraform-provider-automq/internal/provider"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Files written by terraform generate, in the order they are reported.
+const (
+	instancesFile       = "kafka_instances.tf"
+	topicsFile          = "kafka_topics.tf"
+	usersFile           = "kafka_users.tf"
+	aclsFile            = "kafka_acls.tf"
+	connectClustersFile = "connect_clusters.tf"
+	connectorsFile      = "connectors.tf"
+	variablesFile       = "variables.tf"
+)
+
+var generatedFiles = []string{instancesFile, topicsFile, usersFile, aclsFile, connectClustersFile, connectorsFile, variablesFile}
+
+var fileHeaders = map[string]string{
+	instancesFile: "Kafka instances. TLS certificates and private keys cannot be read back from the\n" +
+		"control plane: add them to features.security before applying.",
+	usersFile: "Kafka users. Passwords cannot be read back from the control plane: they are\n" +
+		"taken from the variables in " + variablesFile + ".",
+	aclsFile: "Kafka ACL bindings. automq_kafka_acl does not support import, so these blocks\n" +
+		"have no import block: Terraform creates each binding on the first apply.",
+	connectorsFile: "Connectors. Sensitive connector configuration cannot be read back from the\n" +
+		"control plane: add connector_config_sensitive where the plugin needs it.",
+	variablesFile: "Secrets of the generated resources, which the control plane never returns.",
+}
+
+// generateTerraform walks the environment with the list APIs and writes the
+// resources it finds as Terraform configuration with import blocks.
+func generateTerraform(fs *flag.FlagSet) runFunc {
+	dir := fs.String("dir", ".", "directory to write the .tf files to")
+	force := fs.Bool("force", false, "overwrite existing files")
+	instanceID := fs.String("instance-id", "", "only generate this Kafka instance and the resources that depend on it")
+	return func(ctx context.Context, a *app, _ []string) error {
+		if !*force {
+			for _, name := range generatedFiles {
+				if _, err := os.Stat(filepath.Join(*dir, name)); err == nil {
+					return fmt.Errorf("%s already exists: remove it or set -force", filepath.Join(*dir, name))
+				}
+			}
+		}
+		g := newGenerator(a.client, a.environmentID)
+		if err := g.walk(ctx, *instanceID); err != nil {
+			return err
+		}
+		written, err := g.write(*dir)
+		if err != nil {
+			return err
+		}
+		if a.format == outputJSON {
+			return printJSON(a, map[string]any{"resources": g.resources, "imports": g.imports, "files": written})
+		}
+		for _, name := range written {
+			fmt.Fprintln(a.stdout, name)
+		}
+		return printDone(a, "%d resources, %d import blocks", g.resources, g.imports)
+	}
+}
+
+// generator renders the resources of one environment as HCL.
+type generator struct {
+	client        *client.Client
+	environmentID string
+	files         map[string]*hclwrite.File
+	// labels holds the resource addresses in use, to keep them unique.
+	labels map[string]bool
+	// refs maps the ID of every generated resource to its id attribute, so
+	// that dependent resources reference it instead of repeating the ID.
+	refs      map[string]hcl.Traversal
+	resources int
+	imports   int
+}
+
+func newGenerator(c *client.Client, environmentID string) *generator {
+	return &generator{
+		client:        c,
+		environmentID: environmentID,
+		files:         map[string]*hclwrite.File{},
+		labels:        map[string]bool{},
+		refs:          map[string]hcl.Traversal{},
+	}
+}
+
+func (g *generator) walk(ctx context.Context, instanceID string) error {
+	instances, err := g.client.ListKafkaInstances(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to list Kafka instances: %w", err)
+	}
+	for _, summary := range instances {
+		if summary.InstanceId == nil || (instanceID != "" && *summary.InstanceId != instanceID) {
+			continue
+		}
+		if err := g.instance(ctx, *summary.InstanceId); err != nil {
+			return err
+		}
+	}
+
+	clusters, err := g.client.ListConnectClusters(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to list Connect clusters: %w", err)
+	}
+	clusterIDs := map[string]bool{}
+	for _, vo := range clusters {
+		if vo.Id == nil || (instanceID != "" && (vo.KafkaInstanceId == nil || *vo.KafkaInstanceId != instanceID)) {
+			continue
+		}
+		clusterIDs[*vo.Id] = true
+		var model models.ConnectClusterResourceModel
+		model.EnvironmentID = types.StringValue(g.environmentID)
+		if err := diagError(models.FlattenConnectCluster(&vo, &model)); err != nil {
+			return fmt.Errorf("connect cluster %s: %w", *vo.Id, err)
+		}
+		label := g.add(connectClustersFile, provider.NewConnectClusterResource(), "automq_connect_cluster", nameOr(vo.Name, *vo.Id), &model,
+			g.environmentID+"@"+*vo.Id)
+		g.refs[*vo.Id] = resourceID("automq_connect_cluster", label)
+	}
+
+	connectors, err := g.client.ListConnectors(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to list connectors: %w", err)
+	}
+	for _, vo := range connectors {
+		if vo.Id == nil || (instanceID != "" && (vo.ConnectClusterId == nil || !clusterIDs[*vo.ConnectClusterId])) {
+			continue
+		}
+		var model models.ConnectorResourceModel
+		model.EnvironmentID = types.StringValue(g.environmentID)
+		if err := diagError(models.FlattenConnector(&vo, &model)); err != nil {
+			return fmt.Errorf("connector %s: %w", *vo.Id, err)
+		}
+		g.add(connectorsFile, provider.NewConnectorResource(), "automq_connector", nameOr(vo.Name, *vo.Id), &model,
+			g.environmentID+"@"+*vo.Id)
+	}
+	return nil
+}
+
+// instance generates a Kafka instance with its topics, users and ACLs.
+func (g *generator) instance(ctx context.Context, instanceID string) error {
+	vo, err := g.client.GetKafkaInstance(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("unable to get Kafka instance %s: %w", instanceID, err)
+	}
+	var instance models.KafkaInstanceResourceModel
+	instance.EnvironmentID = types.StringValue(g.environmentID)
+	if err := diagError(models.FlattenKafkaInstanceModel(ctx, vo, &instance)); err != nil {
+		return fmt.Errorf("Kafka instance %s: %w", instanceID, err)
+	}
+	instanceLabel := g.add(instancesFile, provider.NewKafkaInstanceResource(), "automq_kafka_instance", nameOr(vo.Name, instanceID), &instance,
+		g.environmentID+"@"+instanceID)
+	g.refs[instanceID] = resourceID("automq_kafka_instance", instanceLabel)
+
+	topics, err := g.client.ListKafkaTopics(ctx, instanceID, nil)
+	if err != nil {
+		return fmt.Errorf("unable to list the topics of %s: %w", instanceID, err)
+	}
+	for _, vo := range topics {
+		// Internal topics such as __consumer_offsets are managed by Kafka.
+		if strings.HasPrefix(vo.Name, "__") {
+			continue
+		}
+		topic := models.KafkaTopicResourceModel{
+			EnvironmentID: types.StringValue(g.environmentID),
+			KafkaInstance: types.StringValue(instanceID),
+			Configs:       types.MapNull(types.StringType),
+		}
+		if err := diagError(models.FlattenKafkaTopic(&vo, &topic)); err != nil {
+			return fmt.Errorf("topic %s: %w", vo.TopicId, err)
+		}
+		if len(vo.Configs) > 0 {
+			configs := make(map[string]attr.Value, len(vo.Configs))
+			for key, value := range vo.Configs {
+				configs[key] = types.StringValue(fmt.Sprint(value))
+			}
+			topic.Configs = types.MapValueMust(types.StringType, configs)
+		}
+		g.add(topicsFile, provider.NewKafkaTopicResource(), "automq_kafka_topic", instanceLabel+"_"+vo.Name, &topic,
+			g.environmentID+"@"+instanceID+"@"+vo.TopicId)
+	}
+
+	users, err := g.client.ListKafkaUsers(ctx, instanceID, nil)
+	if err != nil {
+		return fmt.Errorf("unable to list the users of %s: %w", instanceID, err)
+	}
+	for _, vo := range users {
+		user := models.KafkaUserResourceModel{
+			EnvironmentID:   types.StringValue(g.environmentID),
+			KafkaInstanceID: types.StringValue(instanceID),
+		}
+		models.FlattenKafkaUserResource(&vo, &user)
+		g.add(usersFile, provider.NewKafkaUserResource(), "automq_kafka_user", instanceLabel+"_"+vo.Name, &user,
+			g.environmentID+"@"+instanceID+"@"+vo.Name)
+	}
+
+	acls, err := g.client.ListKafkaAcls(ctx, instanceID, nil)
+	if err != nil {
+		return fmt.Errorf("unable to list the ACLs of %s: %w", instanceID, err)
+	}
+	for _, vo := range acls {
+		if vo.AccessControl == nil || vo.ResourcePattern == nil {
+			continue
+		}
+		acl := models.KafkaAclResourceModel{
+			EnvironmentID: types.StringValue(g.environmentID),
+			KafkaInstance: types.StringValue(instanceID),
+		}
+		if err := diagError(models.FlattenKafkaACLResource(&vo, &acl)); err != nil {
+			return fmt.Errorf("ACL of %s: %w", instanceID, err)
+		}
+		name := strings.Join([]string{instanceLabel, vo.AccessControl.User, vo.ResourcePattern.ResourceType, vo.ResourcePattern.Name}, "_")
+		g.add(aclsFile, provider.NewKafkaAclResource(), "automq_kafka_acl", name, &acl, "")
+	}
+	return nil
+}
+
+// add renders model, the state the provider would read for the resource, as
+// a resource block followed by its import block, and returns its label.
+// Attributes the practitioner cannot set are left out, sensitive ones are
+// taken from variables, and the import block is only written for resources
+// that support import.
+func (g *generator) add(file string, res resource.Resource, resourceType, name string, model any, importID string) string {
+	label := g.label(resourceType, name)
+	var resp resource.SchemaResponse
+	res.Schema(context.Background(), resource.SchemaRequest{}, &resp)
+
+	body := g.file(file).Body()
+	if len(body.Blocks()) > 0 {
+		body.AppendNewline()
+	}
+	block := body.AppendNewBlock("resource", []string{resourceType, label}).Body()
+	r := rendering{generator: g, label: label, resourceType: resourceType}
+	for _, field := range r.object(reflect.ValueOf(model).Elem(), resp.Schema.Attributes, "") {
+		block.SetAttributeRaw(field.name, field.tokens)
+	}
+	g.resources++
+
+	if _, ok := res.(resource.ResourceWithImportState); ok && importID != "" {
+		body.AppendNewline()
+		imp := body.AppendNewBlock("import", nil).Body()
+		imp.SetAttributeTraversal("to", hcl.Traversal{hcl.TraverseRoot{Name: resourceType}, hcl.TraverseAttr{Name: label}})
+		imp.SetAttributeValue("id", cty.StringVal(importID))
+		g.imports++
+	}
+	return label
+}
+
+func (g *generator) file(name string) *hclwrite.File {
+	f, ok := g.files[name]
+	if !ok {
+		f = hclwrite.NewEmptyFile()
+		if header, ok := fileHeaders[name]; ok {
+			for _, line := range strings.Split(header, "\n") {
+				f.Body().AppendUnstructuredTokens(hclwrite.Tokens{{Type: hclsyntax.TokenComment, Bytes: []byte("# " + line + "\n")}})
+			}
+			f.Body().AppendNewline()
+		}
+		g.files[name] = f
+	}
+	return f
+}
+
+// label turns name into a unique resource label for resourceType.
+func (g *generator) label(resourceType, name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	base := strings.Trim(b.String(), "_")
+	if base == "" || !(unicode.IsLetter(rune(base[0])) || base[0] == '_') {
+		base = "r_" + base
+	}
+	label := base
+	for i := 2; g.labels[resourceType+"."+label]; i++ {
+		label = fmt.Sprintf("%s_%d", base, i)
+	}
+	g.labels[resourceType+"."+label] = true
+	return label
+}
+
+// variable declares a sensitive input variable and returns a reference to it.
+func (g *generator) variable(name, description string) hcl.Traversal {
+	body := g.file(variablesFile).Body()
+	if len(body.Blocks()) > 0 {
+		body.AppendNewline()
+	}
+	block := body.AppendNewBlock("variable", []string{name}).Body()
+	block.SetAttributeValue("description", cty.StringVal(description))
+	block.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+	block.SetAttributeValue("sensitive", cty.True)
+	return hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: name}}
+}
+
+// write writes the generated files to dir and returns their paths.
+func (g *generator) write(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, name := range generatedFiles {
+		f, ok := g.files[name]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, hclwrite.Format(f.Bytes()), 0o644); err != nil {
+			return nil, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// rendering renders the attributes of one resource.
+type rendering struct {
+	*generator
+	label        string
+	resourceType string
+}
+
+type renderedAttribute struct {
+	name   string
+	tokens hclwrite.Tokens
+}
+
+// object renders the tfsdk-tagged fields of the model struct v, in field
+// order, skipping the ones that are unset or not configurable.
+func (r rendering) object(v reflect.Value, attributes map[string]schema.Attribute, path string) []renderedAttribute {
+	var rendered []renderedAttribute
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("tfsdk")
+		attribute, ok := attributes[name]
+		if !ok || name == "timeouts" || (attribute.IsComputed() && !attribute.IsOptional()) {
+			continue
+		}
+		if tokens, ok := r.attribute(v.Field(i), name, attribute, path+name); ok {
+			rendered = append(rendered, renderedAttribute{name: name, tokens: tokens})
+		}
+	}
+	return rendered
+}
+
+func (r rendering) attribute(v reflect.Value, name string, attribute schema.Attribute, path string) (hclwrite.Tokens, bool) {
+	value, isValue := v.Interface().(attr.Value)
+	if attribute.IsSensitive() {
+		if !attribute.IsRequired() && (!isValue || value.IsNull() || value.IsUnknown()) {
+			return nil, false
+		}
+		variable := r.label + "_" + strings.ReplaceAll(path, ".", "_")
+		return hclwrite.TokensForTraversal(r.variable(variable, fmt.Sprintf("%s of %s.%s", path, r.resourceType, r.label))), true
+	}
+	if s, ok := value.(types.String); ok && strings.HasSuffix(name, "_id") && name != "environment_id" {
+		if ref, ok := r.refs[s.ValueString()]; ok {
+			return hclwrite.TokensForTraversal(ref), true
+		}
+	}
+	if isValue {
+		return r.value(value, attribute, path)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, false
+		}
+		return r.nested(v.Elem(), nestedAttributes(attribute), path)
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil, false
+		}
+		elems := make([]hclwrite.Tokens, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if tokens, ok := r.nested(v.Index(i), nestedAttributes(attribute), path); ok {
+				elems = append(elems, tokens)
+			}
+		}
+		return hclwrite.TokensForTuple(elems), true
+	}
+	return nil, false
+}
+
+func (r rendering) nested(v reflect.Value, attributes map[string]schema.Attribute, path string) (hclwrite.Tokens, bool) {
+	fields := r.object(v, attributes, path+".")
+	if len(fields) == 0 {
+		return nil, false
+	}
+	attrs := make([]hclwrite.ObjectAttrTokens, len(fields))
+	for i, field := range fields {
+		attrs[i] = hclwrite.ObjectAttrTokens{Name: hclwrite.TokensForIdentifier(field.name), Value: field.tokens}
+	}
+	return hclwrite.TokensForObject(attrs), true
+}
+
+// value renders a framework value. Null values and empty collections are
+// left out so that optional attributes keep their defaults.
+func (r rendering) value(value attr.Value, attribute schema.Attribute, path string) (hclwrite.Tokens, bool) {
+	if value == nil || value.IsNull() || value.IsUnknown() {
+		return nil, false
+	}
+	switch v := value.(type) {
+	case types.String:
+		return hclwrite.TokensForValue(cty.StringVal(v.ValueString())), true
+	case types.Int64:
+		return hclwrite.TokensForValue(cty.NumberIntVal(v.ValueInt64())), true
+	case types.Bool:
+		return hclwrite.TokensForValue(cty.BoolVal(v.ValueBool())), true
+	case types.List:
+		return r.elements(v.Elements(), attribute, path)
+	case types.Set:
+		return r.elements(v.Elements(), attribute, path)
+	case types.Map:
+		elems := v.Elements()
+		if len(elems) == 0 {
+			return nil, false
+		}
+		keys := make([]string, 0, len(elems))
+		for key := range elems {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		attrs := make([]hclwrite.ObjectAttrTokens, 0, len(keys))
+		for _, key := range keys {
+			if tokens, ok := r.value(elems[key], nil, path); ok {
+				attrs = append(attrs, hclwrite.ObjectAttrTokens{Name: objectKey(key), Value: tokens})
+			}
+		}
+		return hclwrite.TokensForObject(attrs), true
+	case types.Object:
+		attributes := nestedAttributes(attribute)
+		values := v.Attributes()
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var attrs []hclwrite.ObjectAttrTokens
+		for _, name := range names {
+			nested, ok := attributes[name]
+			if !ok || (nested.IsComputed() && !nested.IsOptional()) {
+				continue
+			}
+			if tokens, ok := r.attribute(reflect.ValueOf(values[name]), name, nested, path+"."+name); ok {
+				attrs = append(attrs, hclwrite.ObjectAttrTokens{Name: hclwrite.TokensForIdentifier(name), Value: tokens})
+			}
+		}
+		if len(attrs) == 0 {
+			return nil, false
+		}
+		return hclwrite.TokensForObject(attrs), true
+	}
+	return nil, false
+}
+
+func (r rendering) elements(values []attr.Value, attribute schema.Attribute, path string) (hclwrite.Tokens, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+	elems := make([]hclwrite.Tokens, 0, len(values))
+	for _, value := range values {
+		if tokens, ok := r.value(value, attribute, path); ok {
+			elems = append(elems, tokens)
+		}
+	}
+	return hclwrite.TokensForTuple(elems), true
+}
+
+// nestedAttributes returns the attributes of a nested attribute's objects.
+func nestedAttributes(attribute schema.Attribute) map[string]schema.Attribute {
+	switch a := attribute.(type) {
+	case schema.SingleNestedAttribute:
+		return a.Attributes
+	case schema.ListNestedAttribute:
+		return a.NestedObject.Attributes
+	case schema.SetNestedAttribute:
+		return a.NestedObject.Attributes
+	case schema.MapNestedAttribute:
+		return a.NestedObject.Attributes
+	}
+	return nil
+}
+
+// objectKey renders a map key, quoted unless it is a valid identifier.
+func objectKey(key string) hclwrite.Tokens {
+	if hclsyntax.ValidIdentifier(key) {
+		return hclwrite.TokensForIdentifier(key)
+	}
+	return hclwrite.TokensForValue(cty.StringVal(key))
+}
+
+func resourceID(resourceType, label string) hcl.Traversal {
+	return hcl.Traversal{hcl.TraverseRoot{Name: resourceType}, hcl.TraverseAttr{Name: label}, hcl.TraverseAttr{Name: "id"}}
+}
+
+func nameOr(name *string, id string) string {
+	if name == nil || *name == "" {
+		return id
+	}
+	return *name
+}
+
+func diagError(diags diag.Diagnostics) error {
+	if !diags.HasError() {
+		return nil
+	}
+	var messages []string
+	for _, d := range diags.Errors() {
+		messages = append(messages, d.Summary()+": "+d.Detail())
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
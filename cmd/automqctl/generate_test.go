@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"terraform-provider-automq/client"
+	"terraform-provider-automq/internal/fakeserver"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// addConnector creates a plugin, a Connect cluster on instanceID and a
+// connector on it, and returns the connector ID.
+func addConnector(t *testing.T, srv *fakeserver.Server, instanceID string) string {
+	t.Helper()
+	ctx := context.WithValue(context.Background(), client.EnvIdKey, fakeserver.EnvironmentID)
+	c, err := client.NewClient(ctx, srv.URL, client.AuthCredentials{AccessKeyID: fakeserver.AccessKeyID, SecretAccessKey: fakeserver.SecretAccessKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateConnectPlugin(ctx, client.ConnectPluginCreateParam{
+		Name:           "s3-sink",
+		Version:        "1.0.0",
+		StorageUrl:     "s3://plugins/s3-sink.zip",
+		Types:          []string{"SINK"},
+		ConnectorClass: "io.example.S3SinkConnector",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cluster, err := c.CreateConnectCluster(ctx, client.ConnectClusterCreateParam{
+		Name:         "connect",
+		Plugins:      []client.ClusterPluginParam{{Name: "s3-sink", Version: "1.0.0"}},
+		KafkaCluster: client.ConnectClusterKafkaParam{KafkaInstanceId: instanceID},
+		Capacity: client.ConnectClusterCapacityParam{
+			Type:        "PROVISIONED",
+			Provisioned: &client.ConnectClusterProvisionedParam{WorkerResourceSpec: "TIER1", WorkerCount: 2},
+		},
+		Compute: client.ConnectClusterComputeParam{Type: "KUBERNETES"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := client.ConnectorCreateParam{
+		ConnectClusterId: *cluster.Id,
+		Name:             "orders-sink",
+		ConnectorClass:   "io.example.S3SinkConnector",
+		TaskCount:        1,
+		ConnectorConfig:  &client.ConnectorConnectorConfigParam{Properties: map[string]string{"topics": "payments"}},
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		connector, err := c.CreateConnector(ctx, param)
+		if err == nil {
+			return *connector.Id
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func readGenerated(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, diags := hclparse.NewParser().ParseHCL(content, path); diags.HasErrors() {
+		t.Fatalf("%s is not valid HCL: %s\n%s", name, diags, content)
+	}
+	return string(content)
+}
+
+func TestGenerateTerraform(t *testing.T) {
+	srv, id := newTestServer(t)
+	for _, args := range [][]string{
+		{"topics", "create", id, "payments", "-partitions", "3", "-config", "retention.ms=3600000"},
+		{"users", "create", id, "alice", "-password", "s3cret-Passw0rd"},
+		{"acls", "create", id, "-user", "User:alice", "-resource-name", "payments", "-operation-group", "produce"},
+	} {
+		if code, _, stderr := runCommand(srv, args...); code != 0 {
+			t.Fatalf("%v: exit code %d: %s", args, code, stderr)
+		}
+	}
+	connectorID := addConnector(t, srv, id)
+
+	dir := t.TempDir()
+	code, stdout, stderr := runCommand(srv, "terraform", "generate", "-dir", dir)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "6 resources, 5 import blocks") {
+		t.Errorf("unexpected summary:\n%s", stdout)
+	}
+
+	expectations := map[string][]string{
+		instancesFile: {
+			`resource "automq_kafka_instance" "orders" {`,
+			`name           = "orders"`,
+			`reserved_aku = 3`,
+			"to = automq_kafka_instance.orders",
+			`id = "` + fakeserver.EnvironmentID + "@" + id + `"`,
+		},
+		topicsFile: {
+			`resource "automq_kafka_topic" "orders_payments" {`,
+			"kafka_instance_id = automq_kafka_instance.orders.id",
+			`"retention.ms" = "3600000"`,
+			`id = "` + fakeserver.EnvironmentID + "@" + id + "@",
+		},
+		usersFile: {
+			"password          = var.orders_alice_password",
+			`id = "` + fakeserver.EnvironmentID + "@" + id + `@alice"`,
+		},
+		aclsFile: {
+			`resource "automq_kafka_acl" "orders_alice_topic_payments" {`,
+			`operation_group   = "PRODUCE"`,
+		},
+		connectClustersFile: {
+			"kafka_instance_id = automq_kafka_instance.orders.id",
+			"to = automq_connect_cluster.connect",
+		},
+		connectorsFile: {
+			`resource "automq_connector" "orders_sink" {`,
+			"connect_cluster_id = automq_connect_cluster.connect.id",
+			`topics = "payments"`,
+			`id = "` + fakeserver.EnvironmentID + "@" + connectorID + `"`,
+		},
+		variablesFile: {
+			`variable "orders_alice_password" {`,
+			"sensitive   = true",
+		},
+	}
+	for name, want := range expectations {
+		content := readGenerated(t, dir, name)
+		for _, w := range want {
+			if !strings.Contains(content, w) {
+				t.Errorf("%s does not contain %q:\n%s", name, w, content)
+			}
+		}
+		if strings.Contains(content, "s3cret-Passw0rd") {
+			t.Errorf("%s contains the password of alice", name)
+		}
+	}
+	if content := readGenerated(t, dir, aclsFile); strings.Contains(content, "import {") {
+		t.Errorf("ACLs cannot be imported, got:\n%s", content)
+	}
+
+	if code, _, stderr = runCommand(srv, "terraform", "generate", "-dir", dir); code != 1 || !strings.Contains(stderr, "already exists") {
+		t.Errorf("second run: exit code %d, stderr %s", code, stderr)
+	}
+	if code, _, stderr = runCommand(srv, "terraform", "generate", "-dir", dir, "-force", "-instance-id", "kf-other"); code != 0 {
+		t.Errorf("-force: exit code %d, stderr %s", code, stderr)
+	}
+}
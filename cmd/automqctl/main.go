@@ -0,0 +1,178 @@
+// Command automqctl is a command line client for the AutoMQ BYOC control
+// plane, built on the same API client and credential chain as the Terraform
+// provider. It is meant for break-glass operations and scripting:
+//
+//	automqctl -environment-id env-1 instances list
+//	automqctl -output json topics get kf-1 topic-1
+//	automqctl terraform generate -dir ./imported
+//
+// The endpoint and environment default to AUTOMQ_BYOC_ENDPOINT and
+// AUTOMQ_ENVIRONMENT_ID. Credentials are resolved like in the provider: from
+// AUTOMQ_BYOC_ACCESS_KEY and AUTOMQ_BYOC_SECRET_KEY, then the shared
+// credentials file, then -credential-process.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"terraform-provider-automq/client"
+)
+
+const (
+	envEndpoint      = "AUTOMQ_BYOC_ENDPOINT"
+	envEnvironmentID = "AUTOMQ_ENVIRONMENT_ID"
+)
+
+// version is set by goreleaser.
+var version = "dev"
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// app holds what every command needs once the global flags are parsed.
+type app struct {
+	client        *client.Client
+	environmentID string
+	stdout        io.Writer
+	format        outputFormat
+}
+
+type globalOptions struct {
+	endpoint           string
+	environmentID      string
+	profile            string
+	credentialsFile    string
+	credentialProcess  string
+	output             string
+	timeout            time.Duration
+	caBundleFile       string
+	insecureSkipVerify bool
+	showVersion        bool
+}
+
+// run executes the command line args and returns the process exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	var opts globalOptions
+	fs := flag.NewFlagSet("automqctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.endpoint, "endpoint", os.Getenv(envEndpoint), "control plane endpoint (default $"+envEndpoint+")")
+	fs.StringVar(&opts.environmentID, "environment-id", os.Getenv(envEnvironmentID), "environment ID (default $"+envEnvironmentID+")")
+	fs.StringVar(&opts.profile, "profile", "", "profile of the shared credentials file (default $"+client.EnvProfile+", then \"default\")")
+	fs.StringVar(&opts.credentialsFile, "credentials-file", "", "shared credentials file (default $"+client.EnvSharedCredentialsFile+", then ~/.automq/credentials)")
+	fs.StringVar(&opts.credentialProcess, "credential-process", "", "command printing credentials as JSON, used when no other source has any")
+	fs.StringVar(&opts.output, "output", string(outputTable), "output format: table or json")
+	fs.DurationVar(&opts.timeout, "timeout", 5*time.Minute, "overall timeout of the command")
+	fs.StringVar(&opts.caBundleFile, "ca-bundle-file", "", "PEM file of additional CA certificates to trust")
+	fs.BoolVar(&opts.insecureSkipVerify, "insecure-skip-verify", false, "do not verify the control plane certificate (test environments only)")
+	fs.BoolVar(&opts.showVersion, "version", false, "print the version and exit")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if opts.showVersion {
+		fmt.Fprintln(stdout, "automqctl", version)
+		return 0
+	}
+
+	cmd, cmdArgs, err := findCommand(fs.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "automqctl: %s\n\n", err)
+		usage(fs)
+		return 2
+	}
+	format, err := parseOutputFormat(opts.output)
+	if err != nil {
+		fmt.Fprintf(stderr, "automqctl: %s\n", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, client.EnvIdKey, opts.environmentID)
+
+	c, err := newClient(ctx, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "automqctl: %s\n", err)
+		return 1
+	}
+	a := &app{client: c, environmentID: opts.environmentID, stdout: stdout, format: format}
+	if err := cmd.execute(ctx, a, cmdArgs, stderr); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(stderr, "automqctl: %s\n", strings.TrimSpace(err.Error()))
+		return 1
+	}
+	return 0
+}
+
+func newClient(ctx context.Context, opts globalOptions) (*client.Client, error) {
+	if opts.endpoint == "" {
+		return nil, fmt.Errorf("no control plane endpoint: set -endpoint or %s", envEndpoint)
+	}
+	if opts.environmentID == "" {
+		return nil, fmt.Errorf("no environment ID: set -environment-id or %s", envEnvironmentID)
+	}
+	endpoint, err := url.Parse(opts.endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q: expected a URL such as https://automq.example.com", opts.endpoint)
+	}
+	baseURL := fmt.Sprintf("%s://%s%s", endpoint.Scheme, endpoint.Host, strings.TrimRight(endpoint.Path, "/"))
+
+	credentials := client.NewCredentialsCache(client.ChainCredentialsProvider{Providers: []client.CredentialsProvider{
+		client.EnvCredentialsProvider{},
+		client.SharedCredentialsProvider{Filename: opts.credentialsFile, Profile: opts.profile},
+		client.ProcessCredentialsProvider{Command: opts.credentialProcess},
+	}})
+	credential, err := credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load credentials: %w", err)
+	}
+
+	var transport http.RoundTripper
+	if opts.caBundleFile != "" || opts.insecureSkipVerify {
+		custom, err := client.NewTransport(client.TransportConfig{
+			CABundleFile:       opts.caBundleFile,
+			InsecureSkipVerify: opts.insecureSkipVerify,
+		})
+		if err != nil {
+			return nil, err
+		}
+		transport = custom
+	}
+	return client.NewClient(ctx, baseURL, credential, func(c *client.Client) {
+		c.CredentialsProvider = credentials
+		if transport != nil {
+			c.HTTPClient.Transport = transport
+		}
+	})
+}
+
+func usage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintln(out, "Usage: automqctl [flags] <resource> <action> [arguments]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+	w := tabwriter.NewWriter(out, 0, 4, 3, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s %s %s\t%s\n", cmd.resource, cmd.action, cmd.argsUsage(), cmd.summary)
+	}
+	_ = w.Flush()
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	fs.PrintDefaults()
+}
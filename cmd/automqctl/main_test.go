@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"terraform-provider-automq/client"
+	"terraform-provider-automq/internal/fakeserver"
+	"testing"
+)
+
+func newTestServer(t *testing.T) (*fakeserver.Server, string) {
+	t.Helper()
+	t.Setenv(client.EnvAccessKey, fakeserver.AccessKeyID)
+	t.Setenv(client.EnvSecretKey, fakeserver.SecretAccessKey)
+	t.Setenv(envEndpoint, "")
+	t.Setenv(envEnvironmentID, "")
+	srv := fakeserver.New()
+	t.Cleanup(srv.Close)
+	id, err := srv.AddInstance(fakeserver.EnvironmentID, client.InstanceCreateParam{
+		Name:    "orders",
+		Version: "5.2.0",
+		Spec:    client.SpecificationParam{ReservedAku: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, id
+}
+
+// runCommand runs automqctl against srv and returns its exit code and
+// standard output and error.
+func runCommand(srv *fakeserver.Server, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	global := []string{"-endpoint", srv.URL, "-environment-id", fakeserver.EnvironmentID}
+	code := run(context.Background(), append(global, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestInstancesTable(t *testing.T) {
+	srv, id := newTestServer(t)
+
+	code, stdout, stderr := runCommand(srv, "instances", "list")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") {
+		t.Fatalf("unexpected table:\n%s", stdout)
+	}
+	if fields := strings.Fields(lines[1]); fields[0] != id || fields[1] != "orders" || fields[2] != "Running" {
+		t.Errorf("row = %v", fields)
+	}
+}
+
+func TestTopicsAndUsersJSON(t *testing.T) {
+	srv, id := newTestServer(t)
+
+	code, stdout, stderr := runCommand(srv, "-output", "json", "topics", "create", id, "payments", "-partitions", "3", "-config", "retention.ms=3600000")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	var topic client.TopicVO
+	if err := json.Unmarshal([]byte(stdout), &topic); err != nil {
+		t.Fatalf("output is not a topic: %v\n%s", err, stdout)
+	}
+	if topic.Name != "payments" || topic.Partition != 3 || topic.Configs["retention.ms"] != "3600000" {
+		t.Errorf("topic = %+v", topic)
+	}
+
+	code, stdout, stderr = runCommand(srv, "-output", "json", "topics", "list", id)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	var topics []client.TopicVO
+	if err := json.Unmarshal([]byte(stdout), &topics); err != nil || len(topics) != 1 {
+		t.Fatalf("topics list = %s (%v)", stdout, err)
+	}
+
+	if code, _, stderr = runCommand(srv, "users", "create", id, "alice", "-password", "s3cret-Passw0rd"); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	code, stdout, _ = runCommand(srv, "-output", "json", "users", "list", id)
+	if code != 0 || !strings.Contains(stdout, "alice") || strings.Contains(stdout, "s3cret-Passw0rd") {
+		t.Errorf("users list printed %s", stdout)
+	}
+}
+
+func TestAcls(t *testing.T) {
+	srv, id := newTestServer(t)
+
+	binding := []string{"-user", "User:alice", "-resource-name", "orders", "-operation-group", "produce"}
+	if code, _, stderr := runCommand(srv, append([]string{"acls", "create", id}, binding...)...); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	_, stdout, _ := runCommand(srv, "acls", "list", id, "-user", "alice")
+	if fields := strings.Fields(strings.Split(strings.TrimSpace(stdout), "\n")[1]); fields[0] != "alice" || fields[2] != "PRODUCE" {
+		t.Errorf("acls list:\n%s", stdout)
+	}
+	if code, _, stderr := runCommand(srv, append([]string{"acls", "delete", id}, binding...)...); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	_, stdout, _ = runCommand(srv, "-output", "json", "acls", "list", id)
+	if strings.TrimSpace(stdout) != "[]" {
+		t.Errorf("acls left after delete: %s", stdout)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	code, _, stderr := runCommand(srv, "instances", "get", "kf-missing")
+	if code != 1 || !strings.Contains(stderr, "ResourceNotFound") {
+		t.Errorf("missing instance: exit code %d, stderr %s", code, stderr)
+	}
+	if code, _, _ = runCommand(srv, "instances", "get"); code != 2 {
+		t.Errorf("missing argument: exit code %d, want 2", code)
+	}
+	if code, _, stderr = runCommand(srv, "clusters", "list"); code != 2 || !strings.Contains(stderr, "unknown command") {
+		t.Errorf("unknown command: exit code %d, stderr %s", code, stderr)
+	}
+
+	var stdout, errOut bytes.Buffer
+	if code := run(context.Background(), []string{"instances", "list"}, &stdout, &errOut); code != 1 || !strings.Contains(errOut.String(), envEndpoint) {
+		t.Errorf("missing endpoint: exit code %d, stderr %s", code, errOut.String())
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+)
+
+func parseOutputFormat(value string) (outputFormat, error) {
+	switch format := outputFormat(strings.ToLower(value)); format {
+	case outputTable, outputJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown output format %q: expected table or json", value)
+}
+
+// column renders one field of T in table output.
+type column[T any] struct {
+	header string
+	value  func(T) string
+}
+
+// printList writes items as a JSON array or as a table with one row per
+// item.
+func printList[T any](a *app, items []T, columns ...column[T]) error {
+	if a.format == outputJSON {
+		if items == nil {
+			items = []T{}
+		}
+		return printJSON(a, items)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.header
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, item := range items {
+		values := make([]string, len(columns))
+		for i, c := range columns {
+			values[i] = c.value(item)
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	return w.Flush()
+}
+
+// printItem writes item as a JSON object or as a one-row table.
+func printItem[T any](a *app, item T, columns ...column[T]) error {
+	if a.format == outputJSON {
+		return printJSON(a, item)
+	}
+	return printList(a, []T{item}, columns...)
+}
+
+// printDone reports a successful command that returns nothing.
+func printDone(a *app, format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	if a.format == outputJSON {
+		return printJSON(a, map[string]string{"result": message})
+	}
+	_, err := fmt.Fprintln(a.stdout, message)
+	return err
+}
+
+func printJSON(a *app, v any) error {
+	encoder := json.NewEncoder(a.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// str renders an optional field, "-" when it is not set.
+func str(v *string) string {
+	if v == nil || *v == "" {
+		return "-"
+	}
+	return *v
+}
+
+func num[T int | int32 | int64](v *T) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprint(*v)
+}
@@ -10,6 +10,14 @@ provider "automq" {
   automq_byoc_endpoint      = var.automq_byoc_endpoint      # optionally use AUTOMQ_BYOC_ENDPOINT environment variable
   automq_byoc_access_key_id = var.automq_byoc_access_key_id # optionally use AUTOMQ_BYOC_ACCESS_KEY_ID environment variable
   automq_byoc_secret_key    = var.automq_byoc_secret_key    # optionally use AUTOMQ_BYOC_SECRET_KEY environment variable
+
+  # Merged into the tags of every Kafka instance and Connect cluster.
+  default_tags {
+    tags = {
+      team        = "streaming"
+      cost-center = "cc-42"
+    }
+  }
 }
 
 variable "automq_byoc_endpoint" {
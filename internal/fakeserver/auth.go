@@ -0,0 +1,79 @@
+package fakeserver
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"terraform-provider-automq/client/signer"
+	"time"
+)
+
+const (
+	authorizationPrefix = "AUTOMQ-HMAC-SHA256 "
+	signingTimeFormat   = "20060102T150405Z"
+	// signingWindow is how far the signing time of a request may be from
+	// the server clock.
+	signingWindow = 15 * time.Minute
+)
+
+// verifySignature checks the AUTOMQ-HMAC-SHA256 signature of r by signing a
+// copy of it again with the secret of its access key. It returns the status,
+// error code and message to reject the request with, or a zero status.
+func (s *Server) verifySignature(r *http.Request, body []byte) (int, string, string) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, authorizationPrefix) {
+		return http.StatusUnauthorized, "MissingAuthenticationToken", "the request is not signed"
+	}
+	var accessKeyID, signedHeaders string
+	for _, part := range strings.Split(strings.TrimPrefix(auth, authorizationPrefix), ", ") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "Credential":
+			accessKeyID, _, _ = strings.Cut(value, "/")
+		case "SignedHeaders":
+			signedHeaders = value
+		}
+	}
+	secret, ok := s.credentials[accessKeyID]
+	if !ok {
+		return http.StatusForbidden, "InvalidAccessKeyId", "the access key ID does not exist"
+	}
+	signTime, err := time.Parse(signingTimeFormat, r.Header.Get("X-Automq-Date"))
+	if err != nil {
+		return http.StatusForbidden, "IncompleteSignature", "the X-Automq-Date header is missing or malformed"
+	}
+	if s.now().Sub(signTime).Abs() > signingWindow {
+		return http.StatusForbidden, "RequestTimeTooSkewed", "the difference between the request time and the server time is too large"
+	}
+
+	expected, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	if err != nil {
+		return http.StatusBadRequest, "InvalidRequest", err.Error()
+	}
+	expected.Host = r.Host
+	for _, name := range strings.Split(signedHeaders, ";") {
+		if name != "host" {
+			key := textproto.CanonicalMIMEHeaderKey(name)
+			expected.Header[key] = r.Header.Values(key)
+		}
+	}
+	var payload io.ReadSeeker
+	if len(body) > 0 {
+		payload = bytes.NewReader(body)
+	}
+	credentials := signer.Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secret,
+		SessionToken:    r.Header.Get("X-Automq-Security-Token"),
+	}
+	if _, err := signer.NewSigner(credentials).Sign(expected, payload, "cmp", "private", signTime); err != nil {
+		return http.StatusBadRequest, "InvalidRequest", err.Error()
+	}
+	if subtle.ConstantTimeCompare([]byte(expected.Header.Get("Authorization")), []byte(auth)) != 1 {
+		return http.StatusForbidden, "SignatureDoesNotMatch", "the request signature does not match the signature calculated by the server"
+	}
+	return 0, "", ""
+}
@@ -0,0 +1,511 @@
+package fakeserver
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"terraform-provider-automq/client"
+	"time"
+)
+
+type connectCluster struct {
+	lifecycle
+	env string
+	vo  client.ConnectClusterVO
+}
+
+func (c *connectCluster) view() client.ConnectClusterVO {
+	vo := c.vo
+	vo.State = ptr(c.state)
+	return vo
+}
+
+type connector struct {
+	lifecycle
+	env string
+	vo  client.ConnectorVO
+}
+
+func (c *connector) view() client.ConnectorVO {
+	vo := c.vo
+	vo.State = ptr(c.state)
+	return vo
+}
+
+type plugin struct {
+	lifecycle
+	env string
+	vo  client.ConnectPluginVO
+}
+
+func (p *plugin) view() client.ConnectPluginVO {
+	vo := p.vo
+	vo.Status = ptr(p.state)
+	return vo
+}
+
+func (s *Server) registerConnectRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/connect/clusters", s.createConnectCluster)
+	mux.HandleFunc("GET /api/v1/connect/clusters", s.listConnectClusters)
+	mux.HandleFunc("GET /api/v1/connect/clusters/{clusterId}", s.getConnectCluster)
+	mux.HandleFunc("PUT /api/v1/connect/clusters/{clusterId}", s.updateConnectCluster)
+	mux.HandleFunc("DELETE /api/v1/connect/clusters/{clusterId}", s.deleteConnectCluster)
+
+	mux.HandleFunc("POST /api/v1/connect/connectors", s.createConnector)
+	mux.HandleFunc("GET /api/v1/connect/connectors", s.listConnectors)
+	mux.HandleFunc("GET /api/v1/connect/connectors/{connectorId}", s.getConnector)
+	mux.HandleFunc("PUT /api/v1/connect/connectors/{connectorId}", s.updateConnector)
+	mux.HandleFunc("DELETE /api/v1/connect/connectors/{connectorId}", s.deleteConnector)
+	// Pause and resume are custom methods: /connectors/{id}:pause.
+	mux.HandleFunc("POST /api/v1/connect/connectors/{connectorAction}", s.connectorAction)
+
+	mux.HandleFunc("POST /api/v1/connect/plugins", s.createPlugin)
+	mux.HandleFunc("GET /api/v1/connect/plugins", s.listPlugins)
+	mux.HandleFunc("GET /api/v1/connect/plugins/{pluginId}", s.getPlugin)
+	mux.HandleFunc("DELETE /api/v1/connect/plugins/{pluginId}", s.deletePlugin)
+}
+
+func (s *Server) connectCluster(env, id string) *connectCluster {
+	s.connectClusters = settled(s.connectClusters, s.now())
+	for _, c := range s.connectClusters {
+		if c.env == env && *c.vo.Id == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) createConnectCluster(w http.ResponseWriter, r *http.Request) {
+	var param client.ConnectClusterCreateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if param.Name == "" || param.Capacity.Type == "" || param.Compute.Type == "" {
+		invalidParameter(w, "name, capacity.type and compute.type are required")
+		return
+	}
+	kafkaID := param.KafkaCluster.KafkaInstanceId
+	if in := s.instance(envID(r), kafkaID); in == nil {
+		notFound(w, "instance", kafkaID)
+		return
+	} else if in.state != instanceRunning {
+		invalidState(w, "instance", kafkaID, in.state)
+		return
+	}
+	for _, c := range settled(s.connectClusters, s.now()) {
+		if c.env == envID(r) && *c.vo.Name == param.Name {
+			conflict(w, "connect cluster", param.Name)
+			return
+		}
+	}
+
+	now := s.now()
+	c := &connectCluster{env: envID(r), vo: client.ConnectClusterVO{
+		Id:                  ptr(s.newID("connect")),
+		KafkaInstanceId:     ptr(kafkaID),
+		IamRole:             param.Compute.IamRole,
+		KafkaConnectVersion: ptr("3.9.0"),
+		CreateTime:          &now,
+	}}
+	if k8s := param.Compute.Kubernetes; k8s != nil {
+		c.vo.KubernetesClusterId = ptr(k8s.ClusterId)
+		c.vo.KubernetesNamespace = ptr(k8s.Namespace)
+		c.vo.KubernetesServiceAccount = ptr(k8s.ServiceAccount)
+		c.vo.SchedulingSpec = k8s.SchedulingSpec
+	}
+	applyConnectClusterUpdate(&c.vo, client.ConnectClusterUpdateParam{
+		Name:           &param.Name,
+		Description:    param.Description,
+		Plugins:        param.Plugins,
+		Capacity:       &param.Capacity,
+		WorkerConfig:   param.WorkerConfig,
+		MetricExporter: param.MetricExporter,
+		Tags:           param.Tags,
+		Version:        param.Version,
+	}, now)
+	s.transition(&c.lifecycle, client.ConnectClusterStateCreating, client.ConnectClusterStateRunning)
+	s.connectClusters = append(s.connectClusters, c)
+	writeJSON(w, http.StatusOK, c.view())
+}
+
+// applyConnectClusterUpdate sets the fields of param that are not nil on
+// vo.
+func applyConnectClusterUpdate(vo *client.ConnectClusterVO, param client.ConnectClusterUpdateParam, now time.Time) {
+	if param.Name != nil {
+		vo.Name = param.Name
+	}
+	if param.Description != nil {
+		vo.Description = param.Description
+	}
+	if param.Plugins != nil {
+		vo.Plugins = nil
+		for _, p := range param.Plugins {
+			vo.Plugins = append(vo.Plugins, client.ClusterPluginVO{Name: ptr(p.Name), Version: ptr(p.Version)})
+		}
+	}
+	if capacity := param.Capacity; capacity != nil {
+		vo.CapacityType = ptr(capacity.Type)
+		vo.WorkerCount, vo.MinWorkerCount, vo.MaxWorkerCount = nil, nil, nil
+		vo.ScaleInCpuPercent, vo.ScaleOutCpuPercent = nil, nil
+		if p := capacity.Provisioned; p != nil {
+			vo.WorkerResourceSpec = ptr(p.WorkerResourceSpec)
+			vo.WorkerCount = ptr(p.WorkerCount)
+		}
+		if a := capacity.Autoscaling; a != nil {
+			vo.WorkerResourceSpec = ptr(a.WorkerResourceSpec)
+			vo.MinWorkerCount = ptr(a.MinWorkerCount)
+			vo.MaxWorkerCount = ptr(a.MaxWorkerCount)
+			if a.ScaleInPolicy != nil {
+				vo.ScaleInCpuPercent = ptr(a.ScaleInPolicy.CpuUtilizationPercentage)
+			}
+			if a.ScaleOutPolicy != nil {
+				vo.ScaleOutCpuPercent = ptr(a.ScaleOutPolicy.CpuUtilizationPercentage)
+			}
+		}
+	}
+	if param.WorkerConfig != nil {
+		vo.WorkerConfig = map[string]interface{}{}
+		for key, value := range param.WorkerConfig.Properties {
+			vo.WorkerConfig[key] = value
+		}
+	}
+	if param.MetricExporter != nil {
+		vo.MetricExporter = nil
+		if rw := param.MetricExporter.RemoteWrite; rw != nil {
+			remoteWrite := &client.ConnectRemoteWriteConfigVO{}
+			_ = convert(rw, remoteWrite)
+			vo.MetricExporter = &client.ConnectMetricsConfigVO{RemoteWrite: remoteWrite}
+		}
+	}
+	if param.Tags != nil {
+		vo.Tags = param.Tags
+	}
+	if param.Version != nil {
+		vo.Version = param.Version
+	}
+	vo.UpdateTime = &now
+}
+
+func (s *Server) listConnectClusters(w http.ResponseWriter, r *http.Request) {
+	var list []client.ConnectClusterVO
+	for _, c := range settled(s.connectClusters, s.now()) {
+		if c.env == envID(r) {
+			list = append(list, c.view())
+		}
+	}
+	writePage(w, r, list)
+}
+
+func (s *Server) getConnectCluster(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("clusterId")
+	c := s.connectCluster(envID(r), id)
+	if c == nil {
+		notFound(w, "connect cluster", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, c.view())
+}
+
+func (s *Server) updateConnectCluster(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("clusterId")
+	c := s.connectCluster(envID(r), id)
+	if c == nil {
+		notFound(w, "connect cluster", id)
+		return
+	}
+	if c.state != client.ConnectClusterStateRunning {
+		invalidState(w, "connect cluster", id, c.state)
+		return
+	}
+	var param client.ConnectClusterUpdateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	applyConnectClusterUpdate(&c.vo, param, s.now())
+	s.transition(&c.lifecycle, client.ConnectClusterStateChanging, client.ConnectClusterStateRunning)
+	writeJSON(w, http.StatusOK, c.view())
+}
+
+func (s *Server) deleteConnectCluster(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("clusterId")
+	c := s.connectCluster(envID(r), id)
+	if c == nil {
+		notFound(w, "connect cluster", id)
+		return
+	}
+	for _, conn := range settled(s.connectors, s.now()) {
+		if *conn.vo.ConnectClusterId == id {
+			writeError(w, http.StatusConflict, "ResourceInUse", "connect cluster "+id+" still has connectors")
+			return
+		}
+	}
+	if c.state != client.ConnectClusterStateDeleting {
+		s.transition(&c.lifecycle, client.ConnectClusterStateDeleting, removed)
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (s *Server) connector(env, id string) *connector {
+	s.connectors = settled(s.connectors, s.now())
+	for _, c := range s.connectors {
+		if c.env == env && *c.vo.Id == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) createConnector(w http.ResponseWriter, r *http.Request) {
+	var param client.ConnectorCreateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if param.Name == "" || param.ConnectorClass == "" || param.TaskCount < 1 {
+		invalidParameter(w, "name, connectorClass and a positive taskCount are required")
+		return
+	}
+	cluster := s.connectCluster(envID(r), param.ConnectClusterId)
+	if cluster == nil {
+		notFound(w, "connect cluster", param.ConnectClusterId)
+		return
+	}
+	if cluster.state != client.ConnectClusterStateRunning {
+		invalidState(w, "connect cluster", param.ConnectClusterId, cluster.state)
+		return
+	}
+	for _, c := range settled(s.connectors, s.now()) {
+		if c.env == envID(r) && *c.vo.Name == param.Name {
+			conflict(w, "connector", param.Name)
+			return
+		}
+	}
+
+	now := s.now()
+	c := &connector{env: envID(r), vo: client.ConnectorVO{
+		Id:                       ptr(s.newID("connector")),
+		ConnectClusterId:         ptr(param.ConnectClusterId),
+		Name:                     ptr(param.Name),
+		Description:              param.Description,
+		ConnectorClass:           ptr(param.ConnectorClass),
+		TaskCount:                ptr(param.TaskCount),
+		KafkaInstanceId:          cluster.vo.KafkaInstanceId,
+		KubernetesClusterId:      cluster.vo.KubernetesClusterId,
+		KubernetesNamespace:      cluster.vo.KubernetesNamespace,
+		KubernetesServiceAccount: cluster.vo.KubernetesServiceAccount,
+		IamRole:                  cluster.vo.IamRole,
+		WorkerCount:              cluster.vo.WorkerCount,
+		WorkerResourceSpec:       cluster.vo.WorkerResourceSpec,
+		ConnectorConfig:          connectorConfig(param.ConnectorConfig),
+		CreateTime:               &now,
+		UpdateTime:               &now,
+	}}
+	if kafka := param.KafkaCluster; kafka != nil && kafka.SecurityProtocolConfig != nil {
+		// Secrets are write-only.
+		config := *kafka.SecurityProtocolConfig
+		config.Password, config.KeyPassword, config.PrivateKey = nil, nil, nil
+		c.vo.SecurityProtocolConfig = &config
+	}
+	for _, p := range settled(s.plugins, s.now()) {
+		if p.env == envID(r) && *p.vo.ConnectorClass == param.ConnectorClass {
+			c.vo.PluginId = p.vo.Id
+			if slices.Contains(p.vo.Types, "SOURCE") {
+				c.vo.ConnectorType = ptr("SOURCE")
+			} else {
+				c.vo.ConnectorType = ptr("SINK")
+			}
+			break
+		}
+	}
+	s.transition(&c.lifecycle, client.ConnectorStateCreating, client.ConnectorStateRunning)
+	s.connectors = append(s.connectors, c)
+	writeJSON(w, http.StatusOK, c.view())
+}
+
+func connectorConfig(param *client.ConnectorConnectorConfigParam) map[string]interface{} {
+	if param == nil || param.Properties == nil {
+		return nil
+	}
+	config := make(map[string]interface{}, len(param.Properties))
+	for key, value := range param.Properties {
+		config[key] = value
+	}
+	return config
+}
+
+func (s *Server) listConnectors(w http.ResponseWriter, r *http.Request) {
+	clusterID := r.URL.Query().Get("connectClusterId")
+	var list []client.ConnectorVO
+	for _, c := range settled(s.connectors, s.now()) {
+		if c.env == envID(r) && (clusterID == "" || *c.vo.ConnectClusterId == clusterID) {
+			list = append(list, c.view())
+		}
+	}
+	writePage(w, r, list)
+}
+
+func (s *Server) getConnector(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("connectorId")
+	c := s.connector(envID(r), id)
+	if c == nil {
+		notFound(w, "connector", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, c.view())
+}
+
+// updateConnector reconfigures a connector. A paused connector stays paused
+// once the change is applied.
+func (s *Server) updateConnector(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("connectorId")
+	c := s.connector(envID(r), id)
+	if c == nil {
+		notFound(w, "connector", id)
+		return
+	}
+	if c.state != client.ConnectorStateRunning && c.state != client.ConnectorStatePaused {
+		invalidState(w, "connector", id, c.state)
+		return
+	}
+	var param client.ConnectorUpdateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if param.Name != nil {
+		c.vo.Name = param.Name
+	}
+	if param.Description != nil {
+		c.vo.Description = param.Description
+	}
+	if param.TaskCount != nil {
+		c.vo.TaskCount = param.TaskCount
+	}
+	if param.ConnectorConfig != nil {
+		c.vo.ConnectorConfig = connectorConfig(param.ConnectorConfig)
+	}
+	now := s.now()
+	c.vo.UpdateTime = &now
+	s.transition(&c.lifecycle, client.ConnectorStateChanging, c.state)
+	writeJSON(w, http.StatusOK, c.view())
+}
+
+func (s *Server) deleteConnector(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("connectorId")
+	c := s.connector(envID(r), id)
+	if c == nil {
+		notFound(w, "connector", id)
+		return
+	}
+	if c.state != client.ConnectorStateDeleting {
+		s.transition(&c.lifecycle, client.ConnectorStateDeleting, removed)
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// connectorAction serves POST /connectors/{id}:pause and :resume. Both are
+// idempotent.
+func (s *Server) connectorAction(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(r.PathValue("connectorAction"), ":")
+	c := s.connector(envID(r), id)
+	if c == nil {
+		notFound(w, "connector", id)
+		return
+	}
+	var from, to string
+	switch action {
+	case "pause":
+		from, to = client.ConnectorStateRunning, client.ConnectorStatePaused
+	case "resume":
+		from, to = client.ConnectorStatePaused, client.ConnectorStateRunning
+	default:
+		writeError(w, http.StatusNotFound, "ResourceNotFound", "unknown connector action "+action)
+		return
+	}
+	if c.state != from && c.state != to {
+		invalidState(w, "connector", id, c.state)
+		return
+	}
+	c.state = to
+	writeJSON(w, http.StatusOK, c.view())
+}
+
+func (s *Server) plugin(env, id string) *plugin {
+	s.plugins = settled(s.plugins, s.now())
+	for _, p := range s.plugins {
+		if p.env == env && *p.vo.Id == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) createPlugin(w http.ResponseWriter, r *http.Request) {
+	var param client.ConnectPluginCreateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if param.Name == "" || param.Version == "" || param.ConnectorClass == "" {
+		invalidParameter(w, "name, version and connectorClass are required")
+		return
+	}
+	if !strings.HasPrefix(param.StorageUrl, "s3://") && !strings.HasPrefix(param.StorageUrl, "https://") {
+		invalidParameter(w, "storageUrl must be an s3:// or https:// URL")
+		return
+	}
+	for _, p := range settled(s.plugins, s.now()) {
+		if p.env == envID(r) && *p.vo.Name == param.Name {
+			conflict(w, "plugin", param.Name)
+			return
+		}
+	}
+
+	now := s.now()
+	p := &plugin{env: envID(r), vo: client.ConnectPluginVO{
+		Id:                ptr(s.newID("plugin")),
+		Name:              ptr(param.Name),
+		Description:       param.Description,
+		DocumentationLink: param.DocumentationLink,
+		Types:             param.Types,
+		Provider:          ptr(client.PluginProviderCustom),
+		StorageUrl:        ptr(param.StorageUrl),
+		Version:           ptr(param.Version),
+		ConnectorClass:    ptr(param.ConnectorClass),
+		CreateTime:        &now,
+		UpdateTime:        &now,
+	}}
+	s.transition(&p.lifecycle, client.PluginStatePending, client.PluginStateActive)
+	s.plugins = append(s.plugins, p)
+	writeJSON(w, http.StatusOK, p.view())
+}
+
+func (s *Server) listPlugins(w http.ResponseWriter, r *http.Request) {
+	var list []client.ConnectPluginVO
+	for _, p := range settled(s.plugins, s.now()) {
+		if p.env == envID(r) {
+			list = append(list, p.view())
+		}
+	}
+	writePage(w, r, list)
+}
+
+func (s *Server) getPlugin(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("pluginId")
+	p := s.plugin(envID(r), id)
+	if p == nil {
+		notFound(w, "plugin", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, p.view())
+}
+
+func (s *Server) deletePlugin(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("pluginId")
+	p := s.plugin(envID(r), id)
+	if p == nil {
+		notFound(w, "plugin", id)
+		return
+	}
+	if p.state != client.PluginStateDeleting {
+		s.transition(&p.lifecycle, client.PluginStateDeleting, removed)
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
@@ -0,0 +1,652 @@
+package fakeserver
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"terraform-provider-automq/client"
+)
+
+// Kafka instance states.
+const (
+	instanceCreating = "Creating"
+	instanceRunning  = "Running"
+	instanceChanging = "Changing"
+	instanceDeleting = "Deleting"
+)
+
+var topicNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][.a-zA-Z0-9_-]*[a-zA-Z0-9]$`)
+
+type instance struct {
+	lifecycle
+	env     string
+	vo      client.InstanceVO
+	configs map[string]string
+	topics  []*client.TopicVO
+	users   []*client.KafkaUserVO
+	acls    []client.KafkaAclBindingVO
+	links   []*link
+}
+
+func (in *instance) view() client.InstanceVO {
+	vo := in.vo
+	vo.State = ptr(in.state)
+	return vo
+}
+
+func (s *Server) registerKafkaRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/instances", s.createInstance)
+	mux.HandleFunc("GET /api/v1/instances", s.listInstances)
+	mux.HandleFunc("GET /api/v1/instances/{instanceId}", s.getInstance)
+	mux.HandleFunc("PATCH /api/v1/instances/{instanceId}", s.updateInstance)
+	mux.HandleFunc("DELETE /api/v1/instances/{instanceId}", s.deleteInstance)
+	mux.HandleFunc("GET /api/v1/instances/{instanceId}/endpoints", s.getInstanceEndpoints)
+	mux.HandleFunc("GET /api/v1/instances/{instanceId}/configurations", s.getInstanceConfigs)
+
+	mux.HandleFunc("POST /api/v1/instances/{instanceId}/topics", s.createTopic)
+	mux.HandleFunc("GET /api/v1/instances/{instanceId}/topics", s.listTopics)
+	mux.HandleFunc("GET /api/v1/instances/{instanceId}/topics/{topicId}", s.getTopic)
+	mux.HandleFunc("DELETE /api/v1/instances/{instanceId}/topics/{topicId}", s.deleteTopic)
+	mux.HandleFunc("PATCH /api/v1/instances/{instanceId}/topics/{topicId}/configurations", s.updateTopicConfigs)
+	mux.HandleFunc("PATCH /api/v1/instances/{instanceId}/topics/{topicId}/partition-counts", s.updateTopicPartitions)
+
+	mux.HandleFunc("POST /api/v1/instances/{instanceId}/users", s.createUser)
+	mux.HandleFunc("GET /api/v1/instances/{instanceId}/users", s.listUsers)
+	mux.HandleFunc("DELETE /api/v1/instances/{instanceId}/users/{userName}", s.deleteUser)
+
+	mux.HandleFunc("POST /api/v1/instances/{instanceId}/acls", s.createAcls)
+	mux.HandleFunc("GET /api/v1/instances/{instanceId}/acls", s.listAcls)
+	mux.HandleFunc("POST /api/v1/instances/{instanceId}/acls/batch:delete", s.deleteAcls)
+}
+
+// AddInstance creates a Running instance in environment env, skipping the
+// Creating state, and returns its ID. It lets tests of topics, users and
+// other instance sub-resources start from an existing instance.
+func (s *Server) AddInstance(env string, param client.InstanceCreateParam) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	in, err := s.newInstance(env, param)
+	if err != nil {
+		return "", err
+	}
+	in.state = instanceRunning
+	return *in.vo.InstanceId, nil
+}
+
+func (s *Server) newInstance(env string, param client.InstanceCreateParam) (*instance, error) {
+	raw := map[string]any{}
+	if err := convert(param, &raw); err != nil {
+		return nil, err
+	}
+	in := &instance{env: env, configs: map[string]string{}}
+	applyInstanceConfigs(in.configs, raw)
+	dropDisabledFeatures(raw)
+	if err := convert(raw, &in.vo); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	in.vo.InstanceId = ptr(s.newID("kf"))
+	in.vo.KafkaClusterId = ptr(s.newID("cluster"))
+	in.vo.DeployProfile = ptr("default")
+	in.vo.GmtCreate = &now
+	in.vo.GmtModified = &now
+	if in.vo.Spec != nil {
+		in.vo.Spec.CurrentAku = in.vo.Spec.ReservedAku
+	}
+	s.instances = append(s.instances, in)
+	return in, nil
+}
+
+// instance returns the instance id of environment env, or nil when it does
+// not exist.
+func (s *Server) instance(env, id string) *instance {
+	s.instances = settled(s.instances, s.now())
+	for _, in := range s.instances {
+		if in.env == env && *in.vo.InstanceId == id {
+			return in
+		}
+	}
+	return nil
+}
+
+// instanceIn returns the instance of the request path when it exists and is
+// in one of states. Otherwise it answers the request and returns nil.
+func (s *Server) instanceIn(w http.ResponseWriter, r *http.Request, states ...string) *instance {
+	id := r.PathValue("instanceId")
+	in := s.instance(envID(r), id)
+	if in == nil {
+		notFound(w, "instance", id)
+		return nil
+	}
+	if !slices.Contains(states, in.state) {
+		invalidState(w, "instance", id, in.state)
+		return nil
+	}
+	return in
+}
+
+// availableInstance returns the instance of the request path when its
+// Kafka cluster is up, which is also the case while a change is rolled out.
+func (s *Server) availableInstance(w http.ResponseWriter, r *http.Request) *instance {
+	return s.instanceIn(w, r, instanceRunning, instanceChanging)
+}
+
+func (s *Server) createInstance(w http.ResponseWriter, r *http.Request) {
+	var param client.InstanceCreateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if param.Name == "" || param.Version == "" {
+		invalidParameter(w, "name and version are required")
+		return
+	}
+	if param.Spec.ReservedAku < 1 {
+		invalidParameter(w, "spec.reservedAku must be positive")
+		return
+	}
+	for _, in := range settled(s.instances, s.now()) {
+		if in.env == envID(r) && *in.vo.Name == param.Name {
+			conflict(w, "instance", param.Name)
+			return
+		}
+	}
+	in, err := s.newInstance(envID(r), param)
+	if err != nil {
+		invalidParameter(w, "%s", err)
+		return
+	}
+	s.transition(&in.lifecycle, instanceCreating, instanceRunning)
+
+	summary := client.InstanceSummaryVO{}
+	if err := convert(in.view(), &summary); err != nil {
+		invalidParameter(w, "%s", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (s *Server) listInstances(w http.ResponseWriter, r *http.Request) {
+	keyword := r.URL.Query().Get("keyword")
+	var list []client.InstanceVO
+	for _, in := range settled(s.instances, s.now()) {
+		if in.env == envID(r) && strings.Contains(*in.vo.Name, keyword) {
+			list = append(list, in.view())
+		}
+	}
+	writePage(w, r, list)
+}
+
+func (s *Server) getInstance(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("instanceId")
+	in := s.instance(envID(r), id)
+	if in == nil {
+		notFound(w, "instance", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, in.view())
+}
+
+// updateInstance applies a PATCH the way the control plane does: name and
+// description change at once, anything else puts the instance in the
+// Changing state until the change is rolled out.
+func (s *Server) updateInstance(w http.ResponseWriter, r *http.Request) {
+	in := s.instanceIn(w, r, instanceRunning)
+	if in == nil {
+		return
+	}
+	patch := map[string]any{}
+	if !decode(w, r, &patch) {
+		return
+	}
+	if name, ok := patch["name"].(string); ok && name != *in.vo.Name {
+		for _, other := range s.instances {
+			if other.env == in.env && *other.vo.Name == name {
+				conflict(w, "instance", name)
+				return
+			}
+		}
+	}
+
+	current := map[string]any{}
+	if err := convert(in.vo, &current); err != nil {
+		invalidParameter(w, "%s", err)
+		return
+	}
+	applyInstanceConfigs(in.configs, patch)
+	replaceMetricsExporters(current, patch)
+	mergeJSON(current, patch)
+	dropDisabledFeatures(current)
+	vo := client.InstanceVO{}
+	if err := convert(current, &vo); err != nil {
+		invalidParameter(w, "%s", err)
+		return
+	}
+	now := s.now()
+	vo.GmtModified = &now
+	if vo.Spec != nil {
+		vo.Spec.CurrentAku = vo.Spec.ReservedAku
+	}
+	in.vo = vo
+
+	for key := range patch {
+		if key != "name" && key != "description" {
+			s.transition(&in.lifecycle, instanceChanging, instanceRunning)
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (s *Server) deleteInstance(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("instanceId")
+	in := s.instance(envID(r), id)
+	if in == nil {
+		notFound(w, "instance", id)
+		return
+	}
+	if in.state != instanceDeleting {
+		s.transition(&in.lifecycle, instanceDeleting, removed)
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// getInstanceEndpoints lists one bootstrap endpoint per transit encryption
+// mode of an instance.
+func (s *Server) getInstanceEndpoints(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	sasl := false
+	modes := []string{"plaintext"}
+	if security := in.vo.Features; security != nil && security.Security != nil {
+		sasl = slices.Contains(security.Security.AuthenticationMethods, "sasl")
+		if len(security.Security.TransitEncryptionModes) > 0 {
+			modes = security.Security.TransitEncryptionModes
+		}
+	}
+	var endpoints []client.InstanceAccessInfoVO
+	for i, mode := range modes {
+		protocol := "PLAINTEXT"
+		if mode == "tls" {
+			protocol = "SSL"
+		}
+		mechanisms := ""
+		if sasl {
+			protocol = "SASL_" + protocol
+			mechanisms = "SCRAM-SHA-256,SCRAM-SHA-512,PLAIN"
+		}
+		endpoints = append(endpoints, client.InstanceAccessInfoVO{
+			DisplayName:      ptr(protocol),
+			Name:             ptr(strings.ToLower(protocol)),
+			NetworkType:      ptr("VPC"),
+			Protocol:         ptr(protocol),
+			Mechanisms:       ptr(mechanisms),
+			BootstrapServers: ptr(fmt.Sprintf("%s.automq.fake:%d", *in.vo.InstanceId, 9092+i)),
+		})
+	}
+	writeJSON(w, http.StatusOK, client.PageNumResultInstanceAccessInfoVO{List: endpoints})
+}
+
+func (s *Server) getInstanceConfigs(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("instanceId")
+	in := s.instance(envID(r), id)
+	if in == nil {
+		notFound(w, "instance", id)
+		return
+	}
+	keys := make([]string, 0, len(in.configs))
+	for key := range in.configs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	list := make([]client.ConfigItemParam, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, client.ConfigItemParam{Key: ptr(key), Value: ptr(in.configs[key])})
+	}
+	writePage(w, r, list)
+}
+
+// applyInstanceConfigs stores the features.instanceConfigs of a create or
+// update request, which are not part of the instance view.
+func applyInstanceConfigs(configs map[string]string, raw map[string]any) {
+	features, _ := raw["features"].(map[string]any)
+	items, _ := features["instanceConfigs"].([]any)
+	for _, item := range items {
+		config, _ := item.(map[string]any)
+		key, _ := config["key"].(string)
+		value, _ := config["value"].(string)
+		if key != "" {
+			configs[key] = value
+		}
+	}
+}
+
+// replaceMetricsExporters removes from current the exporters a PATCH sets,
+// since an exporter is always sent as a whole.
+func replaceMetricsExporters(current, patch map[string]any) {
+	patchFeatures, _ := patch["features"].(map[string]any)
+	patchExporters, _ := patchFeatures["metricsExporter"].(map[string]any)
+	features, _ := current["features"].(map[string]any)
+	exporters, _ := features["metricsExporter"].(map[string]any)
+	for name := range patchExporters {
+		delete(exporters, name)
+	}
+}
+
+// dropDisabledFeatures removes the metrics exporters turned off with
+// "enabled": false, which the instance view omits.
+func dropDisabledFeatures(raw map[string]any) {
+	features, _ := raw["features"].(map[string]any)
+	exporters, _ := features["metricsExporter"].(map[string]any)
+	for name, value := range exporters {
+		if exporter, ok := value.(map[string]any); ok && exporter["enabled"] == false {
+			delete(exporters, name)
+		}
+	}
+}
+
+// mergeJSON merges patch into dst. Objects are merged recursively, any
+// other value replaces the previous one.
+func mergeJSON(dst, patch map[string]any) {
+	for key, value := range patch {
+		if from, ok := value.(map[string]any); ok {
+			if to, ok := dst[key].(map[string]any); ok {
+				mergeJSON(to, from)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+func configMap(items []client.ConfigItemParam, into map[string]interface{}) error {
+	for _, item := range items {
+		if item.Key == nil || item.Value == nil {
+			return fmt.Errorf("config items need a key and a value")
+		}
+		into[*item.Key] = *item.Value
+	}
+	return nil
+}
+
+func (s *Server) createTopic(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	var param client.TopicCreateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if !topicNamePattern.MatchString(param.Name) {
+		invalidParameter(w, "invalid topic name %q", param.Name)
+		return
+	}
+	if param.Partition < 1 {
+		invalidParameter(w, "partition must be positive")
+		return
+	}
+	for _, topic := range in.topics {
+		if topic.Name == param.Name {
+			conflict(w, "topic", param.Name)
+			return
+		}
+	}
+	topic := &client.TopicVO{
+		TopicId:   s.newID("topic"),
+		Name:      param.Name,
+		Partition: param.Partition,
+		Configs:   map[string]interface{}{},
+	}
+	if err := configMap(param.Configs, topic.Configs); err != nil {
+		invalidParameter(w, "%s", err)
+		return
+	}
+	in.topics = append(in.topics, topic)
+	writeJSON(w, http.StatusOK, topic)
+}
+
+func (s *Server) listTopics(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	keyword := r.URL.Query().Get("keyword")
+	var list []client.TopicVO
+	for _, topic := range in.topics {
+		if strings.Contains(topic.Name, keyword) {
+			list = append(list, *topic)
+		}
+	}
+	writePage(w, r, list)
+}
+
+// topic returns the topic of the request path, or answers the request and
+// returns nil.
+func (s *Server) topic(w http.ResponseWriter, r *http.Request) (*instance, int) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return nil, -1
+	}
+	id := r.PathValue("topicId")
+	for i, topic := range in.topics {
+		if topic.TopicId == id {
+			return in, i
+		}
+	}
+	notFound(w, "topic", id)
+	return nil, -1
+}
+
+func (s *Server) getTopic(w http.ResponseWriter, r *http.Request) {
+	if in, i := s.topic(w, r); in != nil {
+		writeJSON(w, http.StatusOK, in.topics[i])
+	}
+}
+
+func (s *Server) deleteTopic(w http.ResponseWriter, r *http.Request) {
+	if in, i := s.topic(w, r); in != nil {
+		in.topics = slices.Delete(in.topics, i, i+1)
+		writeJSON(w, http.StatusOK, struct{}{})
+	}
+}
+
+func (s *Server) updateTopicConfigs(w http.ResponseWriter, r *http.Request) {
+	in, i := s.topic(w, r)
+	if in == nil {
+		return
+	}
+	var param client.TopicConfigParam
+	if !decode(w, r, &param) {
+		return
+	}
+	configs := map[string]interface{}{}
+	for key, value := range in.topics[i].Configs {
+		configs[key] = value
+	}
+	if err := configMap(param.Configs, configs); err != nil {
+		invalidParameter(w, "%s", err)
+		return
+	}
+	in.topics[i].Configs = configs
+	writeJSON(w, http.StatusOK, in.topics[i])
+}
+
+func (s *Server) updateTopicPartitions(w http.ResponseWriter, r *http.Request) {
+	in, i := s.topic(w, r)
+	if in == nil {
+		return
+	}
+	var param client.TopicPartitionParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if param.Partition < in.topics[i].Partition {
+		invalidParameter(w, "the partition count of topic %q cannot be decreased", in.topics[i].Name)
+		return
+	}
+	in.topics[i].Partition = param.Partition
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	var param client.InstanceUserCreateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if param.Name == "" || param.Password == "" {
+		invalidParameter(w, "name and password are required")
+		return
+	}
+	for _, user := range in.users {
+		if user.Name == param.Name {
+			conflict(w, "user", param.Name)
+			return
+		}
+	}
+	user := &client.KafkaUserVO{
+		Name:                    param.Name,
+		Password:                param.Password,
+		SupportedSaslMechanisms: []string{"SCRAM-SHA-256", "SCRAM-SHA-512", "PLAIN"},
+	}
+	in.users = append(in.users, user)
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	var names []string
+	if v := r.URL.Query().Get("userNames"); v != "" {
+		names = strings.Split(v, ",")
+	}
+	var list []client.KafkaUserVO
+	for _, user := range in.users {
+		if names == nil || slices.Contains(names, user.Name) {
+			list = append(list, *user)
+		}
+	}
+	writePage(w, r, list)
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	name := r.PathValue("userName")
+	for i, user := range in.users {
+		if user.Name == name {
+			in.users = slices.Delete(in.users, i, i+1)
+			writeJSON(w, http.StatusOK, struct{}{})
+			return
+		}
+	}
+	notFound(w, "user", name)
+}
+
+func aclBinding(param client.KafkaAclBindingParam) client.KafkaAclBindingVO {
+	host := "*"
+	if param.AccessControlParam.Host != nil {
+		host = *param.AccessControlParam.Host
+	}
+	return client.KafkaAclBindingVO{
+		AccessControl: &client.KafkaAccessControlVO{
+			User:           param.AccessControlParam.User,
+			Host:           &host,
+			OperationGroup: client.OperationGroup{Name: param.AccessControlParam.OperationGroup},
+			PermissionType: param.AccessControlParam.PermissionType,
+		},
+		ResourcePattern: &client.KafkaResourcePatternVO{
+			ResourceType: param.ResourcePatternParam.ResourceType,
+			Name:         param.ResourcePatternParam.Name,
+			PatternType:  param.ResourcePatternParam.PatternType,
+		},
+	}
+}
+
+func sameAcl(a, b client.KafkaAclBindingVO) bool {
+	return a.AccessControl.User == b.AccessControl.User &&
+		*a.AccessControl.Host == *b.AccessControl.Host &&
+		a.AccessControl.OperationGroup.Name == b.AccessControl.OperationGroup.Name &&
+		a.AccessControl.PermissionType == b.AccessControl.PermissionType &&
+		*a.ResourcePattern == *b.ResourcePattern
+}
+
+// createAcls adds the bindings that do not exist yet and returns all the
+// requested bindings, like Kafka's CreateAcls.
+func (s *Server) createAcls(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	var params client.KafkaAclBindingParams
+	if !decode(w, r, &params) {
+		return
+	}
+	var created []client.KafkaAclBindingVO
+	for _, param := range params.Params {
+		if param.AccessControlParam.User == "" || param.ResourcePatternParam.ResourceType == "" || param.ResourcePatternParam.Name == "" {
+			invalidParameter(w, "user, resource type and resource name are required")
+			return
+		}
+		binding := aclBinding(param)
+		if !slices.ContainsFunc(in.acls, func(acl client.KafkaAclBindingVO) bool { return sameAcl(acl, binding) }) {
+			in.acls = append(in.acls, binding)
+		}
+		created = append(created, binding)
+	}
+	writePage(w, r, created)
+}
+
+func (s *Server) listAcls(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	query := r.URL.Query()
+	var list []client.KafkaAclBindingVO
+	for _, acl := range in.acls {
+		if user := query.Get("exactUser"); user != "" && acl.AccessControl.User != user {
+			continue
+		}
+		if resourceTypes := query.Get("resourceTypes"); resourceTypes != "" && !slices.Contains(strings.Split(resourceTypes, ","), acl.ResourcePattern.ResourceType) {
+			continue
+		}
+		if permission := query.Get("permissionType"); permission != "" && acl.AccessControl.PermissionType != permission {
+			continue
+		}
+		if !strings.Contains(acl.ResourcePattern.Name, query.Get("fuzzyResourceName")) {
+			continue
+		}
+		list = append(list, acl)
+	}
+	writePage(w, r, list)
+}
+
+func (s *Server) deleteAcls(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	var params client.KafkaAclBindingParams
+	if !decode(w, r, &params) {
+		return
+	}
+	for _, param := range params.Params {
+		binding := aclBinding(param)
+		in.acls = slices.DeleteFunc(in.acls, func(acl client.KafkaAclBindingVO) bool { return sameAcl(acl, binding) })
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
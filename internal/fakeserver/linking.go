@@ -0,0 +1,286 @@
+package fakeserver
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"terraform-provider-automq/client"
+)
+
+// Kafka link and mirror states.
+const (
+	linkRunning    = "RUNNING"
+	mirrorLinking  = "LINKING"
+	mirrorPaused   = "PAUSED"
+	mirrorPromoted = "PROMOTED"
+)
+
+type link struct {
+	vo           client.KafkaLinkVO
+	mirrorTopics []*client.MirrorTopicVO
+	mirrorGroups []*client.MirrorConsumerGroupVO
+}
+
+func (s *Server) registerLinkingRoutes(mux *http.ServeMux) {
+	const links = "/api/v1/instances/{instanceId}/kafka-links"
+	mux.HandleFunc("POST "+links, s.createLink)
+	mux.HandleFunc("GET "+links, s.listLinks)
+	mux.HandleFunc("GET "+links+"/{linkId}", s.getLink)
+	mux.HandleFunc("DELETE "+links+"/{linkId}", s.deleteLink)
+
+	mux.HandleFunc("POST "+links+"/{linkId}/kafka-link-mirror-topics", s.createMirrorTopics)
+	mux.HandleFunc("GET "+links+"/{linkId}/kafka-link-mirror-topics", s.listMirrorTopics)
+	mux.HandleFunc("PATCH "+links+"/{linkId}/kafka-link-mirror-topics/{topicId}", s.updateMirrorTopic)
+	mux.HandleFunc("DELETE "+links+"/{linkId}/kafka-link-mirror-topics/{topicId}", s.deleteMirrorTopic)
+
+	mux.HandleFunc("POST "+links+"/{linkId}/kafka-link-mirror-groups", s.createMirrorGroups)
+	mux.HandleFunc("GET "+links+"/{linkId}/kafka-link-mirror-groups", s.listMirrorGroups)
+	mux.HandleFunc("DELETE "+links+"/{linkId}/kafka-link-mirror-groups/{groupId}", s.deleteMirrorGroup)
+}
+
+func (s *Server) createLink(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	var param client.KafkaLinkCreateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if param.LinkID == "" || param.SourceCluster.Endpoint == "" {
+		invalidParameter(w, "linkId and sourceCluster.endpoint are required")
+		return
+	}
+	for _, l := range in.links {
+		if l.vo.LinkID == param.LinkID {
+			conflict(w, "kafka link", param.LinkID)
+			return
+		}
+	}
+	now := s.now()
+	l := &link{vo: client.KafkaLinkVO{
+		LinkID:          param.LinkID,
+		InstanceID:      *in.vo.InstanceId,
+		StartOffsetTime: param.StartOffsetTime,
+		SourceCluster: &client.KafkaLinkSourceClusterVO{
+			Endpoint:         param.SourceCluster.Endpoint,
+			SecurityProtocol: param.SourceCluster.SecurityProtocol,
+			SaslMechanism:    param.SourceCluster.SaslMechanism,
+			User:             param.SourceCluster.User,
+		},
+		SourceSecurityProtocol: param.SourceCluster.SecurityProtocol,
+		SourceSaslMechanism:    param.SourceCluster.SaslMechanism,
+		SourceUser:             param.SourceCluster.User,
+		GmtCreate:              &now,
+		GmtModified:            &now,
+		Status:                 ptr(linkRunning),
+	}}
+	in.links = append(in.links, l)
+	writeJSON(w, http.StatusOK, l.vo)
+}
+
+func (s *Server) listLinks(w http.ResponseWriter, r *http.Request) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return
+	}
+	var list []client.KafkaLinkVO
+	for _, l := range in.links {
+		list = append(list, l.vo)
+	}
+	writePage(w, r, list)
+}
+
+// link returns the link of the request path, or answers the request and
+// returns nil.
+func (s *Server) link(w http.ResponseWriter, r *http.Request) (*instance, int) {
+	in := s.availableInstance(w, r)
+	if in == nil {
+		return nil, -1
+	}
+	id := r.PathValue("linkId")
+	for i, l := range in.links {
+		if l.vo.LinkID == id {
+			return in, i
+		}
+	}
+	notFound(w, "kafka link", id)
+	return nil, -1
+}
+
+func (s *Server) getLink(w http.ResponseWriter, r *http.Request) {
+	if in, i := s.link(w, r); in != nil {
+		writeJSON(w, http.StatusOK, in.links[i].vo)
+	}
+}
+
+func (s *Server) deleteLink(w http.ResponseWriter, r *http.Request) {
+	if in, i := s.link(w, r); in != nil {
+		in.links = slices.Delete(in.links, i, i+1)
+		writeJSON(w, http.StatusOK, struct{}{})
+	}
+}
+
+func (s *Server) createMirrorTopics(w http.ResponseWriter, r *http.Request) {
+	in, i := s.link(w, r)
+	if in == nil {
+		return
+	}
+	l := in.links[i]
+	var param client.KafkaLinkMirrorTopicsCreateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	var created client.MirrorTopicListVO
+	for _, source := range param.SourceTopics {
+		if !topicNamePattern.MatchString(source.TopicName) {
+			invalidParameter(w, "invalid topic name %q", source.TopicName)
+			return
+		}
+		if slices.ContainsFunc(l.mirrorTopics, func(t *client.MirrorTopicVO) bool { return t.SourceTopicName == source.TopicName }) {
+			conflict(w, "mirror topic", source.TopicName)
+			return
+		}
+	}
+	for _, source := range param.SourceTopics {
+		topic := &client.MirrorTopicVO{
+			SourceTopicName: source.TopicName,
+			MirrorTopicName: ptr(source.TopicName),
+			MirrorTopicID:   ptr(s.newID("mirror-topic")),
+			State:           &client.KafkaLinkingStateVO{State: ptr(mirrorLinking)},
+		}
+		l.mirrorTopics = append(l.mirrorTopics, topic)
+		created.Topics = append(created.Topics, *topic)
+	}
+	writeJSON(w, http.StatusOK, created)
+}
+
+func (s *Server) listMirrorTopics(w http.ResponseWriter, r *http.Request) {
+	in, i := s.link(w, r)
+	if in == nil {
+		return
+	}
+	keyword := r.URL.Query().Get("keyword")
+	var list []client.MirrorTopicVO
+	for _, topic := range in.links[i].mirrorTopics {
+		if strings.Contains(topic.SourceTopicName, keyword) {
+			list = append(list, *topic)
+		}
+	}
+	writePage(w, r, list)
+}
+
+// mirrorTopic returns the mirror topic of the request path, or answers the
+// request and returns a nil link.
+func (s *Server) mirrorTopic(w http.ResponseWriter, r *http.Request) (*link, int) {
+	in, i := s.link(w, r)
+	if in == nil {
+		return nil, -1
+	}
+	l := in.links[i]
+	id := r.PathValue("topicId")
+	for j, topic := range l.mirrorTopics {
+		if *topic.MirrorTopicID == id {
+			return l, j
+		}
+	}
+	notFound(w, "mirror topic", id)
+	return nil, -1
+}
+
+// updateMirrorTopic pauses, resumes or promotes a mirror topic. A promoted
+// topic is detached from the link and cannot change state anymore.
+func (s *Server) updateMirrorTopic(w http.ResponseWriter, r *http.Request) {
+	l, j := s.mirrorTopic(w, r)
+	if l == nil {
+		return
+	}
+	var param client.KafkaLinkMirrorTopicsUpdateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	if !slices.Contains([]string{mirrorLinking, mirrorPaused, mirrorPromoted}, param.State) {
+		invalidParameter(w, "invalid mirror topic state %q", param.State)
+		return
+	}
+	topic := l.mirrorTopics[j]
+	if current := *topic.State.State; current == mirrorPromoted && param.State != mirrorPromoted {
+		invalidState(w, "mirror topic", *topic.MirrorTopicID, current)
+		return
+	}
+	topic.State = &client.KafkaLinkingStateVO{State: ptr(param.State)}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (s *Server) deleteMirrorTopic(w http.ResponseWriter, r *http.Request) {
+	if l, j := s.mirrorTopic(w, r); l != nil {
+		l.mirrorTopics = slices.Delete(l.mirrorTopics, j, j+1)
+		writeJSON(w, http.StatusOK, struct{}{})
+	}
+}
+
+func (s *Server) createMirrorGroups(w http.ResponseWriter, r *http.Request) {
+	in, i := s.link(w, r)
+	if in == nil {
+		return
+	}
+	l := in.links[i]
+	var param client.KafkaLinkMirrorGroupsCreateParam
+	if !decode(w, r, &param) {
+		return
+	}
+	var created client.MirrorConsumerGroupListVO
+	for _, source := range param.SourceGroups {
+		if source.ConsumerGroup == "" {
+			invalidParameter(w, "consumerGroup is required")
+			return
+		}
+		if slices.ContainsFunc(l.mirrorGroups, func(g *client.MirrorConsumerGroupVO) bool { return g.SourceGroupID == source.ConsumerGroup }) {
+			conflict(w, "mirror group", source.ConsumerGroup)
+			return
+		}
+	}
+	for _, source := range param.SourceGroups {
+		group := &client.MirrorConsumerGroupVO{
+			LinkID:        ptr(l.vo.LinkID),
+			SourceGroupID: source.ConsumerGroup,
+			MirrorGroupID: ptr(s.newID("mirror-group")),
+			State:         &client.KafkaLinkingStateVO{State: ptr(mirrorLinking)},
+		}
+		l.mirrorGroups = append(l.mirrorGroups, group)
+		created.Groups = append(created.Groups, *group)
+	}
+	writeJSON(w, http.StatusOK, created)
+}
+
+func (s *Server) listMirrorGroups(w http.ResponseWriter, r *http.Request) {
+	in, i := s.link(w, r)
+	if in == nil {
+		return
+	}
+	keyword := r.URL.Query().Get("keyword")
+	var list []client.MirrorConsumerGroupVO
+	for _, group := range in.links[i].mirrorGroups {
+		if strings.Contains(group.SourceGroupID, keyword) {
+			list = append(list, *group)
+		}
+	}
+	writePage(w, r, list)
+}
+
+func (s *Server) deleteMirrorGroup(w http.ResponseWriter, r *http.Request) {
+	in, i := s.link(w, r)
+	if in == nil {
+		return
+	}
+	l := in.links[i]
+	id := r.PathValue("groupId")
+	for j, group := range l.mirrorGroups {
+		if *group.MirrorGroupID == id {
+			l.mirrorGroups = slices.Delete(l.mirrorGroups, j, j+1)
+			writeJSON(w, http.StatusOK, struct{}{})
+			return
+		}
+	}
+	notFound(w, "mirror group", id)
+}
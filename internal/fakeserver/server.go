@@ -0,0 +1,287 @@
+// Package fakeserver implements an in-memory AutoMQ BYOC control plane for
+// hermetic tests. It serves the instance, topic, user, ACL, Kafka link,
+// mirror, Connect cluster, connector and plugin endpoints called by the
+// client package, verifies AUTOMQ-HMAC-SHA256 request signatures and moves
+// resources through the same asynchronous states as the real control plane.
+package fakeserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// AccessKeyID and SecretAccessKey are the Service Account credentials
+	// accepted by every Server.
+	AccessKeyID     = "fake-access-key-id"
+	SecretAccessKey = "fake-secret-access-key"
+	// EnvironmentID is the environment Server.AddInstance defaults to. Any
+	// other environment ID is accepted too; resources are only visible in
+	// the environment they were created in.
+	EnvironmentID = "env-fake"
+
+	// DefaultTransitionDelay is how long asynchronous operations take when
+	// WithTransitionDelay is not given.
+	DefaultTransitionDelay = 200 * time.Millisecond
+
+	defaultPageSize = 10
+)
+
+// Server is a fake control plane listening on a local httptest server. Its
+// URL is the value of the provider's automq_byoc_endpoint.
+type Server struct {
+	*httptest.Server
+
+	transitionDelay time.Duration
+	clockSkew       time.Duration
+	credentials     map[string]string
+	requestCount    atomic.Int64
+
+	// mu guards everything below. Requests are served one at a time.
+	mu              sync.Mutex
+	lastID          int
+	instances       []*instance
+	connectClusters []*connectCluster
+	connectors      []*connector
+	plugins         []*plugin
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithTransitionDelay sets how long asynchronous operations (instance
+// creation, updates and deletion, Connect clusters, connectors and plugins)
+// stay in their transitional state.
+func WithTransitionDelay(d time.Duration) Option {
+	return func(s *Server) {
+		s.transitionDelay = d
+	}
+}
+
+// WithCredentials accepts requests signed with another key pair in addition
+// to AccessKeyID and SecretAccessKey.
+func WithCredentials(accessKeyID, secretAccessKey string) Option {
+	return func(s *Server) {
+		s.credentials[accessKeyID] = secretAccessKey
+	}
+}
+
+// WithClockSkew runs the server clock d ahead of the local clock (behind
+// when negative). It shifts both the Date header of responses and the
+// window signed requests are accepted in.
+func WithClockSkew(d time.Duration) Option {
+	return func(s *Server) {
+		s.clockSkew = d
+	}
+}
+
+// New starts a Server. Callers must Close it when done.
+func New(options ...Option) *Server {
+	s := &Server{
+		transitionDelay: DefaultTransitionDelay,
+		credentials:     map[string]string{AccessKeyID: SecretAccessKey},
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	mux := http.NewServeMux()
+	s.registerKafkaRoutes(mux)
+	s.registerLinkingRoutes(mux)
+	s.registerConnectRoutes(mux)
+
+	s.Server = httptest.NewServer(s.handler(mux))
+	return s
+}
+
+func (s *Server) now() time.Time {
+	return time.Now().Add(s.clockSkew)
+}
+
+// handler authenticates every request before passing it to next.
+func (s *Server) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Automq-Request-Id", fmt.Sprintf("fake-%d", s.requestCount.Add(1)))
+		w.Header().Set("Date", s.now().UTC().Format(http.TimeFormat))
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", "unable to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if status, code, message := s.verifySignature(r, body); status != 0 {
+			writeError(w, status, code, message)
+			return
+		}
+		if envID(r) == "" {
+			writeError(w, http.StatusBadRequest, "MissingParameter", "the X-Automq-Environment-Id header is required")
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newID returns a new identifier with the given prefix. IDs are unique
+// across all resource types of a Server.
+func (s *Server) newID(prefix string) string {
+	s.lastID++
+	return fmt.Sprintf("%s-%08d", prefix, s.lastID)
+}
+
+func envID(r *http.Request) string {
+	return r.Header.Get("X-Automq-Environment-Id")
+}
+
+// lifecycle tracks the state of a resource with asynchronous operations.
+// A pending transition completes lazily, the first time the resource is
+// looked up after its deadline.
+type lifecycle struct {
+	state   string
+	next    string
+	pending bool
+	at      time.Time
+}
+
+// removed is the state a lifecycle moves to when its deletion completes.
+const removed = ""
+
+func (s *Server) transition(l *lifecycle, state, next string) {
+	l.state = state
+	l.next = next
+	l.pending = true
+	l.at = s.now().Add(s.transitionDelay)
+}
+
+func (l *lifecycle) lc() *lifecycle {
+	return l
+}
+
+func (l *lifecycle) settle(now time.Time) {
+	if l.pending && !now.Before(l.at) {
+		l.state = l.next
+		l.pending = false
+	}
+}
+
+// gone reports whether the deletion of the resource has completed.
+func (l *lifecycle) gone() bool {
+	return !l.pending && l.state == removed
+}
+
+// settled completes the due transitions of items and drops the ones that
+// are gone.
+func settled[T interface{ lc() *lifecycle }](items []T, now time.Time) []T {
+	kept := items[:0]
+	for _, item := range items {
+		item.lc().settle(now)
+		if !item.lc().gone() {
+			kept = append(kept, item)
+		}
+	}
+	clear(items[len(kept):])
+	return kept
+}
+
+type errorBody struct {
+	Error errorModel `json:"error"`
+}
+
+type errorModel struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorBody{Error: errorModel{Code: code, Message: message}})
+}
+
+func notFound(w http.ResponseWriter, kind, id string) {
+	writeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("%s %q not found", kind, id))
+}
+
+func conflict(w http.ResponseWriter, kind, name string) {
+	writeError(w, http.StatusConflict, "ResourceAlreadyExists", fmt.Sprintf("%s %q already exists", kind, name))
+}
+
+func invalidState(w http.ResponseWriter, kind, id, state string) {
+	writeError(w, http.StatusConflict, "InvalidState", fmt.Sprintf("%s %q is in state %q", kind, id, state))
+}
+
+func invalidParameter(w http.ResponseWriter, format string, args ...any) {
+	writeError(w, http.StatusBadRequest, "InvalidParameter", fmt.Sprintf(format, args...))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// decode reads the JSON request body into v, answering 400 when it is not
+// valid.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		invalidParameter(w, "malformed request body: %s", err)
+		return false
+	}
+	return true
+}
+
+type page[T any] struct {
+	PageNum   int `json:"pageNum"`
+	PageSize  int `json:"pageSize"`
+	Total     int `json:"total"`
+	TotalPage int `json:"totalPage"`
+	List      []T `json:"list"`
+}
+
+// writePage answers with the page of items selected by the page and size
+// query parameters.
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	num := queryInt(r, "page", 1)
+	size := queryInt(r, "size", defaultPageSize)
+	start := min((num-1)*size, len(items))
+	end := min(start+size, len(items))
+	writeJSON(w, http.StatusOK, page[T]{
+		PageNum:   num,
+		PageSize:  size,
+		Total:     len(items),
+		TotalPage: (len(items) + size - 1) / size,
+		List:      append([]T{}, items[start:end]...),
+	})
+}
+
+func queryInt(r *http.Request, key string, fallback int) int {
+	v, err := strconv.Atoi(r.URL.Query().Get(key))
+	if err != nil || v < 1 {
+		return fallback
+	}
+	return v
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// convert copies the fields of from into to through their JSON encoding,
+// the way the control plane turns request parameters into resource views.
+func convert(from, to any) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
@@ -0,0 +1,317 @@
+package fakeserver
+
+import (
+	"context"
+	"errors"
+	"terraform-provider-automq/client"
+	"testing"
+	"time"
+)
+
+const testDelay = 20 * time.Millisecond
+
+func newTestClient(t *testing.T, srv *Server, secret string) (context.Context, *client.Client) {
+	t.Helper()
+	ctx := context.WithValue(context.Background(), client.EnvIdKey, EnvironmentID)
+	c, err := client.NewClient(ctx, srv.URL, client.AuthCredentials{AccessKeyID: AccessKeyID, SecretAccessKey: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx, c
+}
+
+func newTestServer(t *testing.T, options ...Option) (context.Context, *client.Client) {
+	t.Helper()
+	srv := New(append([]Option{WithTransitionDelay(testDelay)}, options...)...)
+	t.Cleanup(srv.Close)
+	return newTestClient(t, srv, SecretAccessKey)
+}
+
+func newTestInstance(t *testing.T, ctx context.Context, c *client.Client) string {
+	t.Helper()
+	created, err := c.CreateKafkaInstance(ctx, client.InstanceCreateParam{
+		Name:    "test",
+		Version: "5.2.0",
+		Spec:    client.SpecificationParam{ReservedAku: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitInstanceState(t, ctx, c, *created.InstanceId, "Running")
+	return *created.InstanceId
+}
+
+func waitInstanceState(t *testing.T, ctx context.Context, c *client.Client, id, state string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		in, err := c.GetKafkaInstance(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *in.State == state {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("instance state = %s, want %s", *in.State, state)
+		}
+		time.Sleep(testDelay / 4)
+	}
+}
+
+func TestSignatureMismatch(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+	ctx, c := newTestClient(t, srv, "wrong-secret")
+
+	_, err := c.ListKafkaInstances(ctx, nil)
+	var apiErr *client.ErrorResponse
+	if !errors.As(err, &apiErr) || apiErr.Code != 403 {
+		t.Fatalf("err = %v, want a 403 ErrorResponse", err)
+	}
+	if apiErr.RequestID == "" {
+		t.Error("RequestID is empty")
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	ctx, c := newTestServer(t, WithClockSkew(time.Hour))
+
+	// The client corrects its clock from the Date header and signs again.
+	if _, err := c.ListKafkaInstances(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstanceLifecycle(t *testing.T) {
+	ctx, c := newTestServer(t)
+
+	created, err := c.CreateKafkaInstance(ctx, client.InstanceCreateParam{
+		Name:    "lifecycle",
+		Version: "5.2.0",
+		Spec:    client.SpecificationParam{ReservedAku: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := *created.InstanceId
+	in, err := c.GetKafkaInstance(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *in.State != "Creating" {
+		t.Fatalf("state after create = %s, want Creating", *in.State)
+	}
+	err = c.UpdateKafkaInstance(ctx, id, client.InstanceUpdateParam{Version: ptr("5.3.0")})
+	if !errors.Is(err, client.ErrStateConflict) {
+		t.Fatalf("update while creating: err = %v, want ErrStateConflict", err)
+	}
+	waitInstanceState(t, ctx, c, id, "Running")
+
+	_, err = c.CreateKafkaInstance(ctx, client.InstanceCreateParam{
+		Name:    "lifecycle",
+		Version: "5.2.0",
+		Spec:    client.SpecificationParam{ReservedAku: 3},
+	})
+	if !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("duplicate create: err = %v, want ErrConflict", err)
+	}
+
+	if err := c.UpdateKafkaInstance(ctx, id, client.InstanceUpdateParam{Version: ptr("5.3.0")}); err != nil {
+		t.Fatal(err)
+	}
+	waitInstanceState(t, ctx, c, id, "Changing")
+	waitInstanceState(t, ctx, c, id, "Running")
+	if in, _ = c.GetKafkaInstance(ctx, id); *in.Version != "5.3.0" {
+		t.Errorf("version = %s, want 5.3.0", *in.Version)
+	}
+
+	if err := c.DeleteKafkaInstance(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	waitInstanceState(t, ctx, c, id, "Deleting")
+	time.Sleep(2 * testDelay)
+	if _, err := c.GetKafkaInstance(ctx, id); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("get after delete: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestEnvironmentIsolation(t *testing.T) {
+	ctx, c := newTestServer(t)
+	id := newTestInstance(t, ctx, c)
+
+	other := context.WithValue(ctx, client.EnvIdKey, "env-other")
+	if _, err := c.GetKafkaInstance(other, id); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTopicsUsersAndAcls(t *testing.T) {
+	ctx, c := newTestServer(t)
+	id := newTestInstance(t, ctx, c)
+
+	topic, err := c.CreateKafkaTopic(ctx, id, client.TopicCreateParam{Name: "orders", Partition: 3, CompactStrategy: "DELETE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateKafkaTopic(ctx, id, client.TopicCreateParam{Name: "orders", Partition: 3}); !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("duplicate topic: err = %v, want ErrConflict", err)
+	}
+	if err := c.UpdateKafkaTopicPartition(ctx, id, topic.TopicId, client.TopicPartitionParam{Partition: 1}); !errors.Is(err, client.ErrInvalidParameter) {
+		t.Fatalf("decrease partitions: err = %v, want ErrInvalidParameter", err)
+	}
+	if err := c.UpdateKafkaTopicPartition(ctx, id, topic.TopicId, client.TopicPartitionParam{Partition: 6}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.GetKafkaTopic(ctx, id, topic.TopicId); got.Partition != 6 {
+		t.Errorf("partitions = %d, want 6", got.Partition)
+	}
+
+	if _, err := c.CreateKafkaUser(ctx, id, client.InstanceUserCreateParam{Name: "alice", Password: "secret123"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetKafkaUser(ctx, id, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	acl := client.KafkaAclBindingParams{Params: []client.KafkaAclBindingParam{{
+		AccessControlParam:   client.KafkaControlParam{User: "alice", OperationGroup: "ALL", PermissionType: "ALLOW"},
+		ResourcePatternParam: client.KafkaResourcePatternParam{ResourceType: "TOPIC", Name: "orders", PatternType: "LITERAL"},
+	}}}
+	if _, err := c.CreateKafkaAcls(ctx, id, acl); err != nil {
+		t.Fatal(err)
+	}
+	acls, err := c.ListKafkaAcls(ctx, id, map[string]string{"exactUser": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acls) != 1 {
+		t.Fatalf("got %d ACLs, want 1", len(acls))
+	}
+	if err := c.DeleteKafkaAcls(ctx, id, acl); err != nil {
+		t.Fatal(err)
+	}
+	if acls, _ = c.ListKafkaAcls(ctx, id, map[string]string{"exactUser": "alice"}); len(acls) != 0 {
+		t.Fatalf("got %d ACLs after delete, want 0", len(acls))
+	}
+
+	if err := c.DeleteKafkaUser(ctx, id, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetKafkaUser(ctx, id, "alice"); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("get deleted user: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestKafkaLinkMirrors(t *testing.T) {
+	ctx, c := newTestServer(t)
+	id := newTestInstance(t, ctx, c)
+
+	_, err := c.CreateKafkaLink(ctx, id, client.KafkaLinkCreateParam{
+		LinkID:        "link-1",
+		SourceCluster: client.KafkaLinkSourceClusterParam{Endpoint: "source:9092"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics, err := c.CreateKafkaLinkMirrorTopics(ctx, id, "link-1", client.KafkaLinkMirrorTopicsCreateParam{
+		SourceTopics: []client.KafkaLinkMirrorTopicParam{{TopicName: "orders"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicID := *topics.Topics[0].MirrorTopicID
+	promote := client.KafkaLinkMirrorTopicsUpdateParam{State: mirrorPromoted}
+	if err := c.UpdateKafkaLinkMirrorTopic(ctx, id, "link-1", topicID, promote); err != nil {
+		t.Fatal(err)
+	}
+	pause := client.KafkaLinkMirrorTopicsUpdateParam{State: mirrorPaused}
+	if err := c.UpdateKafkaLinkMirrorTopic(ctx, id, "link-1", topicID, pause); !errors.Is(err, client.ErrStateConflict) {
+		t.Fatalf("pause promoted topic: err = %v, want ErrStateConflict", err)
+	}
+
+	if err := c.DeleteKafkaLink(ctx, id, "link-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetKafkaLink(ctx, id, "link-1"); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("get deleted link: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	ctx, c := newTestServer(t)
+	id := newTestInstance(t, ctx, c)
+
+	p, err := c.CreateConnectPlugin(ctx, client.ConnectPluginCreateParam{
+		Name:           "s3-sink",
+		Version:        "1.0.0",
+		StorageUrl:     "s3://plugins/s3-sink.zip",
+		Types:          []string{"SINK"},
+		ConnectorClass: "io.example.S3SinkConnector",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p.Status != client.PluginStatePending {
+		t.Fatalf("plugin status = %s, want PENDING", *p.Status)
+	}
+
+	cluster, err := c.CreateConnectCluster(ctx, client.ConnectClusterCreateParam{
+		Name:         "connect",
+		KafkaCluster: client.ConnectClusterKafkaParam{KafkaInstanceId: id},
+		Capacity: client.ConnectClusterCapacityParam{
+			Type:        "PROVISIONED",
+			Provisioned: &client.ConnectClusterProvisionedParam{WorkerResourceSpec: "TIER1", WorkerCount: 2},
+		},
+		Compute: client.ConnectClusterComputeParam{Type: "KUBERNETES"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	connector := client.ConnectorCreateParam{
+		ConnectClusterId: *cluster.Id,
+		Name:             "sink",
+		ConnectorClass:   "io.example.S3SinkConnector",
+		TaskCount:        1,
+	}
+	if _, err := c.CreateConnector(ctx, connector); !errors.Is(err, client.ErrStateConflict) {
+		t.Fatalf("create connector on creating cluster: err = %v, want ErrStateConflict", err)
+	}
+	time.Sleep(2 * testDelay)
+
+	conn, err := c.CreateConnector(ctx, connector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *conn.PluginId != *p.Id || *conn.ConnectorType != "SINK" {
+		t.Errorf("connector plugin = %s/%s, want %s/SINK", *conn.PluginId, *conn.ConnectorType, *p.Id)
+	}
+	time.Sleep(2 * testDelay)
+	if conn, err = c.PauseConnector(ctx, *conn.Id); err != nil {
+		t.Fatal(err)
+	}
+	if *conn.State != client.ConnectorStatePaused {
+		t.Fatalf("state after pause = %s, want PAUSED", *conn.State)
+	}
+	if conn, err = c.ResumeConnector(ctx, *conn.Id); err != nil {
+		t.Fatal(err)
+	}
+	if *conn.State != client.ConnectorStateRunning {
+		t.Fatalf("state after resume = %s, want RUNNING", *conn.State)
+	}
+
+	if err := c.DeleteConnectCluster(ctx, *cluster.Id); err == nil {
+		t.Fatal("deleted a Connect cluster that still has connectors")
+	}
+	if err := c.DeleteConnector(ctx, *conn.Id); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * testDelay)
+	if err := c.DeleteConnectCluster(ctx, *cluster.Id); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * testDelay)
+	if _, err := c.GetConnectCluster(ctx, *cluster.Id); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("get deleted cluster: err = %v, want ErrNotFound", err)
+	}
+}
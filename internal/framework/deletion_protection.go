@@ -0,0 +1,453 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// DeletionProtectionAttribute returns the deletion_protection attribute of a
+// resource. The AutoMQ API has no protection flag, so it is only enforced by
+// the provider, through CheckDeletionProtection and DeletionProtectionError.
+func DeletionProtectionAttribute(resourceName string) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Optional: true,
+		Computed: true,
+		Default:  booldefault.StaticBool(false),
+		MarkdownDescription: fmt.Sprintf("Whether Terraform is prevented from destroying or replacing the %s. Defaults to `false`. "+
+			"It must be set to `false`, and applied, before the %s can be destroyed. "+
+			"The protection is enforced by the provider only: the %s can still be deleted from the AutoMQ console or API.", resourceName, resourceName, resourceName),
+	}
+}
+
+// CheckDeletionProtection is called from ModifyPlan. It fails plans that
+// destroy or replace a resource whose deletion_protection is enabled in
+// state. Turning deletion_protection off in the same plan does not help, the
+// change has to be applied first.
+func CheckDeletionProtection(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var protected types.Bool
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("deletion_protection"), &protected)...)
+	if resp.Diagnostics.HasError() || !protected.ValueBool() {
+		return
+	}
+	if req.Plan.Raw.IsNull() {
+		resp.Diagnostics.AddError(
+			"Deletion Protection Enabled",
+			"The resource cannot be destroyed while deletion_protection is true. Set deletion_protection to false and apply the change first.",
+		)
+		return
+	}
+	replaced, diags := requiresReplace(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if len(replaced) > 0 {
+		resp.Diagnostics.AddAttributeError(
+			replaced[0],
+			"Deletion Protection Enabled",
+			fmt.Sprintf("Changing %s replaces the resource, which cannot be destroyed while deletion_protection is true. Set deletion_protection to false and apply the change first.", replaced[0]),
+		)
+	}
+}
+
+// DeletionProtectionError returns an error diagnostic when deletion_protection
+// is enabled, for Delete to refuse deleting the resource. ModifyPlan already
+// fails such plans, this guards against plans made before it was enabled.
+func DeletionProtectionError(protected types.Bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if protected.ValueBool() {
+		diags.AddError(
+			"Deletion Protection Enabled",
+			"The resource cannot be deleted while deletion_protection is true. Set deletion_protection to false and apply the change first.",
+		)
+	}
+	return diags
+}
+
+// requiresReplace returns the paths of the attributes whose plan modifiers
+// require replacing the resource. The framework does not hand the
+// replacements it collected to ModifyPlan, so the plan modifiers of the
+// schema are run again on the modified plan. A schema element the walker does
+// not know is an error, so that a replacement cannot go unnoticed.
+func requiresReplace(ctx context.Context, req resource.ModifyPlanRequest) (path.Paths, diag.Diagnostics) {
+	s, ok := req.Plan.Schema.(schema.Schema)
+	if !ok {
+		var diags diag.Diagnostics
+		diags.AddError("Deletion Protection Error", fmt.Sprintf("Unable to check the replacements of a %T schema. This is always an error in the provider.", req.Plan.Schema))
+		return nil, diags
+	}
+	w := replaceWalker{req: req}
+	w.attributes(ctx, path.Empty(), s.Attributes)
+	w.blocks(ctx, path.Empty(), s.Blocks)
+	return w.paths, w.diags
+}
+
+type replaceWalker struct {
+	req   resource.ModifyPlanRequest
+	paths path.Paths
+	diags diag.Diagnostics
+}
+
+// values reads the config, plan and state values at p into the given
+// pointers, which must all be of the same attribute value type.
+func (w *replaceWalker) values(ctx context.Context, p path.Path, config, plan, state interface{}) bool {
+	w.diags.Append(w.req.Config.GetAttribute(ctx, p, config)...)
+	w.diags.Append(w.req.Plan.GetAttribute(ctx, p, plan)...)
+	w.diags.Append(w.req.State.GetAttribute(ctx, p, state)...)
+	return !w.diags.HasError()
+}
+
+// planned returns the planned value at p, whatever its type, for nested
+// attributes to be walked.
+func (w *replaceWalker) planned(ctx context.Context, p path.Path) attr.Value {
+	var value attr.Value
+	w.diags.Append(w.req.Plan.GetAttribute(ctx, p, &value)...)
+	return value
+}
+
+// elements returns the paths of the planned elements of the list, set or map
+// at p. Elements of sets and maps are looked up in config and state by value
+// and key, the same as the framework does.
+func (w *replaceWalker) elements(ctx context.Context, p path.Path) path.Paths {
+	var paths path.Paths
+	switch value := w.planned(ctx, p).(type) {
+	case basetypes.ListValuable:
+		list, diags := value.ToListValue(ctx)
+		w.diags.Append(diags...)
+		for i := range list.Elements() {
+			paths.Append(p.AtListIndex(i))
+		}
+	case basetypes.SetValuable:
+		set, diags := value.ToSetValue(ctx)
+		w.diags.Append(diags...)
+		for _, element := range set.Elements() {
+			paths.Append(p.AtSetValue(element))
+		}
+	case basetypes.MapValuable:
+		m, diags := value.ToMapValue(ctx)
+		w.diags.Append(diags...)
+		keys := make([]string, 0, len(m.Elements()))
+		for key := range m.Elements() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			paths.Append(p.AtMapKey(key))
+		}
+	}
+	return paths
+}
+
+// object walks the nested object at p when it is planned, running its
+// object-level plan modifiers first.
+func (w *replaceWalker) object(ctx context.Context, p path.Path, modifiers []planmodifier.Object, attributes map[string]schema.Attribute, blocks map[string]schema.Block) {
+	if object := w.planned(ctx, p); object == nil || object.IsNull() || object.IsUnknown() {
+		return
+	}
+	w.objectModifiers(ctx, p, modifiers)
+	w.attributes(ctx, p, attributes)
+	w.blocks(ctx, p, blocks)
+}
+
+func (w *replaceWalker) result(p path.Path, replace bool, diags diag.Diagnostics) {
+	w.diags.Append(diags...)
+	if replace {
+		w.paths.Append(p)
+	}
+}
+
+func (w *replaceWalker) unsupported(p path.Path, element interface{}) {
+	w.diags.AddAttributeError(p, "Deletion Protection Error",
+		fmt.Sprintf("Unable to check whether changing %s replaces the resource: %T is not supported. This is always an error in the provider.", p, element))
+}
+
+func sortedNames[T any](elements map[string]T) []string {
+	names := make([]string, 0, len(elements))
+	for name := range elements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (w *replaceWalker) attributes(ctx context.Context, parent path.Path, attributes map[string]schema.Attribute) {
+	for _, name := range sortedNames(attributes) {
+		if w.diags.HasError() {
+			return
+		}
+		p := parent.AtName(name)
+		switch a := attributes[name].(type) {
+		case schema.StringAttribute:
+			w.stringModifiers(ctx, p, a.PlanModifiers)
+		case schema.BoolAttribute:
+			w.boolModifiers(ctx, p, a.PlanModifiers)
+		case schema.Int32Attribute:
+			w.int32Modifiers(ctx, p, a.PlanModifiers)
+		case schema.Int64Attribute:
+			w.int64Modifiers(ctx, p, a.PlanModifiers)
+		case schema.Float32Attribute:
+			w.float32Modifiers(ctx, p, a.PlanModifiers)
+		case schema.Float64Attribute:
+			w.float64Modifiers(ctx, p, a.PlanModifiers)
+		case schema.NumberAttribute:
+			w.numberModifiers(ctx, p, a.PlanModifiers)
+		case schema.DynamicAttribute:
+			w.dynamicModifiers(ctx, p, a.PlanModifiers)
+		case schema.MapAttribute:
+			w.mapModifiers(ctx, p, a.PlanModifiers)
+		case schema.SetAttribute:
+			w.setModifiers(ctx, p, a.PlanModifiers)
+		case schema.ListAttribute:
+			w.listModifiers(ctx, p, a.PlanModifiers)
+		case schema.ObjectAttribute:
+			w.objectModifiers(ctx, p, a.PlanModifiers)
+		case schema.ListNestedAttribute:
+			w.listModifiers(ctx, p, a.PlanModifiers)
+			for _, element := range w.elements(ctx, p) {
+				w.object(ctx, element, a.NestedObject.PlanModifiers, a.NestedObject.Attributes, nil)
+			}
+		case schema.SetNestedAttribute:
+			w.setModifiers(ctx, p, a.PlanModifiers)
+			for _, element := range w.elements(ctx, p) {
+				w.object(ctx, element, a.NestedObject.PlanModifiers, a.NestedObject.Attributes, nil)
+			}
+		case schema.MapNestedAttribute:
+			w.mapModifiers(ctx, p, a.PlanModifiers)
+			for _, element := range w.elements(ctx, p) {
+				w.object(ctx, element, a.NestedObject.PlanModifiers, a.NestedObject.Attributes, nil)
+			}
+		case schema.SingleNestedAttribute:
+			w.object(ctx, p, a.PlanModifiers, a.Attributes, nil)
+		default:
+			w.unsupported(p, a)
+		}
+	}
+}
+
+func (w *replaceWalker) blocks(ctx context.Context, parent path.Path, blocks map[string]schema.Block) {
+	for _, name := range sortedNames(blocks) {
+		if w.diags.HasError() {
+			return
+		}
+		p := parent.AtName(name)
+		switch b := blocks[name].(type) {
+		case schema.ListNestedBlock:
+			w.listModifiers(ctx, p, b.PlanModifiers)
+			for _, element := range w.elements(ctx, p) {
+				w.object(ctx, element, b.NestedObject.PlanModifiers, b.NestedObject.Attributes, b.NestedObject.Blocks)
+			}
+		case schema.SetNestedBlock:
+			w.setModifiers(ctx, p, b.PlanModifiers)
+			for _, element := range w.elements(ctx, p) {
+				w.object(ctx, element, b.NestedObject.PlanModifiers, b.NestedObject.Attributes, b.NestedObject.Blocks)
+			}
+		case schema.SingleNestedBlock:
+			w.object(ctx, p, b.PlanModifiers, b.Attributes, b.Blocks)
+		default:
+			w.unsupported(p, b)
+		}
+	}
+}
+
+func (w *replaceWalker) stringModifiers(ctx context.Context, p path.Path, modifiers []planmodifier.String) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.String
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.StringRequest{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.StringResponse{PlanValue: plan}
+		modifier.PlanModifyString(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) boolModifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Bool) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Bool
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.BoolRequest{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.BoolResponse{PlanValue: plan}
+		modifier.PlanModifyBool(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) int32Modifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Int32) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Int32
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.Int32Request{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.Int32Response{PlanValue: plan}
+		modifier.PlanModifyInt32(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) int64Modifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Int64) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Int64
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.Int64Request{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.Int64Response{PlanValue: plan}
+		modifier.PlanModifyInt64(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) float32Modifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Float32) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Float32
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.Float32Request{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.Float32Response{PlanValue: plan}
+		modifier.PlanModifyFloat32(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) float64Modifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Float64) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Float64
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.Float64Request{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.Float64Response{PlanValue: plan}
+		modifier.PlanModifyFloat64(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) numberModifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Number) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Number
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.NumberRequest{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.NumberResponse{PlanValue: plan}
+		modifier.PlanModifyNumber(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) dynamicModifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Dynamic) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Dynamic
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.DynamicRequest{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.DynamicResponse{PlanValue: plan}
+		modifier.PlanModifyDynamic(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) mapModifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Map) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Map
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.MapRequest{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.MapResponse{PlanValue: plan}
+		modifier.PlanModifyMap(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) setModifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Set) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Set
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.SetRequest{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.SetResponse{PlanValue: plan}
+		modifier.PlanModifySet(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) listModifiers(ctx context.Context, p path.Path, modifiers []planmodifier.List) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.List
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.ListRequest{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.ListResponse{PlanValue: plan}
+		modifier.PlanModifyList(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
+
+func (w *replaceWalker) objectModifiers(ctx context.Context, p path.Path, modifiers []planmodifier.Object) {
+	if len(modifiers) == 0 {
+		return
+	}
+	var config, plan, state types.Object
+	if !w.values(ctx, p, &config, &plan, &state) {
+		return
+	}
+	for _, modifier := range modifiers {
+		req := planmodifier.ObjectRequest{Path: p, PathExpression: p.Expression(), Config: w.req.Config, ConfigValue: config, Plan: w.req.Plan, PlanValue: plan, State: w.req.State, StateValue: state}
+		resp := &planmodifier.ObjectResponse{PlanValue: plan}
+		modifier.PlanModifyObject(ctx, req, resp)
+		w.result(p, resp.RequiresReplace, resp.Diagnostics)
+	}
+}
@@ -1,8 +1,48 @@
 package framework
 
-import "terraform-provider-automq/client"
+import (
+	"errors"
+	"net/http"
+	"terraform-provider-automq/client"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// IsNotFoundError reports whether err is a 404 from the control plane. Read
+// removes the resource from state when it is, so no other status counts.
 func IsNotFoundError(err error) bool {
-	condition, ok := err.(*client.ErrorResponse)
-	return ok && condition.Code == 404
+	var apiErr *client.ErrorResponse
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// APIErrorAttributes maps kinds of API errors (client.ErrConflict,
+// client.ErrInvalidParameter, ...) to the attribute they are caused by.
+type APIErrorAttributes map[error]path.Path
+
+// AddAPIError adds a diagnostic for err. When err is a typed API error whose
+// kind is mapped in attributes, the diagnostic is reported on that attribute
+// so Terraform points at the offending configuration; otherwise it is a
+// resource-level error. The detail should already include err.
+func AddAPIError(diags *diag.Diagnostics, summary, detail string, err error, attributes APIErrorAttributes) {
+	var apiErr *client.ErrorResponse
+	if !errors.As(err, &apiErr) {
+		diags.AddError(summary, detail)
+		return
+	}
+	kind := apiErr.Kind()
+	if hint := apiErrorHints[kind]; hint != "" {
+		detail += "\n" + hint
+	}
+	if attribute, ok := attributes[kind]; ok && kind != nil {
+		diags.AddAttributeError(attribute, summary, detail)
+		return
+	}
+	diags.AddError(summary, detail)
+}
+
+var apiErrorHints = map[error]string{
+	client.ErrConflict:      "A resource with the same identity already exists. Import it with `terraform import` or choose a different value.",
+	client.ErrStateConflict: "The resource is not in a state that allows this operation. Wait until it is Running and apply again.",
+	client.ErrThrottled:     "The AutoMQ control plane throttled the request. Lower max_requests_per_second or max_concurrent_requests, or apply again later.",
 }
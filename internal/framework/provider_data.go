@@ -0,0 +1,73 @@
+package framework
+
+import (
+	"context"
+	"terraform-provider-automq/client"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ProviderData is handed by the provider to the Configure method of every
+// resource and data source.
+type ProviderData struct {
+	Client *client.Client
+	// EnvironmentID is the provider environment_id, used by resources and
+	// data sources that do not set their own. Empty when not configured.
+	EnvironmentID string
+	// DefaultTags are the provider default_tags, merged into the tags of the
+	// resources that support them. Nil when not configured.
+	DefaultTags map[string]string
+}
+
+// WithDefaultEnvironment is intended to be embedded in resources whose
+// environment_id attribute is Optional and Computed, with the
+// UseStateForUnknown and RequiresReplace plan modifiers, and falls back to
+// the provider environment_id.
+type WithDefaultEnvironment struct {
+	configured           bool
+	defaultEnvironmentID string
+}
+
+// SetDefaultEnvironmentID sets the provider environment_id. It is called
+// from Configure.
+func (w *WithDefaultEnvironment) SetDefaultEnvironmentID(environmentID string) {
+	w.configured = true
+	w.defaultEnvironmentID = environmentID
+}
+
+// DefaultEnvironmentID returns the provider environment_id, empty when not
+// configured.
+func (w *WithDefaultEnvironment) DefaultEnvironmentID() string {
+	return w.defaultEnvironmentID
+}
+
+// ModifyPlan plans the provider environment_id for resources being created
+// without one. Resources already in state keep the environment they were
+// created in, through UseStateForUnknown, even when the provider default
+// changes; a resource-level value always takes precedence.
+func (w *WithDefaultEnvironment) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !w.configured {
+		return
+	}
+	var planned types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("environment_id"), &planned)...)
+	if resp.Diagnostics.HasError() || !planned.IsUnknown() {
+		return
+	}
+	var configured types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("environment_id"), &configured)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+	if w.defaultEnvironmentID == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("environment_id"),
+			"Missing Environment ID",
+			"Set environment_id on this resource, or a default environment_id in the provider block or with the AUTOMQ_ENVIRONMENT_ID environment variable.",
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("environment_id"), w.defaultEnvironmentID)...)
+}
@@ -0,0 +1,34 @@
+package framework
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the spans created by resources
+// and data sources.
+const TracerName = "terraform-provider-automq"
+
+// StartSpan starts a span named name, e.g. "automq_kafka_topic.Create". It is
+// a no-op unless tracing is enabled with OTEL_EXPORTER_OTLP_ENDPOINT.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends a span started for a CRUD operation, marking it failed when
+// diags has errors. It is meant to be deferred right after StartSpan.
+func EndSpan(span trace.Span, diags *diag.Diagnostics) {
+	if errs := diags.Errors(); len(errs) > 0 {
+		for _, d := range errs {
+			span.RecordError(errors.New(d.Summary() + ": " + d.Detail()))
+		}
+		span.SetStatus(codes.Error, errs[0].Summary())
+	}
+	span.End()
+}
@@ -9,10 +9,21 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ScaleWait applies the WaitScale of c to a wait delay or poll interval.
+func ScaleWait(c *client.Client, d time.Duration) time.Duration {
+	if c == nil || c.WaitScale <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * c.WaitScale)
+}
+
 func WaitForKafkaClusterState(ctx context.Context, c *client.Client, clusterId, pendingState, targetState string, timeout time.Duration, refreshFunc retry.StateRefreshFunc) error {
-	delay, pollInterval := 20*time.Second, 10*time.Second
+	delay, pollInterval := ScaleWait(c, 20*time.Second), ScaleWait(c, 10*time.Second)
 	stateConf := &retry.StateChangeConf{
 		Pending:      []string{pendingState},
 		Target:       []string{targetState},
@@ -39,11 +50,35 @@ func WaitForKafkaClusterState(ctx context.Context, c *client.Client, clusterId,
 	return nil
 }
 
-func WaitForKafkaClusterToProvision(ctx context.Context, c *client.Client, clusterId, pendingState string, timeout time.Duration) error {
+func WaitForKafkaClusterToProvision(ctx context.Context, c *client.Client, clusterId, pendingState string, timeout time.Duration) (err error) {
+	ctx, span := startWaitSpan(ctx, "WaitForKafkaClusterToProvision", clusterId, models.StateRunning)
+	defer func() { endWaitSpan(span, err) }()
 	return WaitForKafkaClusterState(ctx, c, clusterId, pendingState, models.StateRunning, timeout, KafkaClusterStatus(ctx, c, clusterId, models.StateRunning))
 }
 
-func WaitForKafkaClusterToDeleted(ctx context.Context, c *client.Client, clusterId string, timeout time.Duration) error {
+// WaitForKafkaClusterUpgrade waits for a version upgrade to roll out, logging
+// the state and version of the cluster at every poll.
+func WaitForKafkaClusterUpgrade(ctx context.Context, c *client.Client, clusterId, version string, timeout time.Duration) (err error) {
+	ctx, span := startWaitSpan(ctx, "WaitForKafkaClusterUpgrade", clusterId, models.StateRunning)
+	defer func() { endWaitSpan(span, err) }()
+	status := KafkaClusterStatus(ctx, c, clusterId, models.StateRunning)
+	start := time.Now()
+	return WaitForKafkaClusterState(ctx, c, clusterId, models.StateChanging, models.StateRunning, timeout, func() (interface{}, string, error) {
+		result, state, err := status()
+		if cluster, ok := result.(*client.InstanceVO); ok {
+			tflog.Info(ctx, fmt.Sprintf("Kafka Cluster %q upgrade to %s in progress", clusterId, version), map[string]interface{}{
+				"state":   state,
+				"version": derefState(cluster.Version),
+				"elapsed": time.Since(start).Round(time.Second).String(),
+			})
+		}
+		return result, state, err
+	})
+}
+
+func WaitForKafkaClusterToDeleted(ctx context.Context, c *client.Client, clusterId string, timeout time.Duration) (err error) {
+	ctx, span := startWaitSpan(ctx, "WaitForKafkaClusterToDeleted", clusterId, models.StateNotFound)
+	defer func() { endWaitSpan(span, err) }()
 	return WaitForKafkaClusterState(ctx, c, clusterId, models.StateDeleting, models.StateNotFound, timeout, KafkaClusterStatus(ctx, c, clusterId, models.StateNotFound))
 }
 
@@ -70,6 +105,11 @@ func KafkaClusterStatus(ctx context.Context, c *client.Client, clusterId string,
 			} else {
 				tflog.Info(ctx, message)
 			}
+			trace.SpanFromContext(ctx).AddEvent("state transition", trace.WithAttributes(
+				attribute.String("automq.instance_id", clusterId),
+				attribute.String("automq.previous_state", lastState),
+				attribute.String("automq.state", currentState),
+			))
 			lastState = currentState
 		}
 
@@ -80,6 +120,21 @@ func KafkaClusterStatus(ctx context.Context, c *client.Client, clusterId string,
 	}
 }
 
+func startWaitSpan(ctx context.Context, name, clusterId, targetState string) (context.Context, trace.Span) {
+	return StartSpan(ctx, name,
+		attribute.String("automq.instance_id", clusterId),
+		attribute.String("automq.target_state", targetState),
+	)
+}
+
+func endWaitSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 func derefState(statePtr *string) string {
 	if statePtr == nil {
 		return models.StateUnknown
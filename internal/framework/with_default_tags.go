@@ -0,0 +1,118 @@
+package framework
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// WithDefaultTags is intended to be embedded in resources with an optional
+// tags map attribute and a computed tags_all map attribute, which holds the
+// provider default_tags merged with the resource tags.
+type WithDefaultTags struct {
+	defaultTags map[string]string
+}
+
+// SetDefaultTags sets the provider default_tags. It is called from Configure.
+func (w *WithDefaultTags) SetDefaultTags(tags map[string]string) {
+	w.defaultTags = tags
+}
+
+// MergeTags returns the provider default_tags overridden by tags. The result
+// is unknown when tags is unknown, and an empty map when there are no tags
+// at all.
+func (w *WithDefaultTags) MergeTags(ctx context.Context, tags types.Map) (types.Map, diag.Diagnostics) {
+	if tags.IsUnknown() {
+		return types.MapUnknown(types.StringType), nil
+	}
+	merged := make(map[string]string, len(w.defaultTags))
+	for key, value := range w.defaultTags {
+		merged[key] = value
+	}
+	if !tags.IsNull() {
+		var own map[string]string
+		if diags := tags.ElementsAs(ctx, &own, false); diags.HasError() {
+			return types.MapUnknown(types.StringType), diags
+		}
+		for key, value := range own {
+			merged[key] = value
+		}
+	}
+	return types.MapValueFrom(ctx, types.StringType, merged)
+}
+
+// ResourceTags returns the tags attribute for all, the tags read back from
+// the API. prior and priorAll are the previous tags and tags_all attributes.
+// A key that is not in prior is left out when it is a current provider
+// default, or was one when priorAll was written, whatever its value, so that
+// changing or removing a default updates tags_all instead of planning a diff
+// on tags. Without a prior tags_all, as on import, only keys whose value
+// equals the current default are left out.
+func (w *WithDefaultTags) ResourceTags(ctx context.Context, all, prior, priorAll types.Map) (types.Map, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if all.IsNull() || all.IsUnknown() {
+		return prior, diags
+	}
+	var allTags, priorTags, priorAllTags map[string]string
+	diags.Append(all.ElementsAs(ctx, &allTags, false)...)
+	if !prior.IsNull() && !prior.IsUnknown() {
+		diags.Append(prior.ElementsAs(ctx, &priorTags, false)...)
+	}
+	if !priorAll.IsNull() && !priorAll.IsUnknown() {
+		diags.Append(priorAll.ElementsAs(ctx, &priorAllTags, false)...)
+	}
+	if diags.HasError() {
+		return prior, diags
+	}
+	tags := make(map[string]string, len(allTags))
+	for key, value := range allTags {
+		if _, configured := priorTags[key]; configured {
+			tags[key] = value
+			continue
+		}
+		_, previousDefault := priorAllTags[key]
+		defaultValue, currentDefault := w.defaultTags[key]
+		if previousDefault || (currentDefault && (priorAllTags != nil || defaultValue == value)) {
+			continue
+		}
+		tags[key] = value
+	}
+	if len(tags) == 0 && priorTags == nil {
+		return types.MapNull(types.StringType), diags
+	}
+	result, d := types.MapValueFrom(ctx, types.StringType, tags)
+	diags.Append(d...)
+	return result, diags
+}
+
+// ModifyPlan plans tags_all from the configured tags. A null tags attribute
+// that is planned unknown, because it is also computed, counts as no tags.
+func (w *WithDefaultTags) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var tags types.Map
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("tags"), &tags)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if tags.IsUnknown() {
+		var configured types.Map
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("tags"), &configured)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if configured.IsNull() {
+			tags = configured
+		}
+	}
+	tagsAll, diags := w.MergeTags(ctx, tags)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("tags_all"), tagsAll)...)
+}
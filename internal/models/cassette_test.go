@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"terraform-provider-automq/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordCassettes re-records the cassettes against the control plane in
+// AUTOMQ_BYOC_ENDPOINT, with the credentials in AUTOMQ_BYOC_ACCESS_KEY_ID and
+// AUTOMQ_BYOC_SECRET_KEY, in the environment AUTOMQ_ENVIRONMENT_ID:
+//
+//	go test ./internal/models -run Cassette -cassette.record
+//
+// The tests create and update real resources, so the IDs they use must exist
+// in that environment.
+var recordCassettes = flag.Bool("cassette.record", false, "record testdata/cassettes against a real control plane instead of replaying them")
+
+// replayClient returns a client answered from testdata/cassettes/<name>.yaml.
+// The test fails if the payloads it sends differ from the recording or if a
+// recorded request is never sent. With -cassette.record the client sends its
+// requests to a real control plane and overwrites the cassette instead.
+func replayClient(t *testing.T, name string) (context.Context, *client.Client) {
+	t.Helper()
+	path := filepath.Join("testdata", "cassettes", name+".yaml")
+	if *recordCassettes {
+		return recordingClient(t, path)
+	}
+	replayer, err := client.NewReplayTransport(path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.Empty(t, replayer.Unused(), "recorded requests that were not sent")
+	})
+	ctx := context.WithValue(context.Background(), client.EnvIdKey, "env-cassette")
+	c, err := client.NewClient(ctx, "https://replay.invalid", client.AuthCredentials{AccessKeyID: "ak", SecretAccessKey: "sk"},
+		func(c *client.Client) {
+			c.HTTPClient.Transport = replayer
+			c.MaxRetries = 0
+		})
+	require.NoError(t, err)
+	return ctx, c
+}
+
+func recordingClient(t *testing.T, path string) (context.Context, *client.Client) {
+	t.Helper()
+	endpoint, environmentID := os.Getenv("AUTOMQ_BYOC_ENDPOINT"), os.Getenv("AUTOMQ_ENVIRONMENT_ID")
+	if endpoint == "" || environmentID == "" {
+		t.Fatal("-cassette.record requires AUTOMQ_BYOC_ENDPOINT and AUTOMQ_ENVIRONMENT_ID")
+	}
+	recorder := client.NewRecordingTransport(path, nil)
+	t.Cleanup(func() {
+		require.NoError(t, recorder.Save())
+	})
+	ctx := context.WithValue(context.Background(), client.EnvIdKey, environmentID)
+	credentials := client.AuthCredentials{
+		AccessKeyID:     os.Getenv("AUTOMQ_BYOC_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AUTOMQ_BYOC_SECRET_KEY"),
+	}
+	c, err := client.NewClient(ctx, strings.TrimRight(endpoint, "/"), credentials, func(c *client.Client) {
+		c.HTTPClient.Transport = recorder
+	})
+	require.NoError(t, err)
+	return ctx, c
+}
+
+func TestExpandKafkaInstanceResourceMatchesCassette(t *testing.T) {
+	ctx, c := replayClient(t, "kafka_instance_create")
+
+	plan := KafkaInstanceResourceModel{
+		Name:        types.StringValue("orders"),
+		Description: types.StringValue("orders cluster"),
+		Version:     types.StringValue("5.2.0"),
+		ComputeSpecs: &ComputeSpecsModel{
+			ReservedAku: types.Int64Value(6),
+			DeployType:  types.StringValue("IAAS"),
+			Networks: testNetworkList(t, []NetworkModel{{
+				Zone:    types.StringValue("us-east-1a"),
+				Subnets: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("subnet-0a1b2c")}),
+			}}),
+		},
+		Features: &FeaturesModel{
+			WalMode: types.StringValue("EBSWAL"),
+			Security: testSecurityObject(t, &SecurityModel{
+				AuthenticationMethods:  types.SetValueMust(types.StringType, []attr.Value{types.StringValue("sasl")}),
+				TransitEncryptionModes: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("plaintext")}),
+				DataEncryptionMode:     types.StringValue("NONE"),
+			}),
+			InstanceConfigs: types.MapValueMust(types.StringType, map[string]attr.Value{
+				"auto.create.topics.enable": types.StringValue("false"),
+			}),
+		},
+	}
+	request := &client.InstanceCreateParam{}
+	require.NoError(t, ExpandKafkaInstanceResource(ctx, plan, request))
+
+	summary, err := c.CreateKafkaInstance(ctx, *request)
+	require.NoError(t, err)
+	assert.Equal(t, "kf-00000001", *summary.InstanceId)
+}
+
+func TestExpandConnectorUpdateMatchesCassette(t *testing.T) {
+	ctx, c := replayClient(t, "connector_update")
+
+	config, diags := types.MapValueFrom(ctx, types.StringType, map[string]string{"topics": "orders,payments", "flush.size": "1000"})
+	require.False(t, diags.HasError())
+	sensitive, diags := types.MapValueFrom(ctx, types.StringType, map[string]string{"aws.secret.access.key": "scrubbed-in-cassette"})
+	require.False(t, diags.HasError())
+	plan := ConnectorResourceModel{
+		Name:                     types.StringValue("s3-sink"),
+		Description:              types.StringValue("orders to S3"),
+		TaskCount:                types.Int64Value(4),
+		ConnectorConfig:          config,
+		ConnectorConfigSensitive: sensitive,
+	}
+	request, diags := ExpandConnectorUpdate(plan)
+	require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+
+	connector, err := c.UpdateConnector(ctx, "connector-00000004", *request)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), *connector.TaskCount)
+	assert.Equal(t, client.ConnectorStateChanging, *connector.State)
+}
@@ -23,6 +23,8 @@ type ConnectClusterResourceModel struct {
 	WorkerConfig        types.Map                      `tfsdk:"worker_config"`
 	MetricExporter      *ConnectorMetricsExporterModel `tfsdk:"metric_exporter"`
 	Tags                types.Map                      `tfsdk:"tags"`
+	TagsAll             types.Map                      `tfsdk:"tags_all"`
+	DeletionProtection  types.Bool                     `tfsdk:"deletion_protection"`
 	Version             types.String                   `tfsdk:"version"`
 	State               types.String                   `tfsdk:"state"`
 	KafkaConnectVersion types.String                   `tfsdk:"kafka_connect_version"`
@@ -94,7 +96,7 @@ func ExpandConnectClusterCreate(plan ConnectClusterResourceModel) (*client.Conne
 	if cfg := cExpandMetrics(plan.MetricExporter); cfg != nil {
 		request.MetricExporter = cfg
 	}
-	if m := cExpandStringMap(plan.Tags); m != nil {
+	if m := cExpandStringMap(EffectiveTags(plan.Tags, plan.TagsAll)); m != nil {
 		request.Tags = m
 	}
 	if s := cOptStr(plan.Version); s != nil {
@@ -124,7 +126,7 @@ func ExpandConnectClusterUpdate(plan ConnectClusterResourceModel) (*client.Conne
 	if cfg := cExpandMetrics(plan.MetricExporter); cfg != nil {
 		request.MetricExporter = cfg
 	}
-	if m := cExpandStringMap(plan.Tags); m != nil {
+	if m := cExpandStringMap(EffectiveTags(plan.Tags, plan.TagsAll)); m != nil {
 		request.Tags = m
 	}
 	if s := cOptStr(plan.Version); s != nil {
@@ -149,6 +151,7 @@ func FlattenConnectCluster(vo *client.ConnectClusterVO, state *ConnectClusterRes
 	state.WorkerConfig = cFlattenInterfaceMapRetainEmpty(vo.WorkerConfig, state.WorkerConfig)
 	state.MetricExporter = cFlattenMetrics(vo.MetricExporter, state.MetricExporter)
 	state.Tags = cFlattenStringMap(vo.Tags)
+	state.TagsAll = FlattenTagsAll(vo.Tags)
 	state.Version = cToStr(vo.Version)
 	state.State = cToStr(vo.State)
 	state.KafkaConnectVersion = cToStr(vo.KafkaConnectVersion)
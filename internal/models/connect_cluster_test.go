@@ -98,6 +98,7 @@ func TestFlattenConnectCluster(t *testing.T) {
 	assert.Equal(t, "provisioned", state.Capacity.Type.ValueString())
 	assert.Equal(t, int64(2), state.Capacity.Provisioned.WorkerCount.ValueInt64())
 	assert.Equal(t, "3.9.0", state.KafkaConnectVersion.ValueString())
+	assert.Equal(t, state.Tags, state.TagsAll)
 }
 
 func TestFlattenConnectCluster_RetainsEmptyWorkerConfig(t *testing.T) {
@@ -26,19 +26,22 @@ const (
 )
 
 type KafkaInstanceResourceModel struct {
-	EnvironmentID  types.String       `tfsdk:"environment_id"`
-	InstanceID     types.String       `tfsdk:"id"`
-	Name           types.String       `tfsdk:"name"`
-	Description    types.String       `tfsdk:"description"`
-	Version        types.String       `tfsdk:"version"`
-	ComputeSpecs   *ComputeSpecsModel `tfsdk:"compute_specs"`
-	Features       *FeaturesModel     `tfsdk:"features"`
-	Tags           types.Map          `tfsdk:"tags"`
-	Endpoints      types.List         `tfsdk:"endpoints"`
-	CreatedAt      timetypes.RFC3339  `tfsdk:"created_at"`
-	LastUpdated    timetypes.RFC3339  `tfsdk:"last_updated"`
-	InstanceStatus types.String       `tfsdk:"status"`
-	Timeouts       timeouts.Value     `tfsdk:"timeouts"`
+	EnvironmentID      types.String       `tfsdk:"environment_id"`
+	InstanceID         types.String       `tfsdk:"id"`
+	Name               types.String       `tfsdk:"name"`
+	Description        types.String       `tfsdk:"description"`
+	Version            types.String       `tfsdk:"version"`
+	ComputeSpecs       *ComputeSpecsModel `tfsdk:"compute_specs"`
+	Features           *FeaturesModel     `tfsdk:"features"`
+	Tags               types.Map          `tfsdk:"tags"`
+	TagsAll            types.Map          `tfsdk:"tags_all"`
+	DeletionProtection types.Bool         `tfsdk:"deletion_protection"`
+	WaitForRunning     types.Bool         `tfsdk:"wait_for_running_before_update"`
+	Endpoints          types.List         `tfsdk:"endpoints"`
+	CreatedAt          timetypes.RFC3339  `tfsdk:"created_at"`
+	LastUpdated        timetypes.RFC3339  `tfsdk:"last_updated"`
+	InstanceStatus     types.String       `tfsdk:"status"`
+	Timeouts           timeouts.Value     `tfsdk:"timeouts"`
 }
 
 type KafkaInstanceModel struct {
@@ -165,9 +168,23 @@ var PrometheusExporterObjectType = types.ObjectType{
 	},
 }
 
+var CloudWatchExporterObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"namespace": types.StringType,
+	},
+}
+
+var OtlpExporterObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"endpoint": types.StringType,
+	},
+}
+
 var MetricsExporterObjectType = types.ObjectType{
 	AttrTypes: map[string]attr.Type{
 		"prometheus": PrometheusExporterObjectType,
+		"cloudwatch": CloudWatchExporterObjectType,
+		"otlp":       OtlpExporterObjectType,
 	},
 }
 
@@ -184,6 +201,29 @@ var TableTopicObjectType = types.ObjectType{
 	},
 }
 
+var S3FailoverObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"enabled":               types.BoolType,
+		"storage_type":          types.StringType,
+		"ebs_volume_size_in_gb": types.Int64Type,
+	},
+}
+
+var InboundRuleObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"listener_name": types.StringType,
+		"cidrs":         types.SetType{ElemType: types.StringType},
+	},
+}
+
+var ExtendListenerObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"listener_name":     types.StringType,
+		"security_protocol": types.StringType,
+		"port":              types.Int64Type,
+	},
+}
+
 func NetworkListToModels(ctx context.Context, list types.List) ([]NetworkModel, diag.Diagnostics) {
 	if list.IsNull() || list.IsUnknown() {
 		return nil, nil
@@ -216,6 +256,38 @@ func NodeGroupModelsToList(ctx context.Context, groups []NodeGroupModel) (types.
 	return types.ListValueFrom(ctx, NodeGroupObjectType, groups)
 }
 
+func InboundRuleListToModels(ctx context.Context, list types.List) ([]InboundRuleModel, diag.Diagnostics) {
+	if list.IsNull() || list.IsUnknown() {
+		return nil, nil
+	}
+	var rules []InboundRuleModel
+	diags := list.ElementsAs(ctx, &rules, false)
+	return rules, diags
+}
+
+func InboundRuleModelsToList(ctx context.Context, rules []InboundRuleModel) (types.List, diag.Diagnostics) {
+	if len(rules) == 0 {
+		return types.ListNull(InboundRuleObjectType), nil
+	}
+	return types.ListValueFrom(ctx, InboundRuleObjectType, rules)
+}
+
+func ExtendListenerListToModels(ctx context.Context, list types.List) ([]ExtendListenerModel, diag.Diagnostics) {
+	if list.IsNull() || list.IsUnknown() {
+		return nil, nil
+	}
+	var listeners []ExtendListenerModel
+	diags := list.ElementsAs(ctx, &listeners, false)
+	return listeners, diags
+}
+
+func ExtendListenerModelsToList(ctx context.Context, listeners []ExtendListenerModel) (types.List, diag.Diagnostics) {
+	if len(listeners) == 0 {
+		return types.ListNull(ExtendListenerObjectType), nil
+	}
+	return types.ListValueFrom(ctx, ExtendListenerObjectType, listeners)
+}
+
 func DataBucketListToModels(ctx context.Context, list types.List) ([]DataBucketModel, diag.Diagnostics) {
 	if list.IsNull() || list.IsUnknown() {
 		return nil, nil
@@ -359,6 +431,12 @@ func MetricsExporterModelToObject(ctx context.Context, model *MetricsExporterMod
 		}
 		normalized.Prometheus = &prom
 	}
+	if normalized.CloudWatch != nil && normalized.CloudWatch.Namespace.IsNull() {
+		normalized.CloudWatch = &CloudWatchExporterModel{Namespace: types.StringNull()}
+	}
+	if normalized.Otlp != nil && normalized.Otlp.EndPoint.IsNull() {
+		normalized.Otlp = &OtlpExporterModel{EndPoint: types.StringNull()}
+	}
 	return types.ObjectValueFrom(ctx, MetricsExporterObjectType.AttrTypes, normalized)
 }
 
@@ -403,6 +481,22 @@ func TableTopicModelToObject(ctx context.Context, model *TableTopicModel) (types
 	return types.ObjectValueFrom(ctx, TableTopicObjectType.AttrTypes, normalized)
 }
 
+func S3FailoverObjectToModel(ctx context.Context, object types.Object) (*S3FailoverModel, diag.Diagnostics) {
+	if object.IsNull() || object.IsUnknown() {
+		return nil, nil
+	}
+	var model S3FailoverModel
+	diags := object.As(ctx, &model, basetypes.ObjectAsOptions{})
+	return &model, diags
+}
+
+func S3FailoverModelToObject(ctx context.Context, model *S3FailoverModel) (types.Object, diag.Diagnostics) {
+	if model == nil {
+		return types.ObjectNull(S3FailoverObjectType.AttrTypes), nil
+	}
+	return types.ObjectValueFrom(ctx, S3FailoverObjectType.AttrTypes, model)
+}
+
 func coalesceStringAttr(apiValue *string, previous *types.String) types.String {
 	if apiValue != nil {
 		return types.StringValue(*apiValue)
@@ -430,6 +524,9 @@ type FeaturesModel struct {
 	MetricsExporter       types.Object `tfsdk:"metrics_exporter"`
 	TableTopic            types.Object `tfsdk:"table_topic"`
 	SchemaRegistryEnabled types.Bool   `tfsdk:"schema_registry_enabled"`
+	S3Failover            types.Object `tfsdk:"s3_failover"`
+	InboundRules          types.List   `tfsdk:"inbound_rules"`
+	ExtendListeners       types.List   `tfsdk:"extend_listeners"`
 }
 
 type FeaturesSummaryModel struct {
@@ -439,6 +536,9 @@ type FeaturesSummaryModel struct {
 	MetricsExporter       types.Object `tfsdk:"metrics_exporter"`
 	TableTopic            types.Object `tfsdk:"table_topic"`
 	SchemaRegistryEnabled types.Bool   `tfsdk:"schema_registry_enabled"`
+	S3Failover            types.Object `tfsdk:"s3_failover"`
+	InboundRules          types.List   `tfsdk:"inbound_rules"`
+	ExtendListeners       types.List   `tfsdk:"extend_listeners"`
 }
 
 type SecurityModel struct {
@@ -460,6 +560,16 @@ type SecuritySummaryModel struct {
 
 type MetricsExporterModel struct {
 	Prometheus *PrometheusExporterModel `tfsdk:"prometheus"`
+	CloudWatch *CloudWatchExporterModel `tfsdk:"cloudwatch"`
+	Otlp       *OtlpExporterModel       `tfsdk:"otlp"`
+}
+
+type CloudWatchExporterModel struct {
+	Namespace types.String `tfsdk:"namespace"`
+}
+
+type OtlpExporterModel struct {
+	EndPoint types.String `tfsdk:"endpoint"`
 }
 
 type PrometheusExporterModel struct {
@@ -478,7 +588,9 @@ func MetricsExporterHasConfig(model *MetricsExporterModel) bool {
 	if model == nil {
 		return false
 	}
-	return PrometheusExporterHasConfig(model.Prometheus)
+	return PrometheusExporterHasConfig(model.Prometheus) ||
+		(model.CloudWatch != nil && isKnownStringSet(model.CloudWatch.Namespace)) ||
+		(model.Otlp != nil && isKnownStringSet(model.Otlp.EndPoint))
 }
 
 // PrometheusExporterHasConfig inspects the nested Prometheus block for any user
@@ -514,6 +626,18 @@ func BuildMetricsExporterParam(model *MetricsExporterModel) (*client.InstanceMet
 			hasConfig = true
 		}
 	}
+	if model.CloudWatch != nil && isKnownStringSet(model.CloudWatch.Namespace) {
+		enabled := true
+		namespace := model.CloudWatch.Namespace.ValueString()
+		exporter.CloudWatch = &client.InstanceCloudWatchExporterParam{Enabled: &enabled, Namespace: &namespace}
+		hasConfig = true
+	}
+	if model.Otlp != nil && isKnownStringSet(model.Otlp.EndPoint) {
+		enabled := true
+		endpoint := model.Otlp.EndPoint.ValueString()
+		exporter.Oltp = &client.InstanceOLTPExporterParam{Enabled: &enabled, EndPoint: &endpoint}
+		hasConfig = true
+	}
 	if !hasConfig {
 		return nil, false
 	}
@@ -586,6 +710,81 @@ type TableTopicModel struct {
 	Krb5ConfFile      types.String `tfsdk:"krb5conf_file"`
 }
 
+// S3FailoverModel is the failover storage of an S3WAL instance.
+type S3FailoverModel struct {
+	Enabled           types.Bool   `tfsdk:"enabled"`
+	StorageType       types.String `tfsdk:"storage_type"`
+	EbsVolumeSizeInGB types.Int64  `tfsdk:"ebs_volume_size_in_gb"`
+}
+
+// InboundRuleModel limits the client addresses allowed on a listener.
+type InboundRuleModel struct {
+	ListenerName types.String `tfsdk:"listener_name"`
+	Cidrs        types.Set    `tfsdk:"cidrs"`
+}
+
+// ExtendListenerModel is a listener added to the default listeners of the
+// instance.
+type ExtendListenerModel struct {
+	ListenerName     types.String `tfsdk:"listener_name"`
+	SecurityProtocol types.String `tfsdk:"security_protocol"`
+	Port             types.Int64  `tfsdk:"port"`
+}
+
+func BuildInboundRuleParams(ctx context.Context, rules []InboundRuleModel) ([]client.InboundRuleParam, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if len(rules) == 0 {
+		return nil, diags
+	}
+	params := make([]client.InboundRuleParam, 0, len(rules))
+	for _, rule := range rules {
+		var cidrs []string
+		diags.Append(rule.Cidrs.ElementsAs(ctx, &cidrs, false)...)
+		params = append(params, client.InboundRuleParam{ListenerName: rule.ListenerName.ValueString(), Cidrs: cidrs})
+	}
+	return params, diags
+}
+
+func BuildExtendListenerParams(listeners []ExtendListenerModel) []client.InstanceListenerParam {
+	if len(listeners) == 0 {
+		return nil
+	}
+	params := make([]client.InstanceListenerParam, 0, len(listeners))
+	for _, listener := range listeners {
+		param := client.InstanceListenerParam{ListenerName: listener.ListenerName.ValueString()}
+		if !listener.SecurityProtocol.IsNull() && !listener.SecurityProtocol.IsUnknown() {
+			protocol := listener.SecurityProtocol.ValueString()
+			param.SecurityProtocol = &protocol
+		}
+		if !listener.Port.IsNull() && !listener.Port.IsUnknown() {
+			port := int32(listener.Port.ValueInt64())
+			param.Port = &port
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+func BuildS3FailoverParam(model *S3FailoverModel) *client.InstanceFailoverParam {
+	if model == nil {
+		return nil
+	}
+	failover := &client.InstanceFailoverParam{}
+	if !model.Enabled.IsNull() && !model.Enabled.IsUnknown() {
+		enabled := model.Enabled.ValueBool()
+		failover.Enabled = &enabled
+	}
+	if !model.StorageType.IsNull() && !model.StorageType.IsUnknown() {
+		storageType := model.StorageType.ValueString()
+		failover.StorageType = &storageType
+	}
+	if !model.EbsVolumeSizeInGB.IsNull() && !model.EbsVolumeSizeInGB.IsUnknown() {
+		size := int32(model.EbsVolumeSizeInGB.ValueInt64())
+		failover.EbsVolumeSizeInGB = &size
+	}
+	return failover
+}
+
 func BuildTableTopicParam(model *TableTopicModel) *client.TableTopicParam {
 	if model == nil {
 		return nil
@@ -813,9 +1012,9 @@ func ExpandKafkaInstanceResource(ctx context.Context, instance KafkaInstanceReso
 	}
 
 	// Tags - expand map to TagParam slice
-	if !instance.Tags.IsNull() && !instance.Tags.IsUnknown() {
+	if tagsValue := EffectiveTags(instance.Tags, instance.TagsAll); !tagsValue.IsNull() && !tagsValue.IsUnknown() {
 		tagsMap := make(map[string]string)
-		diags := instance.Tags.ElementsAs(ctx, &tagsMap, false)
+		diags := tagsValue.ElementsAs(ctx, &tagsMap, false)
 		if diags.HasError() {
 			return fmt.Errorf("failed to parse tags: %v", diags)
 		}
@@ -933,6 +1132,28 @@ func ExpandKafkaInstanceResource(ctx context.Context, instance KafkaInstanceReso
 			request.Features.TableTopic = BuildTableTopicParam(topicModel)
 		}
 
+		// S3 failover
+		failoverModel, failoverDiags := S3FailoverObjectToModel(ctx, instance.Features.S3Failover)
+		if failoverDiags.HasError() {
+			return fmt.Errorf("failed to parse features.s3_failover: %v", failoverDiags.Errors())
+		}
+		request.Features.S3Failover = BuildS3FailoverParam(failoverModel)
+
+		// Inbound rules and extended listeners
+		inboundRules, inboundDiags := InboundRuleListToModels(ctx, instance.Features.InboundRules)
+		if inboundDiags.HasError() {
+			return fmt.Errorf("failed to parse features.inbound_rules: %v", inboundDiags.Errors())
+		}
+		request.Features.InboundRules, inboundDiags = BuildInboundRuleParams(ctx, inboundRules)
+		if inboundDiags.HasError() {
+			return fmt.Errorf("failed to parse features.inbound_rules: %v", inboundDiags.Errors())
+		}
+		listeners, listenerDiags := ExtendListenerListToModels(ctx, instance.Features.ExtendListeners)
+		if listenerDiags.HasError() {
+			return fmt.Errorf("failed to parse features.extend_listeners: %v", listenerDiags.Errors())
+		}
+		request.Features.ExtendListeners = BuildExtendListenerParams(listeners)
+
 		// Schema Registry
 		if !instance.Features.SchemaRegistryEnabled.IsNull() && !instance.Features.SchemaRegistryEnabled.IsUnknown() {
 			enabled := instance.Features.SchemaRegistryEnabled.ValueBool()
@@ -968,6 +1189,9 @@ func ConvertKafkaInstanceModel(resource *KafkaInstanceResourceModel, model *Kafk
 			MetricsExporter:       resource.Features.MetricsExporter,
 			TableTopic:            resource.Features.TableTopic,
 			SchemaRegistryEnabled: resource.Features.SchemaRegistryEnabled,
+			S3Failover:            resource.Features.S3Failover,
+			InboundRules:          resource.Features.InboundRules,
+			ExtendListeners:       resource.Features.ExtendListeners,
 			Security:              types.ObjectNull(SecuritySummaryObjectType.AttrTypes),
 		}
 		security, securityDiags := SecurityObjectToModel(context.Background(), resource.Features.Security)
@@ -1434,6 +1658,36 @@ func FlattenKafkaInstanceModel(ctx context.Context, instance *client.InstanceVO,
 			resource.Features.TableTopic = topicObject
 		}
 
+		// S3 failover
+		var previousFailover *S3FailoverModel
+		if previousFeatures != nil {
+			var failoverDiags diag.Diagnostics
+			previousFailover, failoverDiags = S3FailoverObjectToModel(ctx, previousFeatures.S3Failover)
+			if failoverDiags.HasError() {
+				diags.Append(failoverDiags...)
+			}
+		}
+		failoverObject, failoverDiags := S3FailoverModelToObject(ctx, flattenS3FailoverVO(instance.Features.S3Failover, previousFailover))
+		if failoverDiags.HasError() {
+			diags.Append(failoverDiags...)
+		} else {
+			resource.Features.S3Failover = failoverObject
+		}
+
+		// Inbound rules and extended listeners
+		previousInboundRules := types.ListNull(InboundRuleObjectType)
+		previousListeners := types.ListNull(ExtendListenerObjectType)
+		if previousFeatures != nil {
+			previousInboundRules = previousFeatures.InboundRules
+			previousListeners = previousFeatures.ExtendListeners
+		}
+		inboundRules, inboundDiags := flattenInboundRuleVOs(ctx, instance.Features.InboundRules, previousInboundRules)
+		diags.Append(inboundDiags...)
+		resource.Features.InboundRules = inboundRules
+		listeners, listenerDiags := flattenExtendListenerVOs(ctx, instance.Features.ExtendListeners, previousListeners)
+		diags.Append(listenerDiags...)
+		resource.Features.ExtendListeners = listeners
+
 		var previousSchemaRegistryEnabled *types.Bool
 		if previousFeatures != nil {
 			previousSchemaRegistryEnabled = &previousFeatures.SchemaRegistryEnabled
@@ -1454,20 +1708,19 @@ func FlattenKafkaInstanceModel(ctx context.Context, instance *client.InstanceVO,
 	}
 
 	// Tags - flatten TagVO slice to map
-	if len(instance.Tags) > 0 {
-		tagsMap := make(map[string]string, len(instance.Tags))
-		for _, tag := range instance.Tags {
-			if tag.Name != nil && tag.Value != nil {
-				tagsMap[*tag.Name] = *tag.Value
-			}
+	tagsMap := make(map[string]string, len(instance.Tags))
+	for _, tag := range instance.Tags {
+		if tag.Name != nil && tag.Value != nil {
+			tagsMap[*tag.Name] = *tag.Value
 		}
-		if len(tagsMap) > 0 {
-			tagsValue, tagsDiags := types.MapValueFrom(ctx, types.StringType, tagsMap)
-			if tagsDiags.HasError() {
-				diags.Append(tagsDiags...)
-			} else {
-				resource.Tags = tagsValue
-			}
+	}
+	resource.TagsAll = FlattenTagsAll(tagsMap)
+	if len(tagsMap) > 0 {
+		tagsValue, tagsDiags := types.MapValueFrom(ctx, types.StringType, tagsMap)
+		if tagsDiags.HasError() {
+			diags.Append(tagsDiags...)
+		} else {
+			resource.Tags = tagsValue
 		}
 	} else if resource.Tags.IsUnknown() {
 		resource.Tags = types.MapNull(types.StringType)
@@ -1476,6 +1729,26 @@ func FlattenKafkaInstanceModel(ctx context.Context, instance *client.InstanceVO,
 	return diags
 }
 
+// EffectiveTags returns the tags to send to the API: tagsAll, the tags
+// merged with the provider default_tags, once planned, and tags otherwise.
+func EffectiveTags(tags, tagsAll types.Map) types.Map {
+	if tagsAll.IsNull() || tagsAll.IsUnknown() {
+		return tags
+	}
+	return tagsAll
+}
+
+// FlattenTagsAll returns the tags_all attribute for the tags read from the
+// API. It is an empty map rather than null when there are none, like the
+// planned value.
+func FlattenTagsAll(tags map[string]string) types.Map {
+	values := make(map[string]attr.Value, len(tags))
+	for key, value := range tags {
+		values[key] = types.StringValue(value)
+	}
+	return types.MapValueMust(types.StringType, values)
+}
+
 func isMetricsExporterVOEmpty(vo *client.InstanceMetricsExporterVO) bool {
 	if vo == nil {
 		return true
@@ -1483,7 +1756,26 @@ func isMetricsExporterVOEmpty(vo *client.InstanceMetricsExporterVO) bool {
 	if vo.Prometheus != nil && !isPrometheusVOEmpty(vo.Prometheus) {
 		return false
 	}
-	return true
+	return cloudWatchNamespace(vo.CloudWatch) == nil && otlpEndpoint(vo.Oltp) == nil
+}
+
+// cloudWatchNamespace returns the namespace of an enabled CloudWatch
+// exporter. A disabled exporter may still echo its namespace, which must
+// not bring a removed cloudwatch block back.
+func cloudWatchNamespace(vo *client.InstanceCloudWatchExporterVO) *string {
+	if vo == nil || (vo.Enabled != nil && !*vo.Enabled) {
+		return nil
+	}
+	return cleanAPIString(vo.Namespace)
+}
+
+// otlpEndpoint returns the endpoint of an enabled OTLP exporter, like
+// cloudWatchNamespace.
+func otlpEndpoint(vo *client.InstanceOLTPExporterVO) *string {
+	if vo == nil || (vo.Enabled != nil && !*vo.Enabled) {
+		return nil
+	}
+	return cleanAPIString(vo.EndPoint)
 }
 
 func flattenMetricsExporterVO(vo *client.InstanceMetricsExporterVO, previous *MetricsExporterModel) (*MetricsExporterModel, diag.Diagnostics) {
@@ -1506,7 +1798,16 @@ func flattenMetricsExporterVO(vo *client.InstanceMetricsExporterVO, previous *Me
 	diags.Append(promDiags...)
 	metrics.Prometheus = prom
 
-	if metrics.Prometheus == nil {
+	metrics.CloudWatch = nil
+	if namespace := cloudWatchNamespace(vo.CloudWatch); namespace != nil {
+		metrics.CloudWatch = &CloudWatchExporterModel{Namespace: types.StringValue(*namespace)}
+	}
+	metrics.Otlp = nil
+	if endpoint := otlpEndpoint(vo.Oltp); endpoint != nil {
+		metrics.Otlp = &OtlpExporterModel{EndPoint: types.StringValue(*endpoint)}
+	}
+
+	if metrics.Prometheus == nil && metrics.CloudWatch == nil && metrics.Otlp == nil {
 		return nil, diags
 	}
 	return &metrics, diags
@@ -1589,6 +1890,94 @@ func flattenTableTopicVO(vo *client.TableTopicVO, previous *TableTopicModel) *Ta
 	return &topic
 }
 
+// flattenS3FailoverVO returns nil for failover that is disabled and not in
+// the configuration, so instances that never set s3_failover do not drift.
+func flattenS3FailoverVO(vo *client.InstanceFailoverVO, previous *S3FailoverModel) *S3FailoverModel {
+	if vo == nil {
+		return previous
+	}
+	if previous == nil && !vo.Enabled {
+		return nil
+	}
+	failover := S3FailoverModel{
+		StorageType:       types.StringNull(),
+		EbsVolumeSizeInGB: types.Int64Null(),
+	}
+	if previous != nil {
+		failover = *previous
+	}
+	failover.Enabled = types.BoolValue(vo.Enabled)
+	failover.StorageType = retainString(vo.StorageType, failover.StorageType)
+	if vo.EbsVolumeSizeInGB != nil {
+		failover.EbsVolumeSizeInGB = types.Int64Value(int64(*vo.EbsVolumeSizeInGB))
+	}
+	return &failover
+}
+
+// flattenInboundRuleVOs keeps previous when it holds the same rules, as the
+// API does not keep the order of the rules.
+func flattenInboundRuleVOs(ctx context.Context, vos []client.InstanceInboundRuleVO, previous types.List) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	rules := make([]InboundRuleModel, 0, len(vos))
+	for _, vo := range vos {
+		cidrs, cidrDiags := types.SetValueFrom(ctx, types.StringType, vo.Cidrs)
+		diags.Append(cidrDiags...)
+		rules = append(rules, InboundRuleModel{ListenerName: types.StringPointerValue(vo.ListenerName), Cidrs: cidrs})
+	}
+	if diags.HasError() {
+		return types.ListNull(InboundRuleObjectType), diags
+	}
+	list, listDiags := InboundRuleModelsToList(ctx, rules)
+	diags.Append(listDiags...)
+	if !previous.IsNull() && !previous.IsUnknown() && sameListElements(list, previous) {
+		return previous, diags
+	}
+	return list, diags
+}
+
+// flattenExtendListenerVOs keeps previous when it holds the same listeners.
+func flattenExtendListenerVOs(ctx context.Context, vos []client.InstanceListenerVO, previous types.List) (types.List, diag.Diagnostics) {
+	listeners := make([]ExtendListenerModel, 0, len(vos))
+	for _, vo := range vos {
+		listener := ExtendListenerModel{
+			ListenerName:     types.StringPointerValue(vo.ListenerName),
+			SecurityProtocol: types.StringPointerValue(vo.SecurityProtocol),
+			Port:             types.Int64Null(),
+		}
+		if vo.Port != nil {
+			listener.Port = types.Int64Value(int64(*vo.Port))
+		}
+		listeners = append(listeners, listener)
+	}
+	list, diags := ExtendListenerModelsToList(ctx, listeners)
+	if !previous.IsNull() && !previous.IsUnknown() && sameListElements(list, previous) {
+		return previous, diags
+	}
+	return list, diags
+}
+
+// sameListElements reports whether two lists hold the same elements in any
+// order.
+func sameListElements(a, b types.List) bool {
+	if len(a.Elements()) != len(b.Elements()) {
+		return false
+	}
+	matched := make([]bool, len(b.Elements()))
+	for _, element := range a.Elements() {
+		found := false
+		for i, other := range b.Elements() {
+			if !matched[i] && element.Equal(other) {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func retainString(api *string, previous types.String) types.String {
 	if api != nil {
 		return types.StringValue(*api)
@@ -406,6 +406,10 @@ func TestKafkaInstanceReadbackPreservationContracts(t *testing.T) {
 	t.Run("file system type deserialization", testFlattenKafkaInstanceModelFileSystemTypeDeserialization)
 	t.Run("file system type state preservation", testFlattenKafkaInstanceModelFileSystemTypeStatePreservation)
 	t.Run("pricing fields preserve previous state", testFlattenKafkaInstanceModelPricingFieldsPreservePreviousState)
+	t.Run("s3 failover", testFlattenKafkaInstanceModelS3Failover)
+	t.Run("inbound rules and extended listeners", testFlattenKafkaInstanceModelListeners)
+	t.Run("cloudwatch and otlp metrics exporters", testFlattenKafkaInstanceModelMetricsExporters)
+	t.Run("disabled metrics exporters", testFlattenKafkaInstanceModelDisabledMetricsExporters)
 }
 
 func stringPtr(s string) *string {
@@ -1089,6 +1093,7 @@ func testExpandKafkaInstanceResourceTags(t *testing.T) {
 	tests := []struct {
 		name     string
 		tags     types.Map
+		tagsAll  types.Map
 		expected []client.TagParam
 	}{
 		{
@@ -1107,6 +1112,20 @@ func testExpandKafkaInstanceResourceTags(t *testing.T) {
 			tags:     types.MapNull(types.StringType),
 			expected: nil,
 		},
+		{
+			name: "tags_all with provider default tags",
+			tags: types.MapValueMust(types.StringType, map[string]attr.Value{
+				"env": types.StringValue("prod"),
+			}),
+			tagsAll: types.MapValueMust(types.StringType, map[string]attr.Value{
+				"env":         types.StringValue("prod"),
+				"cost-center": types.StringValue("cc-42"),
+			}),
+			expected: []client.TagParam{
+				{Name: "env", Value: "prod"},
+				{Name: "cost-center", Value: "cc-42"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1116,6 +1135,7 @@ func testExpandKafkaInstanceResourceTags(t *testing.T) {
 				Description: types.StringValue("test"),
 				Version:     types.StringValue("1.0.0"),
 				Tags:        tt.tags,
+				TagsAll:     tt.tagsAll,
 			}
 			request := &client.InstanceCreateParam{}
 			_ = ExpandKafkaInstanceResource(context.Background(), model, request)
@@ -1183,6 +1203,10 @@ func testFlattenKafkaInstanceModelTags(t *testing.T) {
 			} else {
 				assert.Equal(t, tt.initialTags, resource.Tags)
 			}
+			assert.False(t, resource.TagsAll.IsNull())
+			if !tt.expectedTags.IsNull() {
+				assert.Equal(t, tt.expectedTags, resource.TagsAll)
+			}
 		})
 	}
 }
@@ -1561,3 +1585,163 @@ func testFlattenKafkaInstanceModelPricingFieldsPreservePreviousState(t *testing.
 	assert.False(t, diags2.HasError())
 	assert.Equal(t, []string{"m5.xlarge"}, instanceTypes)
 }
+
+func testFlattenKafkaInstanceModelS3Failover(t *testing.T) {
+	ctx := context.Background()
+	flatten := func(vo *client.InstanceFailoverVO, previous *S3FailoverModel) *S3FailoverModel {
+		previousObject, diags := S3FailoverModelToObject(ctx, previous)
+		assert.False(t, diags.HasError())
+		resource := &KafkaInstanceResourceModel{Features: &FeaturesModel{S3Failover: previousObject}}
+		instance := &client.InstanceVO{
+			InstanceId: strPtr("test-instance"),
+			Features:   &client.InstanceFeatureVO{WalMode: strPtr("S3WAL"), S3Failover: vo},
+		}
+		assert.False(t, FlattenKafkaInstanceModel(ctx, instance, resource).HasError())
+		failover, diags := S3FailoverObjectToModel(ctx, resource.Features.S3Failover)
+		assert.False(t, diags.HasError())
+		return failover
+	}
+	size := int32(100)
+	configured := &S3FailoverModel{
+		Enabled:           types.BoolValue(true),
+		StorageType:       types.StringValue("EBS"),
+		EbsVolumeSizeInGB: types.Int64Value(100),
+	}
+
+	assert.Equal(t, configured, flatten(&client.InstanceFailoverVO{Enabled: true, StorageType: strPtr("EBS"), EbsVolumeSizeInGB: &size}, nil))
+	assert.Equal(t, configured, flatten(&client.InstanceFailoverVO{Enabled: true}, configured), "fields the API omits are kept")
+	assert.Nil(t, flatten(&client.InstanceFailoverVO{Enabled: false}, nil), "disabled failover that is not configured")
+	assert.Nil(t, flatten(nil, nil))
+
+	disabled := flatten(&client.InstanceFailoverVO{Enabled: false, StorageType: strPtr("EBS"), EbsVolumeSizeInGB: &size}, configured)
+	assert.Equal(t, types.BoolValue(false), disabled.Enabled)
+
+	param := BuildS3FailoverParam(configured)
+	assert.Equal(t, &client.InstanceFailoverParam{Enabled: boolPtr(true), StorageType: strPtr("EBS"), EbsVolumeSizeInGB: &size}, param)
+}
+
+func testFlattenKafkaInstanceModelListeners(t *testing.T) {
+	ctx := context.Background()
+	rule := func(listener string, cidrs ...string) InboundRuleModel {
+		return InboundRuleModel{ListenerName: types.StringValue(listener), Cidrs: types.SetValueMust(types.StringType, func() []attr.Value {
+			values := make([]attr.Value, len(cidrs))
+			for i, cidr := range cidrs {
+				values[i] = types.StringValue(cidr)
+			}
+			return values
+		}())}
+	}
+	previous, diags := InboundRuleModelsToList(ctx, []InboundRuleModel{rule("INTERNAL", "10.0.0.0/16", "10.1.0.0/16"), rule("EXTERNAL", "0.0.0.0/0")})
+	assert.False(t, diags.HasError())
+	port := int32(9192)
+	listeners, diags := ExtendListenerModelsToList(ctx, []ExtendListenerModel{{
+		ListenerName:     types.StringValue("EXTERNAL"),
+		SecurityProtocol: types.StringValue("SASL_SSL"),
+		Port:             types.Int64Value(9192),
+	}})
+	assert.False(t, diags.HasError())
+
+	resource := &KafkaInstanceResourceModel{Features: &FeaturesModel{InboundRules: previous, ExtendListeners: listeners}}
+	instance := &client.InstanceVO{
+		InstanceId: strPtr("test-instance"),
+		Features: &client.InstanceFeatureVO{
+			InboundRules: []client.InstanceInboundRuleVO{
+				{ListenerName: strPtr("EXTERNAL"), Cidrs: []string{"0.0.0.0/0"}},
+				{ListenerName: strPtr("INTERNAL"), Cidrs: []string{"10.1.0.0/16", "10.0.0.0/16"}},
+			},
+			ExtendListeners: []client.InstanceListenerVO{{ListenerName: strPtr("EXTERNAL"), SecurityProtocol: strPtr("SASL_SSL"), Port: &port}},
+		},
+	}
+	assert.False(t, FlattenKafkaInstanceModel(ctx, instance, resource).HasError())
+	assert.Equal(t, previous, resource.Features.InboundRules, "the configured order is kept")
+	assert.Equal(t, listeners, resource.Features.ExtendListeners)
+
+	instance.Features.InboundRules = instance.Features.InboundRules[:1]
+	assert.False(t, FlattenKafkaInstanceModel(ctx, instance, resource).HasError())
+	rules, diags := InboundRuleListToModels(ctx, resource.Features.InboundRules)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, []InboundRuleModel{rule("EXTERNAL", "0.0.0.0/0")}, rules, "changes made outside Terraform are read back")
+
+	params, diags := BuildInboundRuleParams(ctx, rules)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, []client.InboundRuleParam{{ListenerName: "EXTERNAL", Cidrs: []string{"0.0.0.0/0"}}}, params)
+}
+
+func testFlattenKafkaInstanceModelMetricsExporters(t *testing.T) {
+	ctx := context.Background()
+	previous, diags := MetricsExporterModelToObject(ctx, &MetricsExporterModel{
+		Prometheus: &PrometheusExporterModel{
+			AuthType: types.StringValue("basic"),
+			EndPoint: types.StringValue("https://prometheus.example.com/api/v1/write"),
+			Username: types.StringValue("automq"),
+			Password: types.StringValue("secret"),
+		},
+		CloudWatch: &CloudWatchExporterModel{Namespace: types.StringValue("AutoMQ/Kafka")},
+		Otlp:       &OtlpExporterModel{EndPoint: types.StringValue("https://otel.example.com:4318")},
+	})
+	assert.False(t, diags.HasError())
+
+	resource := &KafkaInstanceResourceModel{Features: &FeaturesModel{MetricsExporter: previous}}
+	instance := &client.InstanceVO{
+		InstanceId: strPtr("test-instance"),
+		Features: &client.InstanceFeatureVO{
+			MetricsExporter: &client.InstanceMetricsExporterVO{
+				Prometheus: &client.InstancePrometheusExporterVO{
+					AuthType: strPtr("basic"),
+					EndPoint: strPtr("https://prometheus.example.com/api/v1/write"),
+					Username: strPtr("automq"),
+				},
+				CloudWatch: &client.InstanceCloudWatchExporterVO{Namespace: strPtr("AutoMQ/Kafka")},
+				Oltp:       &client.InstanceOLTPExporterVO{EndPoint: strPtr("https://otel.example.com:4318")},
+			},
+		},
+	}
+	assert.False(t, FlattenKafkaInstanceModel(ctx, instance, resource).HasError())
+	assert.Equal(t, previous, resource.Features.MetricsExporter, "all exporters round-trip without drift")
+
+	instance.Features.MetricsExporter.Prometheus = nil
+	instance.Features.MetricsExporter.Oltp = nil
+	assert.False(t, FlattenKafkaInstanceModel(ctx, instance, resource).HasError())
+	metrics, diags := MetricsExporterObjectToModel(ctx, resource.Features.MetricsExporter)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, &MetricsExporterModel{CloudWatch: &CloudWatchExporterModel{Namespace: types.StringValue("AutoMQ/Kafka")}}, metrics)
+
+	exporter, ok := BuildMetricsExporterParam(metrics)
+	assert.True(t, ok)
+	assert.Equal(t, &client.InstanceMetricsExporterParam{
+		CloudWatch: &client.InstanceCloudWatchExporterParam{Enabled: boolPtr(true), Namespace: strPtr("AutoMQ/Kafka")},
+	}, exporter)
+}
+
+func testFlattenKafkaInstanceModelDisabledMetricsExporters(t *testing.T) {
+	ctx := context.Background()
+	cloudWatch := &CloudWatchExporterModel{Namespace: types.StringValue("AutoMQ/Kafka")}
+	otlp := &OtlpExporterModel{EndPoint: types.StringValue("https://otel.example.com:4318")}
+
+	// The otlp block is removed: the update disables it, and the backend
+	// still echoes its endpoint.
+	exporter, ok := BuildMetricsExporterParam(&MetricsExporterModel{CloudWatch: cloudWatch})
+	assert.True(t, ok)
+	assert.Nil(t, exporter.Oltp)
+	previous, diags := MetricsExporterModelToObject(ctx, &MetricsExporterModel{CloudWatch: cloudWatch, Otlp: otlp})
+	assert.False(t, diags.HasError())
+	resource := &KafkaInstanceResourceModel{Features: &FeaturesModel{MetricsExporter: previous}}
+	instance := &client.InstanceVO{
+		InstanceId: strPtr("test-instance"),
+		Features: &client.InstanceFeatureVO{
+			MetricsExporter: &client.InstanceMetricsExporterVO{
+				CloudWatch: &client.InstanceCloudWatchExporterVO{Enabled: boolPtr(true), Namespace: strPtr("AutoMQ/Kafka")},
+				Oltp:       &client.InstanceOLTPExporterVO{Enabled: boolPtr(false), EndPoint: strPtr("https://otel.example.com:4318")},
+			},
+		},
+	}
+	assert.False(t, FlattenKafkaInstanceModel(ctx, instance, resource).HasError())
+	metrics, diags := MetricsExporterObjectToModel(ctx, resource.Features.MetricsExporter)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, &MetricsExporterModel{CloudWatch: cloudWatch}, metrics, "the removed otlp block stays removed")
+
+	// Once every exporter is disabled the block is gone.
+	instance.Features.MetricsExporter.CloudWatch.Enabled = boolPtr(false)
+	assert.False(t, FlattenKafkaInstanceModel(ctx, instance, resource).HasError())
+	assert.True(t, resource.Features.MetricsExporter.IsNull())
+}
@@ -10,12 +10,13 @@ import (
 
 // KafkaTopicResourceModel describes the resource data model.
 type KafkaTopicResourceModel struct {
-	EnvironmentID types.String `tfsdk:"environment_id"`
-	KafkaInstance types.String `tfsdk:"kafka_instance_id"`
-	Name          types.String `tfsdk:"name"`
-	Partition     types.Int64  `tfsdk:"partition"`
-	Configs       types.Map    `tfsdk:"configs"`
-	TopicID       types.String `tfsdk:"topic_id"`
+	EnvironmentID      types.String `tfsdk:"environment_id"`
+	KafkaInstance      types.String `tfsdk:"kafka_instance_id"`
+	Name               types.String `tfsdk:"name"`
+	Partition          types.Int64  `tfsdk:"partition"`
+	Configs            types.Map    `tfsdk:"configs"`
+	TopicID            types.String `tfsdk:"topic_id"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
 }
 
 func ExpandKafkaTopicResource(topic KafkaTopicResourceModel, request *client.TopicCreateParam) {
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"flag"
+	"terraform-provider-automq/internal/fakeserver"
+)
+
+var accFake = flag.Bool("acc.fake", false, "run acceptance tests against an in-memory fake control plane instead of -acc.config")
+
+// fakeWaitScale shrinks the state waits of the provider so that the
+// asynchronous transitions of the fake control plane are observed within a
+// few milliseconds of their completion.
+const fakeWaitScale = 0.005
+
+// fakeAccConfig starts the fake control plane shared by every acceptance
+// test of the package and returns the configuration pointing at it. The
+// server lives until the test binary exits.
+func fakeAccConfig() accConfig {
+	srv := fakeserver.New()
+	return accConfig{
+		Endpoint:       srv.URL,
+		AccessKeyID:    fakeserver.AccessKeyID,
+		SecretKey:      fakeserver.SecretAccessKey,
+		EnvironmentID:  fakeserver.EnvironmentID,
+		Region:         "us-east-1",
+		Version:        "5.2.0",
+		UpgradeVersion: "5.3.0",
+		WaitScale:      fakeWaitScale,
+		Networks:       []accNetwork{{Zone: "us-east-1a", Subnets: []string{"subnet-fake-a"}}},
+		K8S: accK8SConfig{
+			ClusterID:      "eks-fake",
+			Namespace:      "automq",
+			ServiceAccount: "automq-connect",
+		},
+		Connector: accConnectorConfig{
+			PluginName:           "fake-s3-sink",
+			PluginVersion:        "1.0.0",
+			ConnectorClass:       "io.automq.fake.S3SinkConnector",
+			SecurityProtocol:     "SASL_PLAINTEXT",
+			SecurityProtocolUser: "fake-user",
+			SecurityProtocolPass: "fake-password",
+			ConnCfgTopics:        "fake-topic",
+			ConnCfgS3Region:      "us-east-1",
+			ConnCfgS3Bucket:      "fake-bucket",
+		},
+		ConnectorPlugin: accConnectorPluginConfig{
+			StorageUrl:     "s3://fake-bucket/plugins/s3-sink.zip",
+			Types:          []string{"SINK"},
+			ConnectorClass: "io.automq.fake.S3SinkConnector",
+			Version:        "1.0.0",
+		},
+	}
+}
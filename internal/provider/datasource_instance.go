@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -24,6 +25,8 @@ func NewKafkaInstanceDataSource() datasource.DataSource {
 // KafkaInstanceDataSource defines the resource implementation.
 type KafkaInstanceDataSource struct {
 	client *client.Client
+	// defaultEnvironmentID is the provider environment_id.
+	defaultEnvironmentID string
 }
 
 func (r *KafkaInstanceDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -37,8 +40,9 @@ func (r *KafkaInstanceDataSource) Schema(_ context.Context, _ datasource.SchemaR
 
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				MarkdownDescription: "Target AutoMQ BYOC environment identifier (for example, `env-xxxxx`). The environment determines the cloud provider and region. Find the ID on the AutoMQ console System Settings page.",
-				Required:            true,
+				MarkdownDescription: "Target AutoMQ BYOC environment identifier (for example, `env-xxxxx`). The environment determines the cloud provider and region. Find the ID on the AutoMQ console System Settings page. Defaults to the provider `environment_id`.",
+				Optional:            true,
+				Computed:            true,
 			},
 			"id": schema.StringAttribute{
 				Optional:            true,
@@ -176,7 +180,7 @@ func (r *KafkaInstanceDataSource) Schema(_ context.Context, _ datasource.SchemaR
 					},
 					"metrics_exporter": schema.SingleNestedAttribute{
 						Computed:            true,
-						MarkdownDescription: "Prometheus Remote Write metrics exporter configuration.",
+						MarkdownDescription: "Metrics exporter configuration.",
 						Attributes: map[string]schema.Attribute{
 							"prometheus": schema.SingleNestedAttribute{
 								Computed:            true,
@@ -215,6 +219,26 @@ func (r *KafkaInstanceDataSource) Schema(_ context.Context, _ datasource.SchemaR
 									},
 								},
 							},
+							"cloudwatch": schema.SingleNestedAttribute{
+								Computed:            true,
+								MarkdownDescription: "Amazon CloudWatch configuration for exporting metrics.",
+								Attributes: map[string]schema.Attribute{
+									"namespace": schema.StringAttribute{
+										Computed:            true,
+										MarkdownDescription: "CloudWatch namespace the metrics are published to.",
+									},
+								},
+							},
+							"otlp": schema.SingleNestedAttribute{
+								Computed:            true,
+								MarkdownDescription: "OpenTelemetry Protocol (OTLP) configuration for exporting metrics.",
+								Attributes: map[string]schema.Attribute{
+									"endpoint": schema.StringAttribute{
+										Computed:            true,
+										MarkdownDescription: "OTLP collector endpoint URL.",
+									},
+								},
+							},
 						},
 					},
 					"table_topic": schema.SingleNestedAttribute{
@@ -231,6 +255,36 @@ func (r *KafkaInstanceDataSource) Schema(_ context.Context, _ datasource.SchemaR
 							"krb5conf_file":      schema.StringAttribute{Computed: true, Sensitive: true, MarkdownDescription: "Kerberos krb5.conf file content."},
 						},
 					},
+					"inbound_rules": schema.ListNestedAttribute{
+						Computed:            true,
+						MarkdownDescription: "Client addresses allowed to connect, per listener.",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"listener_name": schema.StringAttribute{Computed: true, MarkdownDescription: "Name of the listener the rule applies to."},
+								"cidrs":         schema.SetAttribute{Computed: true, ElementType: types.StringType, MarkdownDescription: "CIDR blocks allowed to connect to the listener."},
+							},
+						},
+					},
+					"extend_listeners": schema.ListNestedAttribute{
+						Computed:            true,
+						MarkdownDescription: "Listeners added to the default listeners of the instance.",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"listener_name":     schema.StringAttribute{Computed: true, MarkdownDescription: "Name of the listener."},
+								"security_protocol": schema.StringAttribute{Computed: true, MarkdownDescription: "Kafka security protocol of the listener."},
+								"port":              schema.Int64Attribute{Computed: true, MarkdownDescription: "Port of the listener."},
+							},
+						},
+					},
+					"s3_failover": schema.SingleNestedAttribute{
+						Computed:            true,
+						MarkdownDescription: "WAL failover storage of an `S3WAL` instance.",
+						Attributes: map[string]schema.Attribute{
+							"enabled":               schema.BoolAttribute{Computed: true, MarkdownDescription: "Whether WAL failover is enabled."},
+							"storage_type":          schema.StringAttribute{Computed: true, MarkdownDescription: "Storage used for the WAL during failover."},
+							"ebs_volume_size_in_gb": schema.Int64Attribute{Computed: true, MarkdownDescription: "Size in GiB of the EBS failover volume of each broker."},
+						},
+					},
 					"schema_registry_enabled": schema.BoolAttribute{
 						Computed:            true,
 						MarkdownDescription: "Whether Schema Registry is enabled for this Kafka instance.",
@@ -312,18 +366,22 @@ func (r *KafkaInstanceDataSource) Configure(ctx context.Context, req datasource.
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *http.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *framework.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
-	r.client = client
+	r.client = data.Client
+	r.defaultEnvironmentID = data.EnvironmentID
 }
 
 func (r *KafkaInstanceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "data.automq_kafka_instance.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var config models.KafkaInstanceModel
 
 	// Read Terraform prior state data into the model
@@ -331,9 +389,21 @@ func (r *KafkaInstanceDataSource) Read(ctx context.Context, req datasource.ReadR
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	ctx = context.WithValue(ctx, client.EnvIdKey, config.EnvironmentID.ValueString())
+	environmentID := config.EnvironmentID.ValueString()
+	if config.EnvironmentID.IsNull() {
+		environmentID = r.defaultEnvironmentID
+	}
+	if environmentID == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("environment_id"),
+			"Missing Environment ID",
+			"Set environment_id on this data source, or a default environment_id in the provider block or with the AUTOMQ_ENVIRONMENT_ID environment variable.",
+		)
+		return
+	}
+	ctx = context.WithValue(ctx, client.EnvIdKey, environmentID)
 
-	instance := models.KafkaInstanceResourceModel{}
+	instance := models.KafkaInstanceResourceModel{EnvironmentID: types.StringValue(environmentID)}
 	var out *client.InstanceVO
 	var err error
 
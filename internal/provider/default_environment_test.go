@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"terraform-provider-automq/internal/framework"
+	"terraform-provider-automq/internal/models"
+)
+
+// modifyUserPlan runs ModifyPlan of automq_kafka_user for a create with the
+// given environment_id, and returns the planned one.
+func modifyUserPlan(t *testing.T, defaultEnvironmentID string, environmentID types.String) (types.String, *resource.ModifyPlanResponse) {
+	t.Helper()
+	ctx := context.Background()
+	r := NewKafkaUserResource().(*KafkaUserResource)
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &framework.ProviderData{EnvironmentID: defaultEnvironmentID}}, &resource.ConfigureResponse{})
+
+	config := models.KafkaUserResourceModel{
+		EnvironmentID:   environmentID,
+		KafkaInstanceID: types.StringValue("kf-1"),
+		Username:        types.StringValue("alice"),
+		Password:        types.StringValue("s3cret-Passw0rd"),
+		ID:              types.StringNull(),
+	}
+	plan := config
+	if environmentID.IsNull() {
+		plan.EnvironmentID = types.StringUnknown()
+	}
+	plan.ID = types.StringUnknown()
+
+	resp := modifyPlan(t, r, &config, nil, &plan)
+	var planned types.String
+	resp.Plan.GetAttribute(ctx, path.Root("environment_id"), &planned)
+	return planned, resp
+}
+
+func TestDefaultEnvironmentModifyPlan(t *testing.T) {
+	planned, resp := modifyUserPlan(t, "env-default", types.StringNull())
+	if resp.Diagnostics.HasError() || planned.ValueString() != "env-default" {
+		t.Errorf("provider default: planned %s, diagnostics %v", planned, resp.Diagnostics)
+	}
+
+	planned, resp = modifyUserPlan(t, "env-default", types.StringValue("env-own"))
+	if resp.Diagnostics.HasError() || planned.ValueString() != "env-own" {
+		t.Errorf("resource value: planned %s, diagnostics %v", planned, resp.Diagnostics)
+	}
+
+	_, resp = modifyUserPlan(t, "", types.StringNull())
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Missing Environment ID" {
+		t.Errorf("no default: diagnostics %v", resp.Diagnostics)
+	}
+}
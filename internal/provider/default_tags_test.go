@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"terraform-provider-automq/internal/framework"
+)
+
+func tagsValue(t *testing.T, tags map[string]string) types.Map {
+	t.Helper()
+	if tags == nil {
+		return types.MapNull(types.StringType)
+	}
+	value, diags := types.MapValueFrom(context.Background(), types.StringType, tags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return value
+}
+
+func TestDefaultTags(t *testing.T) {
+	ctx := context.Background()
+	r := NewConnectClusterResource().(*ConnectClusterResource)
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: &framework.ProviderData{
+		DefaultTags: map[string]string{"team": "streaming", "cost-center": "cc-42"},
+	}}, &resource.ConfigureResponse{})
+
+	merged, diags := r.MergeTags(ctx, tagsValue(t, map[string]string{"team": "payments", "app": "orders"}))
+	want := tagsValue(t, map[string]string{"team": "payments", "cost-center": "cc-42", "app": "orders"})
+	if diags.HasError() || !merged.Equal(want) {
+		t.Errorf("MergeTags = %s, %v; want %s", merged, diags, want)
+	}
+	if merged, _ = r.MergeTags(ctx, types.MapUnknown(types.StringType)); !merged.IsUnknown() {
+		t.Errorf("MergeTags of unknown tags = %s", merged)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		all      map[string]string
+		prior    map[string]string
+		priorAll map[string]string
+		want     map[string]string
+	}{
+		{
+			name:     "default tags are left out",
+			all:      map[string]string{"team": "payments", "cost-center": "cc-42", "app": "orders"},
+			prior:    map[string]string{"team": "payments", "app": "orders"},
+			priorAll: map[string]string{"team": "payments", "cost-center": "cc-42", "app": "orders"},
+			want:     map[string]string{"team": "payments", "app": "orders"},
+		},
+		{
+			name:     "configured tags with the default value are kept",
+			all:      map[string]string{"team": "streaming", "cost-center": "cc-42"},
+			prior:    map[string]string{"team": "streaming"},
+			priorAll: map[string]string{"team": "streaming", "cost-center": "cc-42"},
+			want:     map[string]string{"team": "streaming"},
+		},
+		{
+			name:     "default value changed",
+			all:      map[string]string{"team": "streaming", "cost-center": "cc-41", "app": "orders"},
+			prior:    map[string]string{"app": "orders"},
+			priorAll: map[string]string{"team": "streaming", "cost-center": "cc-41", "app": "orders"},
+			want:     map[string]string{"app": "orders"},
+		},
+		{
+			name:     "default removed",
+			all:      map[string]string{"team": "streaming", "cost-center": "cc-42", "region": "eu", "app": "orders"},
+			prior:    map[string]string{"app": "orders"},
+			priorAll: map[string]string{"team": "streaming", "cost-center": "cc-42", "region": "eu", "app": "orders"},
+			want:     map[string]string{"app": "orders"},
+		},
+		{
+			name: "import keeps tags that differ from the defaults",
+			all:  map[string]string{"team": "payments", "cost-center": "cc-42", "app": "orders"},
+			want: map[string]string{"team": "payments", "app": "orders"},
+		},
+		{
+			name: "only default tags",
+			all:  map[string]string{"team": "streaming", "cost-center": "cc-42"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, diags := r.ResourceTags(ctx, tagsValue(t, tt.all), tagsValue(t, tt.prior), tagsValue(t, tt.priorAll))
+			if want := tagsValue(t, tt.want); diags.HasError() || !tags.Equal(want) {
+				t.Errorf("ResourceTags = %s, %v; want %s", tags, diags, want)
+			}
+		})
+	}
+}
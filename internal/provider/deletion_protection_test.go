@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"terraform-provider-automq/internal/framework"
+	"terraform-provider-automq/internal/models"
+)
+
+// modifyTopicPlan runs ModifyPlan of automq_kafka_topic from state to plan;
+// a nil plan plans a destroy.
+func modifyTopicPlan(t *testing.T, state models.KafkaTopicResourceModel, plan *models.KafkaTopicResourceModel) *resource.ModifyPlanResponse {
+	t.Helper()
+	return modifyPlan(t, NewKafkaTopicResource().(*KafkaTopicResource), plan, &state, plan)
+}
+
+func TestDeletionProtectionModifyPlan(t *testing.T) {
+	topic := func(name string, partition int64, protected bool) models.KafkaTopicResourceModel {
+		return models.KafkaTopicResourceModel{
+			EnvironmentID:      types.StringValue("env-1"),
+			KafkaInstance:      types.StringValue("kf-1"),
+			Name:               types.StringValue(name),
+			Partition:          types.Int64Value(partition),
+			Configs:            types.MapNull(types.StringType),
+			TopicID:            types.StringValue("t-1"),
+			DeletionProtection: types.BoolValue(protected),
+		}
+	}
+
+	resp := modifyTopicPlan(t, topic("orders", 16, true), nil)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Deletion Protection Enabled" {
+		t.Errorf("destroy: diagnostics %v", resp.Diagnostics)
+	}
+
+	replaced := topic("payments", 16, true)
+	resp = modifyTopicPlan(t, topic("orders", 16, true), &replaced)
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("replace: no error")
+	}
+	if d, ok := resp.Diagnostics[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root("name")) {
+		t.Errorf("replace: diagnostics %v", resp.Diagnostics)
+	}
+
+	// Turning the protection off does not allow a replace in the same plan.
+	replaced = topic("payments", 16, false)
+	if resp = modifyTopicPlan(t, topic("orders", 16, true), &replaced); !resp.Diagnostics.HasError() {
+		t.Errorf("replace while turning protection off: no error")
+	}
+
+	updated := topic("orders", 32, true)
+	if resp = modifyTopicPlan(t, topic("orders", 16, true), &updated); resp.Diagnostics.HasError() {
+		t.Errorf("in-place update: diagnostics %v", resp.Diagnostics)
+	}
+
+	if resp = modifyTopicPlan(t, topic("orders", 16, false), nil); resp.Diagnostics.HasError() {
+		t.Errorf("unprotected destroy: diagnostics %v", resp.Diagnostics)
+	}
+}
+
+func TestDeletionProtectionInstanceModifyPlan(t *testing.T) {
+	ctx := context.Background()
+	s := getKafkaInstanceResourceSchema(t)
+	instance := func(zone string, nodes int64, protected bool) *models.KafkaInstanceResourceModel {
+		state := tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}
+		for p, value := range map[string]interface{}{
+			"id":                  "kf-1",
+			"environment_id":      "env-1",
+			"name":                "orders",
+			"deletion_protection": protected,
+		} {
+			if diags := state.SetAttribute(ctx, path.Root(p), value); diags.HasError() {
+				t.Fatal(diags)
+			}
+		}
+		computeSpecs := path.Root("compute_specs")
+		if diags := state.SetAttribute(ctx, computeSpecs.AtName("reserved_node_count"), nodes); diags.HasError() {
+			t.Fatal(diags)
+		}
+		networks := testNetworkList(t, []models.NetworkModel{{Zone: types.StringValue(zone), Subnets: types.ListNull(types.StringType)}})
+		if diags := state.SetAttribute(ctx, computeSpecs.AtName("networks"), networks); diags.HasError() {
+			t.Fatal(diags)
+		}
+		var model models.KafkaInstanceResourceModel
+		if diags := state.Get(ctx, &model); diags.HasError() {
+			t.Fatal(diags)
+		}
+		return &model
+	}
+	modifyInstancePlan := func(state, plan *models.KafkaInstanceResourceModel) *resource.ModifyPlanResponse {
+		return modifyPlan(t, NewKafkaInstanceResource().(*KafkaInstanceResource), plan, state, plan)
+	}
+
+	resp := modifyInstancePlan(instance("us-east-1a", 3, true), instance("us-east-1b", 3, true))
+	if !resp.Diagnostics.HasError() {
+		t.Fatalf("replace: no error")
+	}
+	zone := path.Root("compute_specs").AtName("networks").AtListIndex(0).AtName("zone")
+	if d, ok := resp.Diagnostics[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(zone) {
+		t.Errorf("replace: diagnostics %v", resp.Diagnostics)
+	}
+
+	if resp = modifyInstancePlan(instance("us-east-1a", 3, true), instance("us-east-1a", 6, true)); resp.Diagnostics.HasError() {
+		t.Errorf("in-place update: diagnostics %v", resp.Diagnostics)
+	}
+
+	if resp = modifyInstancePlan(instance("us-east-1a", 3, false), instance("us-east-1b", 3, false)); resp.Diagnostics.HasError() {
+		t.Errorf("unprotected replace: diagnostics %v", resp.Diagnostics)
+	}
+}
+
+// protectedResource is a resource with a schema of its own, for the schema
+// elements no resource of the provider uses yet.
+type protectedResource struct {
+	schema schema.Schema
+}
+
+func (r *protectedResource) Metadata(context.Context, resource.MetadataRequest, *resource.MetadataResponse) {
+}
+
+func (r *protectedResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = r.schema
+}
+
+func (r *protectedResource) Create(context.Context, resource.CreateRequest, *resource.CreateResponse) {
+}
+
+func (r *protectedResource) Read(context.Context, resource.ReadRequest, *resource.ReadResponse) {}
+
+func (r *protectedResource) Update(context.Context, resource.UpdateRequest, *resource.UpdateResponse) {
+}
+
+func (r *protectedResource) Delete(context.Context, resource.DeleteRequest, *resource.DeleteResponse) {
+}
+
+func (r *protectedResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	framework.CheckDeletionProtection(ctx, req, resp)
+}
+
+type protectedResourceModel struct {
+	DeletionProtection types.Bool `tfsdk:"deletion_protection"`
+	Listeners          types.Map  `tfsdk:"listeners"`
+	Zone               types.Set  `tfsdk:"zone"`
+}
+
+// unsupportedAttribute is an attribute kind the deletion protection check
+// does not know.
+type unsupportedAttribute struct {
+	schema.Int64Attribute
+}
+
+func TestDeletionProtectionSchemaKinds(t *testing.T) {
+	listenerType := types.ObjectType{AttrTypes: map[string]attr.Type{"port": types.Int64Type}}
+	zoneType := types.ObjectType{AttrTypes: map[string]attr.Type{"name": types.StringType}}
+	r := &protectedResource{schema: schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"deletion_protection": framework.DeletionProtectionAttribute("resource"),
+			"listeners": schema.MapNestedAttribute{
+				Optional: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"port": schema.Int64Attribute{
+							Required:      true,
+							PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+						},
+					},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"zone": schema.SetNestedBlock{
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:      true,
+							PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+						},
+					},
+				},
+			},
+		},
+	}}
+	model := func(port int64, zone string) *protectedResourceModel {
+		return &protectedResourceModel{
+			DeletionProtection: types.BoolValue(true),
+			Listeners: types.MapValueMust(listenerType, map[string]attr.Value{
+				"internal": types.ObjectValueMust(listenerType.AttrTypes, map[string]attr.Value{"port": types.Int64Value(port)}),
+			}),
+			Zone: types.SetValueMust(zoneType, []attr.Value{
+				types.ObjectValueMust(zoneType.AttrTypes, map[string]attr.Value{"name": types.StringValue(zone)}),
+			}),
+		}
+	}
+
+	if resp := modifyPlan(t, r, model(9092, "a"), model(9092, "a"), model(9092, "a")); resp.Diagnostics.HasError() {
+		t.Errorf("no change: diagnostics %v", resp.Diagnostics)
+	}
+
+	resp := modifyPlan(t, r, model(9093, "a"), model(9092, "a"), model(9093, "a"))
+	port := path.Root("listeners").AtMapKey("internal").AtName("port")
+	if d, ok := resp.Diagnostics[0].(diag.DiagnosticWithPath); !ok || d.Summary() != "Deletion Protection Enabled" || !d.Path().Equal(port) {
+		t.Errorf("map nested attribute: diagnostics %v", resp.Diagnostics)
+	}
+
+	// A new set element is not in state, the same as the framework sees it.
+	resp = modifyPlan(t, r, model(9092, "b"), model(9092, "a"), model(9092, "b"))
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Deletion Protection Enabled" {
+		t.Errorf("set nested block: diagnostics %v", resp.Diagnostics)
+	}
+
+	r.schema.Attributes["listeners"] = schema.MapNestedAttribute{
+		Optional: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{"port": unsupportedAttribute{schema.Int64Attribute{Required: true}}},
+		},
+	}
+	resp = modifyPlan(t, r, model(9092, "a"), model(9092, "a"), model(9092, "a"))
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Deletion Protection Error" {
+		t.Errorf("unsupported attribute: diagnostics %v", resp.Diagnostics)
+	}
+}
@@ -2,18 +2,27 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
 	"terraform-provider-automq/client"
+	"terraform-provider-automq/internal/framework"
 
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -40,6 +49,10 @@ type AutoMQProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+	// clientOptions are applied to the API client after the provider
+	// configuration. Acceptance tests use them to tune the client for the
+	// in-memory control plane.
+	clientOptions []func(*client.Client)
 }
 
 // autoMQProviderModel describes the provider data model.
@@ -47,6 +60,32 @@ type autoMQProviderModel struct {
 	BYOCAccessKey types.String `tfsdk:"automq_byoc_access_key_id"`
 	BYOCSecretKey types.String `tfsdk:"automq_byoc_secret_key"`
 	BYOCEndpoint  types.String `tfsdk:"automq_byoc_endpoint"`
+	EnvironmentID types.String `tfsdk:"environment_id"`
+
+	Profile               types.String `tfsdk:"profile"`
+	SharedCredentialsFile types.String `tfsdk:"shared_credentials_file"`
+	CredentialProcess     types.String `tfsdk:"credential_process"`
+
+	MaxRetries     types.Int64          `tfsdk:"max_retries"`
+	MaxBackoff     timetypes.GoDuration `tfsdk:"max_backoff"`
+	RequestTimeout timetypes.GoDuration `tfsdk:"request_timeout"`
+
+	MaxRequestsPerSecond  types.Float64 `tfsdk:"max_requests_per_second"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+
+	HTTPProxy          types.String `tfsdk:"http_proxy"`
+	CABundle           types.String `tfsdk:"ca_bundle"`
+	CABundleFile       types.String `tfsdk:"ca_bundle_file"`
+	ClientCertificate  types.String `tfsdk:"client_certificate"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+
+	DefaultTags *defaultTagsModel `tfsdk:"default_tags"`
+}
+
+// defaultTagsModel describes the default_tags block.
+type defaultTagsModel struct {
+	Tags types.Map `tfsdk:"tags"`
 }
 
 func (p *AutoMQProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -72,6 +111,87 @@ func (p *AutoMQProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 				MarkdownDescription: "Control Plane API endpoint for the installed AutoMQ BYOC environment. Obtain this endpoint after the environment installation completes.",
 				Optional:            true,
 			},
+			"environment_id": schema.StringAttribute{
+				MarkdownDescription: "Default AutoMQ BYOC environment identifier (for example, `env-xxxxx`) for resources and data sources that do not set their own `environment_id`. Can also be set with the `AUTOMQ_ENVIRONMENT_ID` environment variable. Changing it does not move existing resources: they keep the environment they were created in.",
+				Optional:            true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "Name of the profile in the shared credentials file to read Service Account credentials from. Can also be set with the `AUTOMQ_PROFILE` environment variable. Defaults to `default`. When `profile` or `shared_credentials_file` is set in the provider block, the shared credentials file is used before the credentials environment variables.",
+				Optional:            true,
+			},
+			"shared_credentials_file": schema.StringAttribute{
+				MarkdownDescription: "Path of the shared credentials file. Can also be set with the `AUTOMQ_SHARED_CREDENTIALS_FILE` environment variable. Defaults to `~/.automq/credentials`. Each INI section is a profile holding `access_key_id` and `secret_access_key`, or a `credential_process`.",
+				Optional:            true,
+			},
+			"credential_process": schema.StringAttribute{
+				MarkdownDescription: "External command that prints Service Account credentials as JSON (`{\"Version\": 1, \"AccessKeyId\": \"...\", \"SecretAccessKey\": \"...\"}`). Short-lived credentials may add `SessionToken` and an RFC 3339 `Expiration`; the command is run again shortly before they expire. When set, it is used before the credentials environment variables; otherwise it is used when no credentials are found in the configuration, environment variables or shared credentials file.",
+				Optional:            true,
+			},
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of times a failed Control Plane API request is retried. Throttled (`429`) and unavailable (`503`) responses are always retryable; server and network errors are retried only for requests that are safe to repeat. Defaults to `3`. Set to `0` to disable retries.",
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.AtLeast(0)},
+			},
+			"max_backoff": schema.StringAttribute{
+				MarkdownDescription: "Upper bound of the exponential backoff between two retries, as a Go duration string (e.g. `30s`). A `Retry-After` header returned by the Control Plane takes precedence. Defaults to `30s`.",
+				Optional:            true,
+				CustomType:          timetypes.GoDurationType{},
+			},
+			"request_timeout": schema.StringAttribute{
+				MarkdownDescription: "Timeout of a single Control Plane API request attempt, as a Go duration string (e.g. `1m`). Retries start a new attempt with a fresh timeout. Defaults to `30s`.",
+				Optional:            true,
+				CustomType:          timetypes.GoDurationType{},
+			},
+			"max_requests_per_second": schema.Float64Attribute{
+				MarkdownDescription: "Maximum rate of Control Plane API requests per environment, including retries. Requests above the rate wait for a token instead of failing. Fractional values such as `0.5` are allowed. Unlimited when unset.",
+				Optional:            true,
+				Validators:          []validator.Float64{float64validator.AtLeast(0)},
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of Control Plane API requests in flight at the same time per environment, regardless of Terraform `-parallelism`. Unlimited when unset.",
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.AtLeast(0)},
+			},
+			"http_proxy": schema.StringAttribute{
+				MarkdownDescription: "URL of the HTTP proxy used to reach the Control Plane API, e.g. `http://proxy.example.com:3128`. When unset, the `HTTPS_PROXY`, `HTTP_PROXY` and `NO_PROXY` environment variables are honored.",
+				Optional:            true,
+			},
+			"ca_bundle": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded CA certificates trusted in addition to the system roots when verifying the Control Plane API certificate. Conflicts with `ca_bundle_file`.",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.ConflictsWith(path.MatchRoot("ca_bundle_file"))},
+			},
+			"ca_bundle_file": schema.StringAttribute{
+				MarkdownDescription: "Path of a PEM file with CA certificates trusted in addition to the system roots. Conflicts with `ca_bundle`.",
+				Optional:            true,
+			},
+			"client_certificate": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded client certificate presented to the Control Plane API for mutual TLS. Requires `client_key`.",
+				Optional:            true,
+				Validators:          []validator.String{stringvalidator.AlsoRequires(path.MatchRoot("client_key"))},
+			},
+			"client_key": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded private key of `client_certificate`.",
+				Optional:            true,
+				Sensitive:           true,
+				Validators:          []validator.String{stringvalidator.AlsoRequires(path.MatchRoot("client_certificate"))},
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				MarkdownDescription: "Skip verification of the Control Plane API TLS certificate. Only use this against test environments.",
+				Optional:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"default_tags": schema.SingleNestedBlock{
+				MarkdownDescription: "Tags merged into the `tags` of every `automq_kafka_instance` and `automq_connect_cluster`. Resource-level tags take precedence over default tags with the same key. The merged tags are exported as `tags_all`.",
+				Attributes: map[string]schema.Attribute{
+					"tags": schema.MapAttribute{
+						MarkdownDescription: "Tags to add to every resource that supports them, e.g. cost allocation tags.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -111,6 +231,24 @@ func (p *AutoMQProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		)
 	}
 
+	if data.EnvironmentID.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("environment_id"),
+			"Unknown AutoMQ Environment ID",
+			"The provider cannot determine the default environment as there is an unknown configuration value for environment_id. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the AUTOMQ_ENVIRONMENT_ID environment variable.",
+		)
+	}
+
+	if data.DefaultTags != nil && hasUnknownTags(data.DefaultTags.Tags) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("default_tags").AtName("tags"),
+			"Unknown AutoMQ Default Tags",
+			"The provider cannot determine the default tags as there is an unknown configuration value in default_tags. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -119,17 +257,12 @@ func (p *AutoMQProvider) Configure(ctx context.Context, req provider.ConfigureRe
 	// with Terraform configuration value if set.
 
 	byoc_endpoint := os.Getenv("AUTOMQ_BYOC_ENDPOINT")
-	byoc_access_key := os.Getenv("AUTOMQ_BYOC_ACCESS_KEY")
-	byoc_secret_key := os.Getenv("AUTOMQ_BYOC_SECRET_KEY")
-
 	if !data.BYOCEndpoint.IsNull() {
 		byoc_endpoint = data.BYOCEndpoint.ValueString()
 	}
-	if !data.BYOCAccessKey.IsNull() {
-		byoc_access_key = data.BYOCAccessKey.ValueString()
-	}
-	if !data.BYOCSecretKey.IsNull() {
-		byoc_secret_key = data.BYOCSecretKey.ValueString()
+	environmentID := os.Getenv("AUTOMQ_ENVIRONMENT_ID")
+	if !data.EnvironmentID.IsNull() {
+		environmentID = data.EnvironmentID.ValueString()
 	}
 
 	// If any of the expected configurations are missing, return
@@ -145,26 +278,29 @@ func (p *AutoMQProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		)
 	}
 
-	if byoc_access_key == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("automq_byoc_access_key_id"),
-			"Missing AutoMQ API BYOCAccessKey",
-			"The provider cannot create the AutoMQ API client as there is a missing or empty value for the AutoMQ API byoc_access_key. "+
-				"Set the byoc_access_key value in the configuration or use the AUTOMQ_BYOC_ACCESS_KEY environment variable. "+
-				"If either is already set, ensure the value is not empty.",
-		)
-	}
-	if byoc_secret_key == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("automq_byoc_secret_key"),
-			"Missing AutoMQ API BYOCSecretKey",
-			"The provider cannot create the AutoMQ API client as there is a missing or empty value for the AutoMQ API byoc_secret_key. "+
-				"Set the byoc_secret_key value in the configuration or use the AUTOMQ_BYOC_SECRET_KEY environment variable. "+
-				"If either is already set, ensure the value is not empty.",
-		)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if resp.Diagnostics.HasError() {
+	credentials := client.NewCredentialsCache(credentialsChain(data))
+	credential, err := credentials.Retrieve(ctx)
+	if err != nil {
+		if errors.Is(err, client.ErrCredentialsNotFound) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("automq_byoc_access_key_id"),
+				"Missing AutoMQ API Credentials",
+				"The provider cannot create the AutoMQ API client as no Service Account credentials were found. "+
+					"Set automq_byoc_access_key_id and automq_byoc_secret_key in the configuration, "+
+					"use the AUTOMQ_BYOC_ACCESS_KEY and AUTOMQ_BYOC_SECRET_KEY environment variables, "+
+					"add a profile to the shared credentials file (~/.automq/credentials), or set credential_process.\n\n"+
+					err.Error(),
+			)
+		} else {
+			resp.Diagnostics.AddError(
+				"Unable to Load AutoMQ API Credentials",
+				"An error occurred while loading the Service Account credentials: "+err.Error(),
+			)
+		}
 		return
 	}
 
@@ -172,11 +308,6 @@ func (p *AutoMQProvider) Configure(ctx context.Context, req provider.ConfigureRe
 
 	tflog.Debug(ctx, "Creating AutoMQ client")
 
-	credential := client.AuthCredentials{
-		AccessKeyID:     byoc_access_key,
-		SecretAccessKey: byoc_secret_key,
-	}
-
 	parsedURL, err := url.Parse(byoc_endpoint)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -188,7 +319,15 @@ func (p *AutoMQProvider) Configure(ctx context.Context, req provider.ConfigureRe
 	trimmedPath := strings.TrimRight(parsedURL.Path, "/")
 	baseURL := fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, trimmedPath)
 
-	client, err := client.NewClient(ctx, baseURL, credential)
+	options, diags := clientOptions(data)
+	resp.Diagnostics.Append(diags...)
+	options = append(options, func(c *client.Client) { c.CredentialsProvider = credentials })
+	options = append(options, p.clientOptions...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, err := client.NewClient(ctx, baseURL, credential, options...)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Create AutoMQ API Client",
@@ -201,12 +340,137 @@ func (p *AutoMQProvider) Configure(ctx context.Context, req provider.ConfigureRe
 
 	// Make the AutoMQ client available during DataSource and Resource
 	// type Configure methods.
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	providerData := &framework.ProviderData{Client: client, EnvironmentID: environmentID}
+	if data.DefaultTags != nil && !data.DefaultTags.Tags.IsNull() {
+		resp.Diagnostics.Append(data.DefaultTags.Tags.ElementsAs(ctx, &providerData.DefaultTags, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
 
 	tflog.Info(ctx, "Configured AutoMQ client", map[string]any{"success": true})
 }
 
+// hasUnknownTags reports whether tags or any of its values is unknown.
+func hasUnknownTags(tags types.Map) bool {
+	if tags.IsUnknown() {
+		return true
+	}
+	for _, value := range tags.Elements() {
+		if value.IsUnknown() {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialsChain resolves Service Account credentials in order from the
+// provider block, the environment, the shared credentials file and finally
+// credential_process. Keys set in the provider block fall back to the
+// environment individually, as they always have. A shared credentials file,
+// profile or credential_process set in the provider block is explicit
+// configuration too, and comes before the environment.
+func credentialsChain(data autoMQProviderModel) client.CredentialsProvider {
+	var providers []client.CredentialsProvider
+	if !data.BYOCAccessKey.IsNull() || !data.BYOCSecretKey.IsNull() {
+		static := client.AuthCredentials{
+			AccessKeyID:     os.Getenv(client.EnvAccessKey),
+			SecretAccessKey: os.Getenv(client.EnvSecretKey),
+		}
+		if static.AccessKeyID == "" {
+			static.AccessKeyID = os.Getenv(client.EnvAccessKeyID)
+		}
+		if !data.BYOCAccessKey.IsNull() {
+			static.AccessKeyID = data.BYOCAccessKey.ValueString()
+		}
+		if !data.BYOCSecretKey.IsNull() {
+			static.SecretAccessKey = data.BYOCSecretKey.ValueString()
+		}
+		providers = append(providers, client.StaticCredentialsProvider{Credentials: static})
+	}
+	var configured, fallback []client.CredentialsProvider
+	shared := client.SharedCredentialsProvider{
+		Filename: data.SharedCredentialsFile.ValueString(),
+		Profile:  data.Profile.ValueString(),
+	}
+	if !data.SharedCredentialsFile.IsNull() || !data.Profile.IsNull() {
+		configured = append(configured, shared)
+	} else {
+		fallback = append(fallback, shared)
+	}
+	process := client.ProcessCredentialsProvider{Command: data.CredentialProcess.ValueString()}
+	if !data.CredentialProcess.IsNull() {
+		configured = append(configured, process)
+	} else {
+		fallback = append(fallback, process)
+	}
+	providers = append(providers, configured...)
+	providers = append(providers, client.EnvCredentialsProvider{})
+	providers = append(providers, fallback...)
+	return client.ChainCredentialsProvider{Providers: providers}
+}
+
+// clientOptions translates the optional retry, timeout, throttling and
+// transport settings of the provider block into client options. Unset attributes keep client defaults.
+func clientOptions(data autoMQProviderModel) ([]func(*client.Client), diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var options []func(*client.Client)
+
+	if !data.MaxRetries.IsNull() && !data.MaxRetries.IsUnknown() {
+		maxRetries := int(data.MaxRetries.ValueInt64())
+		options = append(options, func(c *client.Client) { c.MaxRetries = maxRetries })
+	}
+	if !data.MaxBackoff.IsNull() && !data.MaxBackoff.IsUnknown() {
+		maxBackoff, d := data.MaxBackoff.ValueGoDuration()
+		diags.Append(d...)
+		if maxBackoff <= 0 {
+			diags.AddAttributeError(path.Root("max_backoff"), "Invalid Max Backoff", "max_backoff must be a positive duration.")
+		}
+		options = append(options, func(c *client.Client) { c.MaxBackoff = maxBackoff })
+	}
+	if !data.RequestTimeout.IsNull() && !data.RequestTimeout.IsUnknown() {
+		requestTimeout, d := data.RequestTimeout.ValueGoDuration()
+		diags.Append(d...)
+		if requestTimeout <= 0 {
+			diags.AddAttributeError(path.Root("request_timeout"), "Invalid Request Timeout", "request_timeout must be a positive duration.")
+		}
+		options = append(options, func(c *client.Client) { c.RequestTimeout = requestTimeout })
+	}
+	if !data.MaxRequestsPerSecond.IsNull() && !data.MaxRequestsPerSecond.IsUnknown() {
+		requestsPerSecond := data.MaxRequestsPerSecond.ValueFloat64()
+		options = append(options, func(c *client.Client) { c.MaxRequestsPerSecond = requestsPerSecond })
+	}
+	if !data.MaxConcurrentRequests.IsNull() && !data.MaxConcurrentRequests.IsUnknown() {
+		concurrentRequests := int(data.MaxConcurrentRequests.ValueInt64())
+		options = append(options, func(c *client.Client) { c.MaxConcurrentRequests = concurrentRequests })
+	}
+
+	var transport http.RoundTripper
+	transportConfig := client.TransportConfig{
+		ProxyURL:           data.HTTPProxy.ValueString(),
+		CABundle:           []byte(data.CABundle.ValueString()),
+		CABundleFile:       data.CABundleFile.ValueString(),
+		ClientCertificate:  []byte(data.ClientCertificate.ValueString()),
+		ClientKey:          []byte(data.ClientKey.ValueString()),
+		InsecureSkipVerify: data.InsecureSkipVerify.ValueBool(),
+	}
+	if transportConfig.ProxyURL != "" || len(transportConfig.CABundle) > 0 || transportConfig.CABundleFile != "" ||
+		len(transportConfig.ClientCertificate) > 0 || transportConfig.InsecureSkipVerify {
+		custom, err := client.NewTransport(transportConfig)
+		if err != nil {
+			diags.AddError("Invalid AutoMQ API Transport Configuration", err.Error())
+		} else {
+			transport = custom
+		}
+	}
+	// Requests and responses are logged to the automq_http subsystem, which
+	// is silent unless TF_LOG or TF_LOG_PROVIDER_AUTOMQ_HTTP enables DEBUG.
+	options = append(options, func(c *client.Client) { c.HTTPClient.Transport = client.NewLoggingTransport(transport) })
+	return options, diags
+}
+
 func (p *AutoMQProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewKafkaInstanceResource,
@@ -4,10 +4,19 @@
 package provider
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"terraform-provider-automq/client"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -15,9 +24,76 @@ import (
 // CLI command executed to create a provider server to which the CLI can
 // reattach.
 var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"automq": providerserver.NewProtocol6WithError(New("test")()),
+	"automq": func() (tfprotov6.ProviderServer, error) {
+		p := &AutoMQProvider{version: "test"}
+		if configData.WaitScale > 0 {
+			p.clientOptions = append(p.clientOptions, func(c *client.Client) { c.WaitScale = configData.WaitScale })
+		}
+		return providerserver.NewProtocol6WithError(p)()
+	},
 }
 
 func testAccPreCheck(t *testing.T) {
 	loadAccConfig(t)
 }
+
+// modifyPlan runs ModifyPlan of r. config, state and plan are pointers to
+// resource models, nil for a null value: a nil state plans a create and a nil
+// plan a destroy.
+func modifyPlan(t *testing.T, r resource.ResourceWithModifyPlan, config, state, plan interface{}) *resource.ModifyPlanResponse {
+	t.Helper()
+	ctx := context.Background()
+	schemaResp := resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	s := schemaResp.Schema
+
+	value := func(model interface{}) tftypes.Value {
+		v := tfsdk.Plan{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}
+		if model != nil && !reflect.ValueOf(model).IsNil() {
+			if diags := v.Set(ctx, model); diags.HasError() {
+				t.Fatal(diags)
+			}
+		}
+		return v.Raw
+	}
+	resp := &resource.ModifyPlanResponse{Plan: tfsdk.Plan{Schema: s, Raw: value(plan)}}
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: s, Raw: value(config)},
+		Plan:   resp.Plan,
+		State:  tfsdk.State{Schema: s, Raw: value(state)},
+	}, resp)
+	return resp
+}
+
+func TestCredentialsChainPrefersConfiguredSources(t *testing.T) {
+	t.Setenv(client.EnvAccessKey, "env-ak")
+	t.Setenv(client.EnvSecretKey, "env-sk")
+	t.Setenv(client.EnvProfile, "")
+	filename := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(filename, []byte("[prod]\naccess_key_id = file-ak\nsecret_access_key = file-sk\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(client.EnvSharedCredentialsFile, filename)
+
+	for _, tt := range []struct {
+		name string
+		data autoMQProviderModel
+		want string
+	}{
+		{name: "nothing configured", want: "env-ak"},
+		{name: "profile", data: autoMQProviderModel{Profile: types.StringValue("prod")}, want: "file-ak"},
+		{name: "shared credentials file", data: autoMQProviderModel{SharedCredentialsFile: types.StringValue(filename), Profile: types.StringValue("prod")}, want: "file-ak"},
+		{name: "credential_process", data: autoMQProviderModel{CredentialProcess: types.StringValue(`echo '{"Version": 1, "AccessKeyId": "process-ak", "SecretAccessKey": "process-sk"}'`)}, want: "process-ak"},
+		{name: "keys", data: autoMQProviderModel{BYOCAccessKey: types.StringValue("static-ak"), BYOCSecretKey: types.StringValue("static-sk"), Profile: types.StringValue("prod")}, want: "static-ak"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := credentialsChain(tt.data).Retrieve(context.Background())
+			if err != nil {
+				t.Fatalf("Retrieve() error = %v", err)
+			}
+			if creds.AccessKeyID != tt.want {
+				t.Errorf("AccessKeyID = %q, want %q", creds.AccessKeyID, tt.want)
+			}
+		})
+	}
+}
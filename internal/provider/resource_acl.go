@@ -21,6 +21,7 @@ import (
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &KafkaAclResource{}
+var _ resource.ResourceWithModifyPlan = &KafkaAclResource{}
 
 func NewKafkaAclResource() resource.Resource {
 	return &KafkaAclResource{}
@@ -29,6 +30,7 @@ func NewKafkaAclResource() resource.Resource {
 // KafkaAclResource defines the resource implementation.
 type KafkaAclResource struct {
 	client *client.Client
+	framework.WithDefaultEnvironment
 }
 
 func (r *KafkaAclResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -45,9 +47,10 @@ func (r *KafkaAclResource) Schema(ctx context.Context, req resource.SchemaReques
 
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page.",
-				Required:            true,
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page. Defaults to the provider `environment_id`.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"kafka_instance_id": schema.StringAttribute{
 				MarkdownDescription: "Target Kafka instance ID (e.g. `kf-xxxxx`). Each instance represents a Kafka cluster. Find this on the AutoMQ console instance list or detail page.",
@@ -113,18 +116,22 @@ func (r *KafkaAclResource) Configure(ctx context.Context, req resource.Configure
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *http.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *framework.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
-	r.client = client
+	r.client = data.Client
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
 }
 
 func (r *KafkaAclResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_acl.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan models.KafkaAclResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -149,7 +156,7 @@ func (r *KafkaAclResource) Create(ctx context.Context, req resource.CreateReques
 	in := client.KafkaAclBindingParams{Params: []client.KafkaAclBindingParam{param}}
 	out, err := r.client.CreateKafkaAcls(ctx, instance, in)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to Create Kafka ACL", err.Error())
+		framework.AddAPIError(&resp.Diagnostics, "Failed to Create Kafka ACL", err.Error(), err, nil)
 		return
 	}
 	// flatten the response and set the ID to the state
@@ -163,6 +170,9 @@ func (r *KafkaAclResource) Create(ctx context.Context, req resource.CreateReques
 }
 
 func (r *KafkaAclResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_acl.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaAclResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -193,9 +203,15 @@ func (r *KafkaAclResource) Read(ctx context.Context, req resource.ReadRequest, r
 }
 
 func (r *KafkaAclResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_acl.Update")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 }
 
 func (r *KafkaAclResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_acl.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var data models.KafkaAclResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
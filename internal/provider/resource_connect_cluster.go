@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"terraform-provider-automq/client"
 	"terraform-provider-automq/internal/framework"
@@ -13,6 +14,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -41,6 +43,7 @@ var (
 	_ resource.Resource                = &ConnectClusterResource{}
 	_ resource.ResourceWithConfigure   = &ConnectClusterResource{}
 	_ resource.ResourceWithImportState = &ConnectClusterResource{}
+	_ resource.ResourceWithModifyPlan  = &ConnectClusterResource{}
 )
 
 func NewConnectClusterResource() resource.Resource {
@@ -55,6 +58,8 @@ type ConnectClusterResource struct {
 	client *client.Client
 	api    connectClusterAPI
 	framework.WithTimeouts
+	framework.WithDefaultEnvironment
+	framework.WithDefaultTags
 }
 
 type connectClusterAPI interface {
@@ -87,20 +92,28 @@ func (r *ConnectClusterResource) Configure(_ context.Context, req resource.Confi
 	if req.ProviderData == nil {
 		return
 	}
-	c, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected Provider Data", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected Provider Data", fmt.Sprintf("Expected *framework.ProviderData, got %T", req.ProviderData))
 		return
 	}
-	r.client = c
-	r.api = defaultConnectClusterAPI{client: c}
+	r.client = data.Client
+	r.api = defaultConnectClusterAPI{client: data.Client}
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
+	r.SetDefaultTags(data.DefaultTags)
+}
+
+func (r *ConnectClusterResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.WithDefaultEnvironment.ModifyPlan(ctx, req, resp)
+	r.WithDefaultTags.ModifyPlan(ctx, req, resp)
+	framework.CheckDeletionProtection(ctx, req, resp)
 }
 
 func (r *ConnectClusterResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "`automq_connect_cluster` manages a Kafka Connect Worker cluster.",
 		Attributes: map[string]schema.Attribute{
-			"environment_id": schema.StringAttribute{Required: true, Description: "AutoMQ environment ID that owns the Connect Cluster, for example `env-xxxxx`. Changing it creates a new Connect Cluster.", PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()}},
+			"environment_id": schema.StringAttribute{Optional: true, Computed: true, Description: "AutoMQ environment ID that owns the Connect Cluster, for example `env-xxxxx`. Changing it creates a new Connect Cluster. Defaults to the provider `environment_id`.", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()}},
 			"id":             schema.StringAttribute{Computed: true, Description: "Connect Cluster ID assigned by AutoMQ, for example `connect-cluster-xxxxx`.", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
 			"name":           schema.StringAttribute{Required: true, Description: "Connect Cluster name shown in AutoMQ. It must be 3 to 64 characters.", Validators: []validator.String{stringvalidator.LengthBetween(3, 64)}},
 			"description":    schema.StringAttribute{Optional: true, Description: "Optional human-readable description for the Connect Cluster."},
@@ -177,11 +190,13 @@ func (r *ConnectClusterResource) Schema(ctx context.Context, _ resource.SchemaRe
 					"iam_role": schema.StringAttribute{Optional: true, Description: "Cloud IAM role used by Connect workers to access external services such as object storage. In AWS K8s deployments this is the IRSA role. Changing it creates a new Connect Cluster.", PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()}},
 				},
 			},
-			"worker_config":   schema.MapAttribute{ElementType: types.StringType, Optional: true, Computed: true, Description: "Worker-level Kafka Connect configuration overrides, such as converters or offset flush settings. Do not put plugin-specific connector configuration here; use `automq_connector.connector_config` instead.", PlanModifiers: []planmodifier.Map{mapplanmodifier.UseStateForUnknown()}},
-			"metric_exporter": metricExporterSchema(),
-			"tags":            schema.MapAttribute{ElementType: types.StringType, Optional: true, Computed: true, Description: "User-defined tags for organizing Connect Clusters. AutoMQ may also return system tags.", PlanModifiers: []planmodifier.Map{mapplanmodifier.UseStateForUnknown()}},
-			"version":         schema.StringAttribute{Optional: true, Computed: true, Description: "AutoMQ Connect worker version. If omitted, AutoMQ selects the backend default version.", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
-			"state":           schema.StringAttribute{Computed: true, Description: "Connect Cluster lifecycle state reported by AutoMQ, such as `RUNNING`, `CHANGING`, `FAILED`, or `DELETING`.", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
+			"worker_config":       schema.MapAttribute{ElementType: types.StringType, Optional: true, Computed: true, Description: "Worker-level Kafka Connect configuration overrides, such as converters or offset flush settings. Do not put plugin-specific connector configuration here; use `automq_connector.connector_config` instead.", PlanModifiers: []planmodifier.Map{mapplanmodifier.UseStateForUnknown()}},
+			"metric_exporter":     metricExporterSchema(),
+			"tags":                schema.MapAttribute{ElementType: types.StringType, Optional: true, Computed: true, Description: "User-defined tags for organizing Connect Clusters. AutoMQ may also return system tags. Tags with the same key as a provider `default_tags` tag take precedence.", PlanModifiers: []planmodifier.Map{mapplanmodifier.UseStateForUnknown()}},
+			"tags_all":            schema.MapAttribute{ElementType: types.StringType, Computed: true, Description: "All tags of the Connect Cluster: `tags` merged with the provider `default_tags`."},
+			"deletion_protection": framework.DeletionProtectionAttribute("Connect Cluster"),
+			"version":             schema.StringAttribute{Optional: true, Computed: true, Description: "AutoMQ Connect worker version. If omitted, AutoMQ selects the backend default version.", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
+			"state":               schema.StringAttribute{Computed: true, Description: "Connect Cluster lifecycle state reported by AutoMQ, such as `RUNNING`, `CHANGING`, `FAILED`, or `DELETING`.", PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()}},
 			"kafka_connect_version": schema.StringAttribute{
 				Computed:      true,
 				Description:   "Kafka Connect framework version used by the worker runtime.",
@@ -221,6 +236,9 @@ func metricExporterSchema() schema.SingleNestedAttribute {
 }
 
 func (r *ConnectClusterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connect_cluster.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan models.ConnectClusterResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -235,7 +253,7 @@ func (r *ConnectClusterResource) Create(ctx context.Context, req resource.Create
 	}
 	created, err := r.api.CreateConnectCluster(ctx, *request)
 	if err != nil {
-		resp.Diagnostics.AddError("Create Connect Cluster Error", fmt.Sprintf("Unable to create connect cluster: %s", err))
+		framework.AddAPIError(&resp.Diagnostics, "Create Connect Cluster Error", fmt.Sprintf("Unable to create connect cluster: %s", err), err, nil)
 		return
 	}
 	clusterID := derefString(created.Id)
@@ -249,7 +267,7 @@ func (r *ConnectClusterResource) Create(ctx context.Context, req resource.Create
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if err := waitForConnectClusterReady(ctx, r.api, clusterID, r.CreateTimeout(ctx, plan.Timeouts)); err != nil {
+	if err := waitForConnectClusterReady(ctx, r.client, r.api, clusterID, r.CreateTimeout(ctx, plan.Timeouts)); err != nil {
 		resp.Diagnostics.AddError("Connect Cluster Provisioning Error", err.Error())
 		return
 	}
@@ -258,7 +276,7 @@ func (r *ConnectClusterResource) Create(ctx context.Context, req resource.Create
 		resp.Diagnostics.AddError("Read Connect Cluster Error", fmt.Sprintf("Unable to read connect cluster %q after create: %s", clusterID, err))
 		return
 	}
-	resp.Diagnostics.Append(models.FlattenConnectCluster(latest, &plan)...)
+	resp.Diagnostics.Append(r.flattenConnectCluster(ctx, latest, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -266,6 +284,9 @@ func (r *ConnectClusterResource) Create(ctx context.Context, req resource.Create
 }
 
 func (r *ConnectClusterResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connect_cluster.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.ConnectClusterResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -282,7 +303,7 @@ func (r *ConnectClusterResource) Read(ctx context.Context, req resource.ReadRequ
 		resp.Diagnostics.AddError("Read Connect Cluster Error", fmt.Sprintf("Unable to read connect cluster %q: %s", state.ID.ValueString(), err))
 		return
 	}
-	resp.Diagnostics.Append(models.FlattenConnectCluster(cluster, &state)...)
+	resp.Diagnostics.Append(r.flattenConnectCluster(ctx, cluster, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -290,6 +311,9 @@ func (r *ConnectClusterResource) Read(ctx context.Context, req resource.ReadRequ
 }
 
 func (r *ConnectClusterResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connect_cluster.Update")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan, state models.ConnectClusterResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -301,14 +325,21 @@ func (r *ConnectClusterResource) Update(ctx context.Context, req resource.Update
 
 	request, diags := models.ExpandConnectClusterUpdate(plan)
 	resp.Diagnostics.Append(diags...)
+	prior, diags := models.ExpandConnectClusterUpdate(state)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	// Changes to Terraform-only attributes, such as deletion_protection or
+	// timeouts, need no backend call; the framework saves the plan.
+	if reflect.DeepEqual(request, prior) {
+		return
+	}
 	if _, err := r.api.UpdateConnectCluster(ctx, clusterID, *request); err != nil {
-		resp.Diagnostics.AddError("Update Connect Cluster Error", fmt.Sprintf("Unable to update connect cluster %q: %s", clusterID, err))
+		framework.AddAPIError(&resp.Diagnostics, "Update Connect Cluster Error", fmt.Sprintf("Unable to update connect cluster %q: %s", clusterID, err), err, nil)
 		return
 	}
-	if err := waitForConnectClusterReady(ctx, r.api, clusterID, r.UpdateTimeout(ctx, plan.Timeouts)); err != nil {
+	if err := waitForConnectClusterReady(ctx, r.client, r.api, clusterID, r.UpdateTimeout(ctx, plan.Timeouts)); err != nil {
 		resp.Diagnostics.AddError("Connect Cluster Update Error", err.Error())
 		return
 	}
@@ -317,7 +348,7 @@ func (r *ConnectClusterResource) Update(ctx context.Context, req resource.Update
 		resp.Diagnostics.AddError("Read Connect Cluster Error", fmt.Sprintf("Unable to refresh connect cluster %q: %s", clusterID, err))
 		return
 	}
-	resp.Diagnostics.Append(models.FlattenConnectCluster(latest, &plan)...)
+	resp.Diagnostics.Append(r.flattenConnectCluster(ctx, latest, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -325,8 +356,12 @@ func (r *ConnectClusterResource) Update(ctx context.Context, req resource.Update
 }
 
 func (r *ConnectClusterResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connect_cluster.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.ConnectClusterResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(framework.DeletionProtectionError(state.DeletionProtection)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -339,7 +374,7 @@ func (r *ConnectClusterResource) Delete(ctx context.Context, req resource.Delete
 		resp.Diagnostics.AddError("Delete Connect Cluster Error", fmt.Sprintf("Unable to delete connect cluster %q: %s", clusterID, err))
 		return
 	}
-	if err := waitForConnectClusterDeletion(ctx, r.api, clusterID, r.DeleteTimeout(ctx, state.Timeouts)); err != nil {
+	if err := waitForConnectClusterDeletion(ctx, r.client, r.api, clusterID, r.DeleteTimeout(ctx, state.Timeouts)); err != nil {
 		resp.Diagnostics.AddError("Connect Cluster Delete Error", err.Error())
 	}
 }
@@ -354,27 +389,45 @@ func (r *ConnectClusterResource) ImportState(ctx context.Context, req resource.I
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), parts[1])...)
 }
 
-func waitForConnectClusterReady(ctx context.Context, api connectClusterAPI, id string, timeout time.Duration) error {
+// flattenConnectCluster flattens vo into model, leaving the provider default
+// tags out of tags. Imported clusters and states written before
+// deletion_protection existed get it disabled.
+func (r *ConnectClusterResource) flattenConnectCluster(ctx context.Context, vo *client.ConnectClusterVO, model *models.ConnectClusterResourceModel) diag.Diagnostics {
+	priorTags, priorTagsAll := model.Tags, model.TagsAll
+	diags := models.FlattenConnectCluster(vo, model)
+	if diags.HasError() {
+		return diags
+	}
+	tags, tagsDiags := r.ResourceTags(ctx, model.TagsAll, priorTags, priorTagsAll)
+	diags.Append(tagsDiags...)
+	model.Tags = tags
+	if model.DeletionProtection.IsNull() {
+		model.DeletionProtection = types.BoolValue(false)
+	}
+	return diags
+}
+
+func waitForConnectClusterReady(ctx context.Context, c *client.Client, api connectClusterAPI, id string, timeout time.Duration) error {
 	conf := &retry.StateChangeConf{
 		Pending:      []string{client.ConnectClusterStateCreating, client.ConnectClusterStateChanging, client.ConnectClusterStateUnknown},
 		Target:       []string{client.ConnectClusterStateRunning},
 		Refresh:      connectClusterStatusFunc(ctx, api, id),
 		Timeout:      timeout,
-		Delay:        10 * time.Second,
-		PollInterval: 15 * time.Second,
+		Delay:        framework.ScaleWait(c, 10*time.Second),
+		PollInterval: framework.ScaleWait(c, 15*time.Second),
 	}
 	_, err := conf.WaitForStateContext(ctx)
 	return err
 }
 
-func waitForConnectClusterDeletion(ctx context.Context, api connectClusterAPI, id string, timeout time.Duration) error {
+func waitForConnectClusterDeletion(ctx context.Context, c *client.Client, api connectClusterAPI, id string, timeout time.Duration) error {
 	conf := &retry.StateChangeConf{
 		Pending:      []string{client.ConnectClusterStateDeleting, client.ConnectClusterStateChanging, client.ConnectClusterStateUnknown},
 		Target:       []string{client.ConnectClusterStateDeleted},
 		Refresh:      connectClusterStatusFunc(ctx, api, id),
 		Timeout:      timeout,
-		Delay:        5 * time.Second,
-		PollInterval: 10 * time.Second,
+		Delay:        framework.ScaleWait(c, 5*time.Second),
+		PollInterval: framework.ScaleWait(c, 10*time.Second),
 	}
 	_, err := conf.WaitForStateContext(ctx)
 	return err
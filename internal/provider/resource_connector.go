@@ -39,6 +39,7 @@ var (
 	_ resource.Resource                = &ConnectorResource{}
 	_ resource.ResourceWithConfigure   = &ConnectorResource{}
 	_ resource.ResourceWithImportState = &ConnectorResource{}
+	_ resource.ResourceWithModifyPlan  = &ConnectorResource{}
 )
 
 func NewConnectorResource() resource.Resource {
@@ -53,6 +54,7 @@ type ConnectorResource struct {
 	client *client.Client
 	api    connectorAPI
 	framework.WithTimeouts
+	framework.WithDefaultEnvironment
 }
 
 type connectorAPI interface {
@@ -85,13 +87,14 @@ func (r *ConnectorResource) Configure(_ context.Context, req resource.ConfigureR
 	if req.ProviderData == nil {
 		return
 	}
-	c, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected Provider Data", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected Provider Data", fmt.Sprintf("Expected *framework.ProviderData, got %T", req.ProviderData))
 		return
 	}
-	r.client = c
-	r.api = defaultConnectorAPI{client: c}
+	r.client = data.Client
+	r.api = defaultConnectorAPI{client: data.Client}
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
 }
 
 func (r *ConnectorResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
@@ -99,9 +102,10 @@ func (r *ConnectorResource) Schema(ctx context.Context, _ resource.SchemaRequest
 		MarkdownDescription: "`automq_connector` manages a Kafka Connector instance on an existing AutoMQ Connect cluster.",
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				Required:      true,
-				Description:   "AutoMQ environment ID that owns the Connect resources, for example `env-xxxxx`. Changing it creates a new connector.",
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Optional:      true,
+				Computed:      true,
+				Description:   "AutoMQ environment ID that owns the Connect resources, for example `env-xxxxx`. Changing it creates a new connector. Defaults to the provider `environment_id`.",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"id": schema.StringAttribute{
 				Computed:      true,
@@ -218,6 +222,9 @@ func (r *ConnectorResource) Schema(ctx context.Context, _ resource.SchemaRequest
 }
 
 func (r *ConnectorResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connector.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan models.ConnectorResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -233,7 +240,10 @@ func (r *ConnectorResource) Create(ctx context.Context, req resource.CreateReque
 
 	created, err := r.api.CreateConnector(ctx, *request)
 	if err != nil {
-		resp.Diagnostics.AddError("Create Connector Error", fmt.Sprintf("Unable to create connector: %s", err))
+		framework.AddAPIError(&resp.Diagnostics, "Create Connector Error", fmt.Sprintf("Unable to create connector: %s", err), err, framework.APIErrorAttributes{
+			client.ErrConflict:         path.Root("name"),
+			client.ErrInvalidParameter: path.Root("connector_config"),
+		})
 		return
 	}
 	connectorID := derefString(created.Id)
@@ -247,7 +257,7 @@ func (r *ConnectorResource) Create(ctx context.Context, req resource.CreateReque
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if err := waitForConnectorReady(ctx, r.api, connectorID, r.CreateTimeout(ctx, plan.Timeouts)); err != nil {
+	if err := waitForConnectorReady(ctx, r.client, r.api, connectorID, r.CreateTimeout(ctx, plan.Timeouts)); err != nil {
 		resp.Diagnostics.AddError("Connector Provisioning Error", err.Error())
 		return
 	}
@@ -264,6 +274,9 @@ func (r *ConnectorResource) Create(ctx context.Context, req resource.CreateReque
 }
 
 func (r *ConnectorResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connector.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.ConnectorResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -288,6 +301,9 @@ func (r *ConnectorResource) Read(ctx context.Context, req resource.ReadRequest,
 }
 
 func (r *ConnectorResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connector.Update")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan, state models.ConnectorResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -303,10 +319,12 @@ func (r *ConnectorResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 	if _, err := r.api.UpdateConnector(ctx, connectorID, *request); err != nil {
-		resp.Diagnostics.AddError("Update Connector Error", fmt.Sprintf("Unable to update connector %q: %s", connectorID, err))
+		framework.AddAPIError(&resp.Diagnostics, "Update Connector Error", fmt.Sprintf("Unable to update connector %q: %s", connectorID, err), err, framework.APIErrorAttributes{
+			client.ErrInvalidParameter: path.Root("connector_config"),
+		})
 		return
 	}
-	if err := waitForConnectorReady(ctx, r.api, connectorID, r.UpdateTimeout(ctx, plan.Timeouts)); err != nil {
+	if err := waitForConnectorReady(ctx, r.client, r.api, connectorID, r.UpdateTimeout(ctx, plan.Timeouts)); err != nil {
 		resp.Diagnostics.AddError("Connector Update Error", err.Error())
 		return
 	}
@@ -323,6 +341,9 @@ func (r *ConnectorResource) Update(ctx context.Context, req resource.UpdateReque
 }
 
 func (r *ConnectorResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connector.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.ConnectorResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -338,7 +359,7 @@ func (r *ConnectorResource) Delete(ctx context.Context, req resource.DeleteReque
 		resp.Diagnostics.AddError("Delete Connector Error", fmt.Sprintf("Unable to delete connector %q: %s", connectorID, err))
 		return
 	}
-	if err := waitForConnectorDeletion(ctx, r.api, connectorID, r.DeleteTimeout(ctx, state.Timeouts)); err != nil {
+	if err := waitForConnectorDeletion(ctx, r.client, r.api, connectorID, r.DeleteTimeout(ctx, state.Timeouts)); err != nil {
 		resp.Diagnostics.AddError("Connector Delete Error", err.Error())
 	}
 }
@@ -353,27 +374,27 @@ func (r *ConnectorResource) ImportState(ctx context.Context, req resource.Import
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), parts[1])...)
 }
 
-func waitForConnectorReady(ctx context.Context, api connectorAPI, id string, timeout time.Duration) error {
+func waitForConnectorReady(ctx context.Context, c *client.Client, api connectorAPI, id string, timeout time.Duration) error {
 	conf := &retry.StateChangeConf{
 		Pending:      []string{client.ConnectorStateCreating, client.ConnectorStateChanging, client.ConnectorStateUnknown},
 		Target:       []string{client.ConnectorStateRunning, client.ConnectorStatePaused},
 		Refresh:      connectorStatusFunc(ctx, api, id),
 		Timeout:      timeout,
-		Delay:        5 * time.Second,
-		PollInterval: 10 * time.Second,
+		Delay:        framework.ScaleWait(c, 5*time.Second),
+		PollInterval: framework.ScaleWait(c, 10*time.Second),
 	}
 	_, err := conf.WaitForStateContext(ctx)
 	return err
 }
 
-func waitForConnectorDeletion(ctx context.Context, api connectorAPI, id string, timeout time.Duration) error {
+func waitForConnectorDeletion(ctx context.Context, c *client.Client, api connectorAPI, id string, timeout time.Duration) error {
 	conf := &retry.StateChangeConf{
 		Pending:      []string{client.ConnectorStateDeleting, client.ConnectorStateChanging, client.ConnectorStateUnknown},
 		Target:       []string{client.ConnectorStateDeleted},
 		Refresh:      connectorStatusFunc(ctx, api, id),
 		Timeout:      timeout,
-		Delay:        5 * time.Second,
-		PollInterval: 10 * time.Second,
+		Delay:        framework.ScaleWait(c, 5*time.Second),
+		PollInterval: framework.ScaleWait(c, 10*time.Second),
 	}
 	_, err := conf.WaitForStateContext(ctx)
 	if ue, ok := err.(*retry.UnexpectedStateError); ok && ue.LastError == nil {
@@ -33,6 +33,7 @@ var (
 	_ resource.Resource                = &ConnectorPluginResource{}
 	_ resource.ResourceWithConfigure   = &ConnectorPluginResource{}
 	_ resource.ResourceWithImportState = &ConnectorPluginResource{}
+	_ resource.ResourceWithModifyPlan  = &ConnectorPluginResource{}
 )
 
 func NewConnectorPluginResource() resource.Resource {
@@ -46,6 +47,7 @@ type ConnectorPluginResource struct {
 	client *client.Client
 	api    connectorPluginAPI
 	framework.WithTimeouts
+	framework.WithDefaultEnvironment
 }
 
 type connectorPluginAPI interface {
@@ -74,13 +76,14 @@ func (r *ConnectorPluginResource) Configure(_ context.Context, req resource.Conf
 	if req.ProviderData == nil {
 		return
 	}
-	c, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected Provider Data", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected Provider Data", fmt.Sprintf("Expected *framework.ProviderData, got %T", req.ProviderData))
 		return
 	}
-	r.client = c
-	r.api = defaultConnectorPluginAPI{client: c}
+	r.client = data.Client
+	r.api = defaultConnectorPluginAPI{client: data.Client}
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
 }
 
 func (r *ConnectorPluginResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
@@ -89,9 +92,10 @@ func (r *ConnectorPluginResource) Schema(ctx context.Context, _ resource.SchemaR
 			"`automq_connector_plugin` registers a custom Kafka Connect plugin. Plugins are immutable after creation — to change a plugin, delete and recreate it.",
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				Required:      true,
-				Description:   "Target AutoMQ environment identifier.",
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Optional:      true,
+				Computed:      true,
+				Description:   "Target AutoMQ environment identifier. Defaults to the provider `environment_id`.",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"id": schema.StringAttribute{
 				Computed:      true,
@@ -169,6 +173,9 @@ func (r *ConnectorPluginResource) Schema(ctx context.Context, _ resource.SchemaR
 // ---------------------------------------------------------------------------
 
 func (r *ConnectorPluginResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connector_plugin.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan models.ConnectorPluginResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -184,7 +191,10 @@ func (r *ConnectorPluginResource) Create(ctx context.Context, req resource.Creat
 
 	created, err := r.api.CreateConnectPlugin(ctx, *request)
 	if err != nil {
-		resp.Diagnostics.AddError("Create Connector Plugin Error", fmt.Sprintf("Unable to create connector plugin: %s", err))
+		framework.AddAPIError(&resp.Diagnostics, "Create Connector Plugin Error", fmt.Sprintf("Unable to create connector plugin: %s", err), err, framework.APIErrorAttributes{
+			client.ErrConflict:         path.Root("name"),
+			client.ErrInvalidParameter: path.Root("storage_url"),
+		})
 		return
 	}
 	pluginID := derefString(created.Id)
@@ -199,7 +209,7 @@ func (r *ConnectorPluginResource) Create(ctx context.Context, req resource.Creat
 		return
 	}
 
-	if err := waitForPluginActive(ctx, r.api, pluginID, r.CreateTimeout(ctx, plan.Timeouts)); err != nil {
+	if err := waitForPluginActive(ctx, r.client, r.api, pluginID, r.CreateTimeout(ctx, plan.Timeouts)); err != nil {
 		resp.Diagnostics.AddError("Connector Plugin Provisioning Error", err.Error())
 		return
 	}
@@ -217,6 +227,9 @@ func (r *ConnectorPluginResource) Create(ctx context.Context, req resource.Creat
 }
 
 func (r *ConnectorPluginResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connector_plugin.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.ConnectorPluginResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -241,11 +254,17 @@ func (r *ConnectorPluginResource) Read(ctx context.Context, req resource.ReadReq
 }
 
 func (r *ConnectorPluginResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connector_plugin.Update")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	// Plugins are immutable — all attributes have RequiresReplace, so Update should never be called.
 	resp.Diagnostics.AddError("Update Not Supported", "Connector plugins are immutable. Delete and recreate to change.")
 }
 
 func (r *ConnectorPluginResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_connector_plugin.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.ConnectorPluginResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -261,7 +280,7 @@ func (r *ConnectorPluginResource) Delete(ctx context.Context, req resource.Delet
 		resp.Diagnostics.AddError("Delete Connector Plugin Error", fmt.Sprintf("Unable to delete plugin %q: %s", pluginID, err))
 		return
 	}
-	if err := waitForPluginDeletion(ctx, r.api, pluginID, r.DeleteTimeout(ctx, state.Timeouts)); err != nil {
+	if err := waitForPluginDeletion(ctx, r.client, r.api, pluginID, r.DeleteTimeout(ctx, state.Timeouts)); err != nil {
 		resp.Diagnostics.AddError("Connector Plugin Delete Error", err.Error())
 	}
 }
@@ -280,27 +299,27 @@ func (r *ConnectorPluginResource) ImportState(ctx context.Context, req resource.
 // Wait helpers
 // ---------------------------------------------------------------------------
 
-func waitForPluginActive(ctx context.Context, api connectorPluginAPI, id string, timeout time.Duration) error {
+func waitForPluginActive(ctx context.Context, c *client.Client, api connectorPluginAPI, id string, timeout time.Duration) error {
 	conf := &retry.StateChangeConf{
 		Pending:      []string{client.PluginStatePending},
 		Target:       []string{client.PluginStateActive},
 		Refresh:      pluginStatusFunc(ctx, api, id),
 		Timeout:      timeout,
-		Delay:        2 * time.Second,
-		PollInterval: 5 * time.Second,
+		Delay:        framework.ScaleWait(c, 2*time.Second),
+		PollInterval: framework.ScaleWait(c, 5*time.Second),
 	}
 	_, err := conf.WaitForStateContext(ctx)
 	return err
 }
 
-func waitForPluginDeletion(ctx context.Context, api connectorPluginAPI, id string, timeout time.Duration) error {
+func waitForPluginDeletion(ctx context.Context, c *client.Client, api connectorPluginAPI, id string, timeout time.Duration) error {
 	conf := &retry.StateChangeConf{
 		Pending:      []string{client.PluginStateActive, client.PluginStateDisabled, client.PluginStateDeleting},
 		Target:       []string{client.PluginStateDeleted},
 		Refresh:      pluginStatusFunc(ctx, api, id),
 		Timeout:      timeout,
-		Delay:        2 * time.Second,
-		PollInterval: 5 * time.Second,
+		Delay:        framework.ScaleWait(c, 2*time.Second),
+		PollInterval: framework.ScaleWait(c, 5*time.Second),
 	}
 	_, err := conf.WaitForStateContext(ctx)
 	if ue, ok := err.(*retry.UnexpectedStateError); ok && ue.LastError == nil {
@@ -2,13 +2,17 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"strings"
 	"terraform-provider-automq/client"
 	"terraform-provider-automq/internal/framework"
 	"terraform-provider-automq/internal/models"
 	"time"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
@@ -22,14 +26,16 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"gopkg.in/yaml.v3"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
@@ -37,6 +43,7 @@ var _ resource.Resource = &KafkaInstanceResource{}
 var _ resource.ResourceWithConfigure = &KafkaInstanceResource{}
 var _ resource.ResourceWithImportState = &KafkaInstanceResource{}
 var _ resource.ResourceWithValidateConfig = &KafkaInstanceResource{}
+var _ resource.ResourceWithModifyPlan = &KafkaInstanceResource{}
 
 func NewKafkaInstanceResource() resource.Resource {
 	r := &KafkaInstanceResource{}
@@ -51,6 +58,8 @@ type KafkaInstanceResource struct {
 	client *client.Client
 	api    kafkaInstanceAPI
 	framework.WithTimeouts
+	framework.WithDefaultEnvironment
+	framework.WithDefaultTags
 }
 
 type kafkaInstanceAPI interface {
@@ -100,13 +109,15 @@ func (r *KafkaInstanceResource) Schema(ctx context.Context, req resource.SchemaR
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "![Preview](https://img.shields.io/badge/Lifecycle_Stage-Preview-blue?style=flat&logoColor=8A3BE2&labelColor=rgba)\n\n" +
-			"Using the `automq_kafka_instance` resource type, you can create and manage Kafka instances, where each instance represents a physical cluster.",
+			"Using the `automq_kafka_instance` resource type, you can create and manage Kafka instances, where each instance represents a physical cluster." +
+			" If the instance is still creating or changing when the wait times out or is interrupted, it is kept in the state instead of being tainted, and the next apply waits for it.",
 
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				MarkdownDescription: "Target AutoMQ BYOC environment identifier (for example, `env-xxxxx`). The environment determines the cloud provider and region. Find the ID on the AutoMQ console System Settings page.",
-				Required:            true,
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				MarkdownDescription: "Target AutoMQ BYOC environment identifier (for example, `env-xxxxx`). The environment determines the cloud provider and region. Find the ID on the AutoMQ console System Settings page. Defaults to the provider `environment_id`.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"id": schema.StringAttribute{
 				Computed:            true,
@@ -128,13 +139,25 @@ func (r *KafkaInstanceResource) Schema(ctx context.Context, req resource.SchemaR
 			},
 			"version": schema.StringAttribute{
 				Required:            true,
-				MarkdownDescription: "The software version of AutoMQ instance. If you need to specify a version, refer to the [documentation](https://docs.automq.com/automq-cloud/release-notes) to choose the appropriate version number.",
+				MarkdownDescription: "The software version of AutoMQ instance. If you need to specify a version, refer to the [documentation](https://docs.automq.com/automq-cloud/release-notes) to choose the appropriate version number. Changing it upgrades the instance in place with a rolling upgrade; downgrades are rejected at plan time. The target version is not checked against the versions the environment offers, which the AutoMQ API does not expose: a version that is not available fails at apply time.",
 			},
 			"tags": schema.MapAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "A map of tags to assign to the Kafka instance. Tags are key-value pairs that help you identify and organize your resources. Once set, tags cannot be modified.",
-				PlanModifiers:       []planmodifier.Map{mapplanmodifier.RequiresReplace()},
+				MarkdownDescription: "A map of tags to assign to the Kafka instance. Tags are key-value pairs that help you identify and organize your resources. Tags cannot be changed once the instance is created: the AutoMQ API does not support updating them yet, so plans changing them fail. Tags with the same key as a provider `default_tags` tag take precedence.",
+			},
+			"tags_all": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "All tags of the Kafka instance: `tags` merged with the provider `default_tags`. As instance tags cannot be changed, changes to `default_tags` only apply to instances created afterwards.",
+			},
+			"deletion_protection": framework.DeletionProtectionAttribute("Kafka instance"),
+			"wait_for_running_before_update": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+				MarkdownDescription: "Whether an update of an instance that is `Creating` or `Changing`, for example during an autoscale or after an interrupted apply, waits for it to be `Running` instead of failing. " +
+					"Updates the control plane rejects because of the instance state are retried the same way, within the update timeout. Defaults to `false`.",
 			},
 			"compute_specs": schema.SingleNestedAttribute{
 				Required:            true,
@@ -307,7 +330,7 @@ func (r *KafkaInstanceResource) Schema(ctx context.Context, req resource.SchemaR
 					},
 					"schedule_spec": schema.StringAttribute{
 						Optional:            true,
-						MarkdownDescription: "Kubernetes affinity and tolerations YAML. Required when `deploy_type` is `K8S` and `kubernetes_node_groups` is omitted. The constraints must match the target cluster's node labels and taints. Requires Control Plane 8.3.6 or later. Updates are not currently supported and will be rejected.",
+						MarkdownDescription: "Kubernetes affinity and tolerations YAML. Required when `deploy_type` is `K8S` and `kubernetes_node_groups` is omitted. The constraints must match the target cluster's node labels and taints. Requires Control Plane 8.3.6 or later. Updates reschedule the brokers in place; the spec cannot be removed once set.",
 					},
 					"instance_role": schema.StringAttribute{
 						Computed:            true,
@@ -468,7 +491,7 @@ func (r *KafkaInstanceResource) Schema(ctx context.Context, req resource.SchemaR
 					},
 					"metrics_exporter": schema.SingleNestedAttribute{
 						Optional:            true,
-						MarkdownDescription: "Configure Prometheus Remote Write metrics exporter.",
+						MarkdownDescription: "Configure metrics exporters. Any combination of the `prometheus`, `cloudwatch` and `otlp` exporters can be enabled.",
 						Attributes: map[string]schema.Attribute{
 							"prometheus": schema.SingleNestedAttribute{
 								Optional:            true,
@@ -510,6 +533,32 @@ func (r *KafkaInstanceResource) Schema(ctx context.Context, req resource.SchemaR
 									},
 								},
 							},
+							"cloudwatch": schema.SingleNestedAttribute{
+								Optional:            true,
+								MarkdownDescription: "Amazon CloudWatch configuration for exporting metrics.",
+								Attributes: map[string]schema.Attribute{
+									"namespace": schema.StringAttribute{
+										Required:            true,
+										MarkdownDescription: "CloudWatch namespace the metrics are published to.",
+										Validators: []validator.String{
+											stringvalidator.LengthAtLeast(1),
+										},
+									},
+								},
+							},
+							"otlp": schema.SingleNestedAttribute{
+								Optional:            true,
+								MarkdownDescription: "OpenTelemetry Protocol (OTLP) configuration for exporting metrics.",
+								Attributes: map[string]schema.Attribute{
+									"endpoint": schema.StringAttribute{
+										Required:            true,
+										MarkdownDescription: "OTLP collector endpoint URL.",
+										Validators: []validator.String{
+											stringvalidator.LengthAtLeast(1),
+										},
+									},
+								},
+							},
 						},
 					},
 					"table_topic": schema.SingleNestedAttribute{
@@ -557,6 +606,83 @@ func (r *KafkaInstanceResource) Schema(ctx context.Context, req resource.SchemaR
 							},
 						},
 					},
+					"inbound_rules": schema.ListNestedAttribute{
+						Optional:            true,
+						MarkdownDescription: "Client addresses allowed to connect, per listener. Rules are updated in place; once set, at least one rule must be kept.",
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"listener_name": schema.StringAttribute{
+									Required:            true,
+									MarkdownDescription: "Name of the listener the rule applies to.",
+								},
+								"cidrs": schema.SetAttribute{
+									Required:            true,
+									ElementType:         types.StringType,
+									MarkdownDescription: "CIDR blocks allowed to connect to the listener, such as `10.0.0.0/16`.",
+									Validators: []validator.Set{
+										setvalidator.SizeAtLeast(1),
+									},
+								},
+							},
+						},
+					},
+					"extend_listeners": schema.ListNestedAttribute{
+						Optional:            true,
+						MarkdownDescription: "Listeners added to the default listeners of the instance. Changing them requires instance replacement.",
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+						PlanModifiers: []planmodifier.List{listplanmodifier.RequiresReplace()},
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"listener_name": schema.StringAttribute{
+									Required:            true,
+									MarkdownDescription: "Name of the listener.",
+								},
+								"security_protocol": schema.StringAttribute{
+									Required:            true,
+									MarkdownDescription: "Kafka security protocol of the listener. Supported values: `PLAINTEXT`, `SASL_PLAINTEXT`, `SSL`, `SASL_SSL`.",
+									Validators: []validator.String{
+										stringvalidator.OneOf("PLAINTEXT", "SASL_PLAINTEXT", "SSL", "SASL_SSL"),
+									},
+								},
+								"port": schema.Int64Attribute{
+									Required:            true,
+									MarkdownDescription: "Port of the listener.",
+									Validators: []validator.Int64{
+										int64validator.Between(1, 65535),
+									},
+								},
+							},
+						},
+					},
+					"s3_failover": schema.SingleNestedAttribute{
+						Optional:            true,
+						MarkdownDescription: "Failover storage for the WAL of an `S3WAL` instance, used while object storage is unavailable. Only valid when `wal_mode` is `S3WAL`. `enabled` can be changed and `ebs_volume_size_in_gb` increased in place; `storage_type` cannot be changed once set. Set `enabled` to `false` instead of removing the block to turn failover off; a block with `enabled = false` can then be removed without changing the instance.",
+						Attributes: map[string]schema.Attribute{
+							"enabled": schema.BoolAttribute{
+								Required:            true,
+								MarkdownDescription: "Whether WAL failover is enabled.",
+							},
+							"storage_type": schema.StringAttribute{
+								Optional:            true,
+								MarkdownDescription: "Storage used for the WAL during failover. Supported values: `EBS`. Required when `enabled` is `true`.",
+								Validators: []validator.String{
+									stringvalidator.OneOf("EBS"),
+								},
+							},
+							"ebs_volume_size_in_gb": schema.Int64Attribute{
+								Optional:            true,
+								MarkdownDescription: "Size in GiB of the EBS volume of each broker used for failover. Required when `storage_type` is `EBS`.",
+								Validators: []validator.Int64{
+									int64validator.AtLeast(1),
+								},
+							},
+						},
+					},
 					"schema_registry_enabled": schema.BoolAttribute{
 						Optional:            true,
 						Computed:            true,
@@ -630,16 +756,83 @@ func (r *KafkaInstanceResource) Configure(ctx context.Context, req resource.Conf
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *http.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *framework.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
-	r.client = client
-	r.api = defaultKafkaInstanceAPI{client: client}
+	r.client = data.Client
+	r.api = defaultKafkaInstanceAPI{client: data.Client}
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
+	r.SetDefaultTags(data.DefaultTags)
+}
+
+func (r *KafkaInstanceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.WithDefaultEnvironment.ModifyPlan(ctx, req, resp)
+	r.WithDefaultTags.ModifyPlan(ctx, req, resp)
+	checkTagsUpdate(ctx, req, resp)
+	framework.CheckDeletionProtection(ctx, req, resp)
+	planPendingOperation(ctx, req.Private, req, resp)
+	checkVersionUpgrade(ctx, req, resp)
+}
+
+// checkTagsUpdate rejects a plan changing the tags of an instance: the API
+// cannot update them yet, and replacing the instance for a tag is not an
+// option. Instances whose tags are unchanged keep their tags_all, so that
+// changing the provider default_tags plans no change for them.
+func checkTagsUpdate(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	var planTags, stateTags, stateTagsAll types.Map
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("tags"), &planTags)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("tags"), &stateTags)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("tags_all"), &stateTagsAll)...)
+	if resp.Diagnostics.HasError() || planTags.IsUnknown() {
+		return
+	}
+	if !mapAttrEqual(planTags, stateTags) {
+		resp.Diagnostics.AddAttributeError(path.Root("tags"), "Kafka Instance Tags Update Not Supported",
+			"The AutoMQ API cannot update the tags of an existing Kafka instance yet. Revert the change to tags, "+
+				"or replace the instance on purpose with terraform apply -replace.")
+		return
+	}
+	if !stateTagsAll.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("tags_all"), stateTagsAll)...)
+	}
+}
+
+// checkVersionUpgrade rejects a planned version that is not a version, or
+// that is older than the version of the instance.
+func checkVersionUpgrade(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	var planned, current types.String
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("version"), &planned)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("version"), &current)...)
+	if resp.Diagnostics.HasError() || planned.IsUnknown() || planned.IsNull() || current.IsNull() || planned.Equal(current) {
+		return
+	}
+	target, err := version.NewVersion(planned.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("version"), "Invalid Kafka Instance Version",
+			fmt.Sprintf("Version %q is not a valid AutoMQ version: %s", planned.ValueString(), err))
+		return
+	}
+	from, err := version.NewVersion(current.ValueString())
+	if err != nil {
+		// The backend reported a version the provider cannot compare, leave
+		// the check to the backend.
+		return
+	}
+	if target.LessThan(from) {
+		resp.Diagnostics.AddAttributeError(path.Root("version"), "Kafka Instance Downgrade Not Supported",
+			fmt.Sprintf("The Kafka instance runs version %s and cannot be downgraded to %s. Keep version at %s or later.", current.ValueString(), planned.ValueString(), current.ValueString()))
+	}
 }
 
 func isStringValueSet(attr types.String) bool {
@@ -685,8 +878,10 @@ func validateInstanceContract(ctx context.Context, plan *models.KafkaInstanceRes
 	}
 
 	diagnostics.Append(validateDeployTypeContract(ctx, plan)...)
+	diagnostics.Append(validateScheduleSpecContract(plan)...)
 	diagnostics.Append(validatePricingModeContract(plan)...)
 	diagnostics.Append(validateWalModeContract(ctx, plan)...)
+	diagnostics.Append(validateS3FailoverContract(ctx, plan)...)
 	diagnostics.Append(validateManagedResourceContract(ctx, plan)...)
 	diagnostics.Append(validateFeatureContract(ctx, plan)...)
 
@@ -744,6 +939,34 @@ func validateDeployTypeContract(ctx context.Context, plan *models.KafkaInstanceR
 	return diagnostics
 }
 
+// validateScheduleSpecContract checks that schedule_spec is a YAML or JSON
+// mapping, such as one with affinity and tolerations keys.
+func validateScheduleSpecContract(plan *models.KafkaInstanceResourceModel) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	if plan == nil || plan.ComputeSpecs == nil {
+		return diagnostics
+	}
+	scheduleSpec, ok := knownStringValue(plan.ComputeSpecs.ScheduleSpec)
+	if !ok {
+		return diagnostics
+	}
+	var spec map[string]interface{}
+	if err := yaml.Unmarshal([]byte(scheduleSpec), &spec); err != nil {
+		diagnostics.AddAttributeError(
+			path.Root("compute_specs").AtName("schedule_spec"),
+			"Invalid Configuration",
+			fmt.Sprintf("compute_specs.schedule_spec must be a YAML or JSON object of Kubernetes scheduling constraints: %s", err),
+		)
+	} else if len(spec) == 0 {
+		diagnostics.AddAttributeError(
+			path.Root("compute_specs").AtName("schedule_spec"),
+			"Invalid Configuration",
+			"compute_specs.schedule_spec must set at least one Kubernetes scheduling constraint, such as affinity or tolerations.",
+		)
+	}
+	return diagnostics
+}
+
 func validatePricingModeContract(plan *models.KafkaInstanceResourceModel) diag.Diagnostics {
 	var diagnostics diag.Diagnostics
 	if plan == nil || plan.ComputeSpecs == nil {
@@ -841,6 +1064,37 @@ func validateWalModeContract(ctx context.Context, plan *models.KafkaInstanceReso
 	return diagnostics
 }
 
+func validateS3FailoverContract(ctx context.Context, plan *models.KafkaInstanceResourceModel) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	if plan == nil || plan.Features == nil {
+		return diagnostics
+	}
+	failover, failoverDiags := models.S3FailoverObjectToModel(ctx, plan.Features.S3Failover)
+	diagnostics.Append(failoverDiags...)
+	if failover == nil || failoverDiags.HasError() {
+		return diagnostics
+	}
+	if walMode, ok := knownStringValue(plan.Features.WalMode); ok && !strings.EqualFold(walMode, "S3WAL") {
+		diagnostics.AddError(
+			"Invalid Configuration",
+			fmt.Sprintf("features.s3_failover is only valid when wal_mode is S3WAL, got %s.", walMode),
+		)
+	}
+	if failover.Enabled.ValueBool() && failover.StorageType.IsNull() {
+		diagnostics.AddError(
+			"Invalid Configuration",
+			"features.s3_failover.storage_type must be provided when s3_failover is enabled.",
+		)
+	}
+	if storageType, ok := knownStringValue(failover.StorageType); ok && strings.EqualFold(storageType, "EBS") && failover.EbsVolumeSizeInGB.IsNull() {
+		diagnostics.AddError(
+			"Invalid Configuration",
+			"features.s3_failover.ebs_volume_size_in_gb must be provided when storage_type is EBS.",
+		)
+	}
+	return diagnostics
+}
+
 func validateManagedResourceContract(ctx context.Context, plan *models.KafkaInstanceResourceModel) diag.Diagnostics {
 	var diagnostics diag.Diagnostics
 	if plan == nil || plan.ComputeSpecs == nil {
@@ -877,6 +1131,7 @@ func validateFeatureContract(ctx context.Context, plan *models.KafkaInstanceReso
 		}
 		diagnostics.Append(validateMetricsExporterContract(metrics)...)
 	}
+	diagnostics.Append(validateListenerContract(ctx, plan.Features)...)
 	if !plan.Features.TableTopic.IsNull() && !plan.Features.TableTopic.IsUnknown() &&
 		(plan.Features.SchemaRegistryEnabled.IsNull() ||
 			plan.Features.SchemaRegistryEnabled.IsUnknown() ||
@@ -889,18 +1144,79 @@ func validateFeatureContract(ctx context.Context, plan *models.KafkaInstanceReso
 	return diagnostics
 }
 
+// validateListenerContract checks that inbound rules and extended listeners
+// name each listener once, and that the rules hold valid CIDR blocks.
+func validateListenerContract(ctx context.Context, features *models.FeaturesModel) diag.Diagnostics {
+	var diagnostics diag.Diagnostics
+	rules, ruleDiags := models.InboundRuleListToModels(ctx, features.InboundRules)
+	diagnostics.Append(ruleDiags...)
+	listeners, listenerDiags := models.ExtendListenerListToModels(ctx, features.ExtendListeners)
+	diagnostics.Append(listenerDiags...)
+	if diagnostics.HasError() {
+		return diagnostics
+	}
+
+	ruleListeners := map[string]bool{}
+	for i, rule := range rules {
+		if name, ok := knownStringValue(rule.ListenerName); ok {
+			if ruleListeners[name] {
+				diagnostics.AddError(
+					"Invalid Configuration",
+					fmt.Sprintf("features.inbound_rules[%d]: listener %q already has a rule. Put all its CIDR blocks in one rule.", i, name),
+				)
+			}
+			ruleListeners[name] = true
+		}
+		if rule.Cidrs.IsUnknown() {
+			continue
+		}
+		for _, element := range rule.Cidrs.Elements() {
+			cidr, ok := element.(types.String)
+			if !ok || cidr.IsUnknown() || cidr.IsNull() {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(cidr.ValueString()); err != nil {
+				diagnostics.AddError(
+					"Invalid Configuration",
+					fmt.Sprintf("features.inbound_rules[%d].cidrs: %q is not a valid CIDR block.", i, cidr.ValueString()),
+				)
+			}
+		}
+	}
+
+	listenerNames := map[string]bool{}
+	for i, listener := range listeners {
+		if name, ok := knownStringValue(listener.ListenerName); ok {
+			if listenerNames[name] {
+				diagnostics.AddError(
+					"Invalid Configuration",
+					fmt.Sprintf("features.extend_listeners[%d]: listener %q is declared more than once.", i, name),
+				)
+			}
+			listenerNames[name] = true
+		}
+	}
+	return diagnostics
+}
+
 func validateMetricsExporterContract(metrics *models.MetricsExporterModel) diag.Diagnostics {
 	var diagnostics diag.Diagnostics
 	if metrics == nil {
 		return diagnostics
 	}
-	if metrics.Prometheus == nil || !models.PrometheusExporterHasConfig(metrics.Prometheus) {
+	if metrics.Prometheus == nil && metrics.CloudWatch == nil && metrics.Otlp == nil {
 		diagnostics.AddError(
 			"Invalid Configuration",
-			"features.metrics_exporter must include a prometheus block with required attributes. Remove the metrics_exporter block entirely to disable metrics export.",
+			"features.metrics_exporter must include at least one of the prometheus, cloudwatch or otlp blocks. Remove the metrics_exporter block entirely to disable metrics export.",
 		)
 		return diagnostics
 	}
+	if metrics.Prometheus != nil && !models.PrometheusExporterHasConfig(metrics.Prometheus) {
+		diagnostics.AddError(
+			"Invalid Configuration",
+			"features.metrics_exporter.prometheus must set its required attributes.",
+		)
+	}
 	return diagnostics
 }
 
@@ -914,6 +1230,9 @@ func (r *KafkaInstanceResource) ValidateConfig(ctx context.Context, req resource
 }
 
 func (r *KafkaInstanceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_instance.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan models.KafkaInstanceResourceModel
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -945,7 +1264,9 @@ func (r *KafkaInstanceResource) Create(ctx context.Context, req resource.CreateR
 
 	out, err := r.api.CreateKafkaInstance(ctx, in)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create Kafka instance, got error: %s", err))
+		framework.AddAPIError(&resp.Diagnostics, "Client Error", fmt.Sprintf("Unable to create Kafka instance, got error: %s", err), err, framework.APIErrorAttributes{
+			client.ErrConflict: path.Root("name"),
+		})
 		return
 	}
 	// Start refresh from the original plan so backend-omitted fields still have
@@ -966,7 +1287,18 @@ func (r *KafkaInstanceResource) Create(ctx context.Context, req resource.CreateR
 
 	createTimeout := r.WithTimeouts.CreateTimeout(ctx, state.Timeouts)
 	if err := waitForKafkaClusterToProvisionFunc(ctx, r.client, instanceId, models.StateCreating, createTimeout); err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error waiting for Kafka Cluster %q to provision: %s", instanceId, err))
+		if !instanceStillPending(ctx, r.api, instanceId, models.StateCreating) {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error waiting for Kafka Cluster %q to provision: %s", instanceId, err))
+			return
+		}
+		// Returning an error would taint an instance that is likely to become
+		// healthy, and the next apply would replace it. Keep it instead, and
+		// let the next apply wait for it.
+		resp.Diagnostics.Append(setPendingOperation(ctx, resp.Private, models.StateCreating)...)
+		resp.Diagnostics.Append(nullUnknownValues(&resp.State)...)
+		resp.Diagnostics.AddWarning("Kafka Instance Still Creating",
+			fmt.Sprintf("Kafka instance %q is not Running yet: %s\n"+
+				"The instance is kept in the state and the next apply waits for it. Resources that depend on it may fail until then.", instanceId, err))
 		return
 	}
 
@@ -985,6 +1317,9 @@ func (r *KafkaInstanceResource) Create(ctx context.Context, req resource.CreateR
 }
 
 func (r *KafkaInstanceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_instance.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaInstanceResourceModel
 
 	// Read Terraform prior state data into the model
@@ -1004,11 +1339,27 @@ func (r *KafkaInstanceResource) Read(ctx context.Context, req resource.ReadReque
 		resp.State.RemoveResource(ctx)
 		return
 	}
+	pending, diags := getPendingOperation(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if pending != "" && state.InstanceStatus.ValueString() == models.StateRunning {
+		resp.Diagnostics.Append(setPendingOperation(ctx, resp.Private, "")...)
+	}
+	// Imported instances and states written before the Terraform-only
+	// attributes existed have none.
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
+	if state.WaitForRunning.IsNull() {
+		state.WaitForRunning = types.BoolValue(false)
+	}
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *KafkaInstanceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_instance.Update")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan, state models.KafkaInstanceResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -1027,7 +1378,9 @@ func (r *KafkaInstanceResource) Update(ctx context.Context, req resource.UpdateR
 	ctx = context.WithValue(ctx, client.EnvIdKey, plan.EnvironmentID.ValueString())
 
 	instanceId := plan.InstanceID.ValueString()
-	updateTimeout := r.WithTimeouts.UpdateTimeout(ctx, state.Timeouts)
+	// Every wait of the update, from resuming a pending operation to the
+	// PATCH being rolled out, shares the update timeout.
+	deadline := time.Now().Add(r.WithTimeouts.UpdateTimeout(ctx, state.Timeouts))
 
 	// Validate update-only contracts that require comparing the new plan with
 	// prior state, such as unsupported removal of instance config keys.
@@ -1043,6 +1396,45 @@ func (r *KafkaInstanceResource) Update(ctx context.Context, req resource.UpdateR
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	// deletion_protection and wait_for_running_before_update only live in
+	// Terraform state, changing them alone needs no backend call. Neither
+	// does removing a disabled s3_failover block.
+	localChanged := !plan.DeletionProtection.Equal(state.DeletionProtection) || !plan.WaitForRunning.Equal(state.WaitForRunning) || updatePlan.s3FailoverRemoved
+	state.DeletionProtection = plan.DeletionProtection
+	state.WaitForRunning = plan.WaitForRunning
+	if updatePlan.s3FailoverRemoved {
+		state.Features.S3Failover = plan.Features.S3Failover
+	}
+	pending, diags := getPendingOperation(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if pending != "" {
+		tflog.Info(ctx, "resuming wait for Kafka instance", map[string]any{"instance_id": instanceId, "status": pending})
+		if err := waitForKafkaClusterToProvisionFunc(ctx, r.client, instanceId, pending, time.Until(deadline)); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error waiting for Kafka Cluster %q to provision: %s", instanceId, err))
+			return
+		}
+		resp.Diagnostics.Append(setPendingOperation(ctx, resp.Private, "")...)
+		if !updatePlan.hasUpdate {
+			found, diags := refreshKafkaInstanceState(ctx, r, instanceId, &state)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			if !found {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Kafka instance %q not found", instanceId))
+				return
+			}
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			return
+		}
+	}
+	if !updatePlan.hasUpdate && localChanged {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
 	if !updatePlan.hasUpdate {
 		resp.Diagnostics.AddError(
 			"Unsupported Kafka Instance Update",
@@ -1070,26 +1462,58 @@ func (r *KafkaInstanceResource) Update(ctx context.Context, req resource.UpdateR
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Kafka instance %q not found", instanceId))
 		return
 	}
+	waitForRunning := plan.WaitForRunning.ValueBool()
 	if instance.State == nil || *instance.State != models.StateRunning {
 		current := models.StateUnknown
 		if instance.State != nil {
 			current = *instance.State
 		}
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Kafka instance %q is Currently in %q state, only instances in 'Running' state can be updated", instanceId, current))
-		return
+		if !waitForRunning || (current != models.StateCreating && current != models.StateChanging) {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Kafka instance %q is Currently in %q state, only instances in 'Running' state can be updated", instanceId, current))
+			return
+		}
+		tflog.Info(ctx, "waiting for Kafka instance to be Running before updating it", map[string]any{"instance_id": instanceId, "status": current})
+		if err := waitForKafkaClusterToProvisionFunc(ctx, r.client, instanceId, current, time.Until(deadline)); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error waiting for Kafka Cluster %q to be Running before updating it: %s", instanceId, err))
+			return
+		}
+	}
+
+	// Upgrade first, so the remaining changes are applied to the new version.
+	if updatePlan.upgradeVersion != "" {
+		upgradeDiags := r.upgradeKafkaInstance(ctx, instanceId, state.Version.ValueString(), updatePlan.upgradeVersion, time.Until(deadline))
+		if upgradeDiags.HasError() {
+			// Keep the version the instance is actually left on in the state.
+			if found, diags := refreshKafkaInstanceState(ctx, r, instanceId, &state); found && !diags.HasError() {
+				resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			}
+			if instanceStillPending(ctx, r.api, instanceId, models.StateChanging) {
+				resp.Diagnostics.Append(setPendingOperation(ctx, resp.Private, models.StateChanging)...)
+			}
+		}
+		resp.Diagnostics.Append(upgradeDiags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
 	// Execute the PATCH, wait for asynchronous changes when needed, then refresh
 	// from backend so Terraform state reflects server-side readback.
-	if err := r.api.UpdateKafkaInstance(ctx, instanceId, updateParam); err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update Kafka instance %q, got error: %s", instanceId, err))
-		return
-	}
-	if updatePlan.shouldWait {
-		if err := waitForKafkaClusterToProvisionFunc(ctx, r.client, instanceId, models.StateChanging, updateTimeout); err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error waiting for Kafka Cluster %q to provision: %s", instanceId, err))
+	// A version upgrade alone leaves nothing to PATCH.
+	if updateParam != (client.InstanceUpdateParam{}) {
+		if err := r.updateKafkaInstance(ctx, instanceId, updateParam, waitForRunning, deadline); err != nil {
+			framework.AddAPIError(&resp.Diagnostics, "Client Error", fmt.Sprintf("Unable to update Kafka instance %q, got error: %s", instanceId, err), err, nil)
 			return
 		}
+		if updatePlan.shouldWait {
+			if err := waitForKafkaClusterToProvisionFunc(ctx, r.client, instanceId, models.StateChanging, time.Until(deadline)); err != nil {
+				if instanceStillPending(ctx, r.api, instanceId, models.StateChanging) {
+					resp.Diagnostics.Append(setPendingOperation(ctx, resp.Private, models.StateChanging)...)
+				}
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error waiting for Kafka Cluster %q to provision: %s", instanceId, err))
+				return
+			}
+		}
 	}
 	found, diags := refreshKafkaInstanceState(ctx, r, instanceId, &state)
 	resp.Diagnostics.Append(diags...)
@@ -1104,6 +1528,9 @@ func (r *KafkaInstanceResource) Update(ctx context.Context, req resource.UpdateR
 }
 
 func (r *KafkaInstanceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_instance.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaInstanceResourceModel
 
 	// Read Terraform prior state data into the model
@@ -1111,6 +1538,10 @@ func (r *KafkaInstanceResource) Delete(ctx context.Context, req resource.DeleteR
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.Diagnostics.Append(framework.DeletionProtectionError(state.DeletionProtection)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	ctx = context.WithValue(ctx, client.EnvIdKey, state.EnvironmentID.ValueString())
 
 	instanceId := state.InstanceID.ValueString()
@@ -1179,10 +1610,14 @@ func refreshKafkaInstanceState(ctx context.Context, r *KafkaInstanceResource, in
 	}
 
 	diags := diag.Diagnostics{}
+	priorTags, priorTagsAll := state.Tags, state.TagsAll
 	diags.Append(models.FlattenKafkaInstanceModel(ctx, instance, state)...)
 	if diags.HasError() {
 		return true, diags
 	}
+	tags, tagsDiags := r.ResourceTags(ctx, state.TagsAll, priorTags, priorTagsAll)
+	diags.Append(tagsDiags...)
+	state.Tags = tags
 	if shouldRefreshInstanceEndpoints(instance) {
 		endpoints, err := r.api.GetInstanceEndpoints(ctx, instanceId)
 		if err != nil {
@@ -1193,6 +1628,201 @@ func refreshKafkaInstanceState(ctx context.Context, r *KafkaInstanceResource, in
 	return true, diags
 }
 
+// maxStateConflictRetries caps how many times updateKafkaInstance sends a
+// PATCH again after it was rejected because of the instance state.
+const maxStateConflictRetries = 5
+
+// updateKafkaInstance sends the PATCH. With waitForRunning, a PATCH rejected
+// because of the instance state is sent again once the instance is Running,
+// after the client retry backoff, at most maxStateConflictRetries times and
+// until deadline.
+func (r *KafkaInstanceResource) updateKafkaInstance(ctx context.Context, instanceId string, param client.InstanceUpdateParam, waitForRunning bool, deadline time.Time) error {
+	err := r.api.UpdateKafkaInstance(ctx, instanceId, param)
+	if !waitForRunning {
+		return err
+	}
+	attempt := 1
+	for ; errors.Is(err, client.ErrStateConflict) && attempt <= maxStateConflictRetries && time.Now().Before(deadline); attempt++ {
+		tflog.Info(ctx, "Kafka instance rejected the update because of its state, retrying once it is Running", map[string]any{"instance_id": instanceId, "attempt": attempt, "error": err.Error()})
+		if waitErr := waitForKafkaClusterToProvisionFunc(ctx, r.client, instanceId, models.StateChanging, time.Until(deadline)); waitErr != nil {
+			return fmt.Errorf("%w (waiting for the instance to be Running: %s)", err, waitErr)
+		}
+		delay := r.client.RetryBackoff(attempt, err)
+		if time.Until(deadline) < delay {
+			break
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = r.api.UpdateKafkaInstance(ctx, instanceId, param)
+	}
+	if errors.Is(err, client.ErrStateConflict) && attempt > 1 {
+		return fmt.Errorf("the instance still rejected the update after %d attempts: %w", attempt, err)
+	}
+	return err
+}
+
+// upgradeKafkaInstance upgrades the instance from one version to another
+// and waits for the rolling upgrade to finish, logging its progress. An
+// upgrade that leaves the instance on another version is reported as rolled
+// back, one that leaves it in another state than Running as failed.
+func (r *KafkaInstanceResource) upgradeKafkaInstance(ctx context.Context, instanceId, from, to string, timeout time.Duration) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	logFields := map[string]any{"instance_id": instanceId, "from_version": from, "to_version": to}
+	start := time.Now()
+
+	tflog.Info(ctx, "requesting Kafka instance upgrade", logFields)
+	if err := r.api.UpdateKafkaInstance(ctx, instanceId, client.InstanceUpdateParam{Version: &to}); err != nil {
+		framework.AddAPIError(&diags, "Kafka Instance Upgrade Error",
+			fmt.Sprintf("Unable to upgrade Kafka instance %q from version %s to %s, got error: %s", instanceId, from, to, err), err,
+			framework.APIErrorAttributes{client.ErrInvalidParameter: path.Root("version")})
+		return diags
+	}
+
+	tflog.Info(ctx, "Kafka instance upgrade accepted, waiting for the rolling upgrade", logFields)
+	waitErr := waitForKafkaInstanceUpgradeFunc(ctx, r.client, instanceId, to, timeout)
+
+	instance, err := r.api.GetKafkaInstance(ctx, instanceId)
+	if err != nil || instance == nil {
+		if waitErr != nil {
+			err = waitErr
+		}
+		diags.AddError("Kafka Instance Upgrade Failed",
+			fmt.Sprintf("Unable to confirm the upgrade of Kafka instance %q to version %s, got error: %v", instanceId, to, err))
+		return diags
+	}
+	state := models.StateUnknown
+	if instance.State != nil {
+		state = *instance.State
+	}
+	current := ""
+	if instance.Version != nil {
+		current = *instance.Version
+	}
+	logFields["state"] = state
+	logFields["version"] = current
+	logFields["elapsed"] = time.Since(start).Round(time.Second).String()
+
+	switch {
+	case state == models.StateChanging:
+		tflog.Warn(ctx, "Kafka instance upgrade still in progress", logFields)
+		diags.AddError("Client Error",
+			fmt.Sprintf("Error waiting for the upgrade of Kafka Cluster %q to version %s: %v. The next apply waits for it.", instanceId, to, waitErr))
+	case state != models.StateRunning:
+		tflog.Error(ctx, "Kafka instance upgrade failed", logFields)
+		diags.AddAttributeError(path.Root("version"), "Kafka Instance Upgrade Failed",
+			fmt.Sprintf("The upgrade of Kafka instance %q from version %s to %s left the instance in state %q. Check the instance in the AutoMQ console before retrying.", instanceId, from, to, state))
+	case current != to:
+		tflog.Warn(ctx, "Kafka instance upgrade rolled back", logFields)
+		diags.AddAttributeError(path.Root("version"), "Kafka Instance Upgrade Rolled Back",
+			fmt.Sprintf("The upgrade of Kafka instance %q to version %s was rolled back, the instance is Running version %s. Check the instance events in the AutoMQ console before retrying.", instanceId, to, current))
+	default:
+		tflog.Info(ctx, "Kafka instance upgrade completed", logFields)
+	}
+	return diags
+}
+
+// pendingOperationKey is the private state key of an instance whose create
+// or update returned before the instance was Running. Its value holds the
+// status to wait on, for the next apply to resume waiting.
+const pendingOperationKey = "pending_operation"
+
+type pendingOperation struct {
+	Status string `json:"status"`
+}
+
+// privateState is the private state of the resource requests and responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// getPendingOperation returns the status of the pending operation, empty
+// when there is none.
+func getPendingOperation(ctx context.Context, private privateState) (string, diag.Diagnostics) {
+	value, diags := private.GetKey(ctx, pendingOperationKey)
+	if diags.HasError() || len(value) == 0 {
+		return "", diags
+	}
+	var operation pendingOperation
+	if err := json.Unmarshal(value, &operation); err != nil {
+		diags.AddError("Private State Error", fmt.Sprintf("Unable to read the pending operation of the Kafka instance: %s", err))
+	}
+	return operation.Status, diags
+}
+
+// setPendingOperation records a pending operation, or removes it when status
+// is empty.
+func setPendingOperation(ctx context.Context, private privateState, status string) diag.Diagnostics {
+	if status == "" {
+		return private.SetKey(ctx, pendingOperationKey, nil)
+	}
+	value, err := json.Marshal(pendingOperation{Status: status})
+	if err != nil {
+		return diag.Diagnostics{diag.NewErrorDiagnostic("Private State Error", fmt.Sprintf("Unable to record the pending operation of the Kafka instance: %s", err))}
+	}
+	return private.SetKey(ctx, pendingOperationKey, value)
+}
+
+// instanceStillPending reports whether an instance whose wait failed is still
+// in the pending status, so a later apply can wait for it. An interrupted wait
+// cannot tell, and counts as pending.
+func instanceStillPending(ctx context.Context, api kafkaInstanceAPI, instanceId, pending string) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	instance, err := api.GetKafkaInstance(ctx, instanceId)
+	return err == nil && instance != nil && instance.State != nil && *instance.State == pending
+}
+
+// planPendingOperation plans an update of an instance with a pending
+// operation, read from private, that was still not Running when refreshed,
+// for Update to resume waiting. The attributes the wait changes are planned
+// unknown.
+func planPendingOperation(ctx context.Context, private privateState, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	pending, diags := getPendingOperation(ctx, private)
+	resp.Diagnostics.Append(diags...)
+	if pending == "" {
+		return
+	}
+	for _, name := range []string{"status", "last_updated", "endpoints"} {
+		attrType, diags := resp.Plan.Schema.TypeAtPath(ctx, path.Root(name))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		unknown, err := attrType.ValueFromTerraform(ctx, tftypes.NewValue(attrType.TerraformType(ctx), tftypes.UnknownValue))
+		if err != nil {
+			resp.Diagnostics.AddError("Plan Error", fmt.Sprintf("Unable to plan %s of the Kafka instance: %s", name, err))
+			return
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), unknown)...)
+	}
+}
+
+// nullUnknownValues replaces the values of state still unknown, which a
+// create cannot return, with nulls. The next refresh reads them.
+func nullUnknownValues(state *tfsdk.State) diag.Diagnostics {
+	raw, err := tftypes.Transform(state.Raw, func(_ *tftypes.AttributePath, value tftypes.Value) (tftypes.Value, error) {
+		if !value.IsKnown() {
+			return tftypes.NewValue(value.Type(), nil), nil
+		}
+		return value, nil
+	})
+	if err != nil {
+		return diag.Diagnostics{diag.NewErrorDiagnostic("State Error", fmt.Sprintf("Unable to save the Kafka instance: %s", err))}
+	}
+	state.Raw = raw
+	return nil
+}
+
 type instanceUpdatePlan struct {
 	hasUpdate              bool
 	shouldWait             bool
@@ -1200,14 +1830,25 @@ type instanceUpdatePlan struct {
 	certificateChanged     bool
 	tableTopicChanged      bool
 	instanceTypesChanged   bool
+	scheduleSpecChanged    bool
+	s3FailoverChanged      bool
+	// s3FailoverRemoved is set when a disabled s3_failover block is removed,
+	// which leaves the instance as it is and only clears it from state.
+	s3FailoverRemoved   bool
+	inboundRulesChanged bool
+	// upgradeVersion is the version to upgrade to, empty when version is
+	// unchanged. The upgrade is an instance update carrying the version only,
+	// sent before updateParam so that it is waited for on its own.
+	upgradeVersion string
 }
 
 func validateInstanceUpdateContract(ctx context.Context, instanceId string, plan, state models.KafkaInstanceResourceModel) diag.Diagnostics {
 	diags := diag.Diagnostics{}
-	if plan.ComputeSpecs != nil && state.ComputeSpecs != nil && !stringAttrEqual(plan.ComputeSpecs.ScheduleSpec, state.ComputeSpecs.ScheduleSpec) {
+	if plan.ComputeSpecs != nil && state.ComputeSpecs != nil && isStringValueSet(state.ComputeSpecs.ScheduleSpec) &&
+		!plan.ComputeSpecs.ScheduleSpec.IsUnknown() && !isStringValueSet(plan.ComputeSpecs.ScheduleSpec) {
 		diags.AddError(
 			"Schedule Spec Update Error",
-			fmt.Sprintf("Error occurred while updating Kafka Instance %q. compute_specs.schedule_spec cannot currently be updated. Keep its existing value until schedule spec updates are supported.", instanceId),
+			fmt.Sprintf("Error occurred while updating Kafka Instance %q. compute_specs.schedule_spec cannot be removed once set. Keep it, or change it to the new scheduling constraints.", instanceId),
 		)
 		return diags
 	}
@@ -1231,6 +1872,18 @@ func validateInstanceUpdateContract(ctx context.Context, instanceId string, plan
 		}
 	}
 
+	if plan.Features != nil && state.Features != nil {
+		diags.Append(validateS3FailoverUpdateContract(ctx, instanceId, plan.Features.S3Failover, state.Features.S3Failover)...)
+		if diags.HasError() {
+			return diags
+		}
+		// The PATCH leaves the rules unchanged when it has none.
+		if plan.Features.InboundRules.IsNull() && len(state.Features.InboundRules.Elements()) > 0 {
+			diags.AddError("Inbound Rules Update Error", fmt.Sprintf("Error occurred while updating Kafka Instance %q. features.inbound_rules cannot be removed once set. Keep at least one rule, such as one allowing 0.0.0.0/0.", instanceId))
+			return diags
+		}
+	}
+
 	if plan.Features == nil || state.Features == nil || plan.Features.InstanceConfigs.IsUnknown() || state.Features.InstanceConfigs.IsUnknown() {
 		return diags
 	}
@@ -1254,6 +1907,36 @@ func validateInstanceUpdateContract(ctx context.Context, instanceId string, plan
 	return diags
 }
 
+// validateS3FailoverUpdateContract allows enabling or disabling failover and
+// growing its EBS volumes in place. The storage type cannot change, volumes
+// cannot shrink, and enabled failover is turned off with enabled = false.
+func validateS3FailoverUpdateContract(ctx context.Context, instanceId string, planObject, stateObject types.Object) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	planFailover, planDiags := models.S3FailoverObjectToModel(ctx, planObject)
+	diags.Append(planDiags...)
+	stateFailover, stateDiags := models.S3FailoverObjectToModel(ctx, stateObject)
+	diags.Append(stateDiags...)
+	if diags.HasError() || stateFailover == nil || planObject.IsUnknown() {
+		return diags
+	}
+	if planFailover == nil {
+		if stateFailover.Enabled.ValueBool() {
+			diags.AddError("S3 Failover Update Error", fmt.Sprintf("Error occurred while updating Kafka Instance %q. features.s3_failover cannot be removed while failover is enabled. Set enabled = false to turn it off.", instanceId))
+		}
+		return diags
+	}
+	if !stateFailover.StorageType.IsNull() && !stringAttrEqual(planFailover.StorageType, stateFailover.StorageType) {
+		diags.AddError("S3 Failover Update Error", fmt.Sprintf("Error occurred while updating Kafka Instance %q. features.s3_failover.storage_type cannot be changed once set.", instanceId))
+		return diags
+	}
+	planSize, planOK := knownInt64Value(planFailover.EbsVolumeSizeInGB)
+	stateSize, stateOK := knownInt64Value(stateFailover.EbsVolumeSizeInGB)
+	if planOK && stateOK && planSize < stateSize {
+		diags.AddError("S3 Failover Update Error", fmt.Sprintf("Error occurred while updating Kafka Instance %q. features.s3_failover.ebs_volume_size_in_gb can only be increased, from %d to %d requested.", instanceId, stateSize, planSize))
+	}
+	return diags
+}
+
 func applyUpdateStatePreservation(ctx context.Context, state *models.KafkaInstanceResourceModel, plan models.KafkaInstanceResourceModel, updatePlan instanceUpdatePlan) diag.Diagnostics {
 	diags := diag.Diagnostics{}
 	if state == nil {
@@ -1299,6 +1982,20 @@ func applyUpdateStatePreservation(ctx context.Context, state *models.KafkaInstan
 		state.Features.TableTopic = plan.Features.TableTopic
 	}
 
+	if updatePlan.inboundRulesChanged && plan.Features != nil {
+		if state.Features == nil {
+			state.Features = &models.FeaturesModel{}
+		}
+		state.Features.InboundRules = plan.Features.InboundRules
+	}
+
+	if updatePlan.s3FailoverChanged && plan.Features != nil {
+		if state.Features == nil {
+			state.Features = &models.FeaturesModel{}
+		}
+		state.Features.S3Failover = plan.Features.S3Failover
+	}
+
 	if updatePlan.instanceTypesChanged && plan.ComputeSpecs != nil {
 		if state.ComputeSpecs == nil {
 			state.ComputeSpecs = &models.ComputeSpecsModel{}
@@ -1306,6 +2003,14 @@ func applyUpdateStatePreservation(ctx context.Context, state *models.KafkaInstan
 		state.ComputeSpecs.InstanceTypes = plan.ComputeSpecs.InstanceTypes
 	}
 
+	// The read API does not return schedule_spec.
+	if updatePlan.scheduleSpecChanged && plan.ComputeSpecs != nil {
+		if state.ComputeSpecs == nil {
+			state.ComputeSpecs = &models.ComputeSpecsModel{}
+		}
+		state.ComputeSpecs.ScheduleSpec = plan.ComputeSpecs.ScheduleSpec
+	}
+
 	return diags
 }
 
@@ -1381,10 +2086,8 @@ func buildInstanceUpdateParam(ctx context.Context, plan, state models.KafkaInsta
 
 	planVersion := plan.Version.ValueString()
 	if planVersion != "" && planVersion != state.Version.ValueString() {
-		version := planVersion
-		updateParam.Version = &version
+		updatePlan.upgradeVersion = planVersion
 		updatePlan.hasUpdate = true
-		updatePlan.shouldWait = true
 	}
 
 	if plan.Features != nil {
@@ -1401,17 +2104,31 @@ func buildInstanceUpdateParam(ctx context.Context, plan, state models.KafkaInsta
 		}
 		if metricsExporterChanged(planMetrics, stateMetrics) {
 			exporter, hasExporter := models.BuildMetricsExporterParam(planMetrics)
-			features := ensureFeatures()
-			if hasExporter {
+			if !hasExporter {
+				exporter = &client.InstanceMetricsExporterParam{}
+			}
+			// Exporters removed from the configuration are disabled explicitly,
+			// as an omitted exporter is left unchanged by the backend.
+			if stateMetrics != nil {
+				if planMetrics == nil {
+					planMetrics = &models.MetricsExporterModel{}
+				}
+				disabled := false
+				if stateMetrics.Prometheus != nil && planMetrics.Prometheus == nil {
+					exporter.Prometheus = &client.InstancePrometheusExporterParam{Enabled: &disabled}
+				}
+				if stateMetrics.CloudWatch != nil && planMetrics.CloudWatch == nil {
+					exporter.CloudWatch = &client.InstanceCloudWatchExporterParam{Enabled: &disabled}
+				}
+				if stateMetrics.Otlp != nil && planMetrics.Otlp == nil {
+					exporter.Oltp = &client.InstanceOLTPExporterParam{Enabled: &disabled}
+				}
+			}
+			if *exporter != (client.InstanceMetricsExporterParam{}) {
+				features := ensureFeatures()
 				features.MetricsExporter = exporter
 				updatePlan.hasUpdate = true
 				updatePlan.shouldWait = true
-			} else if stateMetrics != nil {
-				enabled := false
-				prom := &client.InstancePrometheusExporterParam{Enabled: &enabled}
-				features.MetricsExporter = &client.InstanceMetricsExporterParam{Prometheus: prom}
-				updatePlan.hasUpdate = true
-				updatePlan.shouldWait = true
 			}
 		}
 	}
@@ -1448,6 +2165,46 @@ func buildInstanceUpdateParam(ctx context.Context, plan, state models.KafkaInsta
 			updatePlan.hasUpdate = true
 			updatePlan.shouldWait = true
 		}
+
+		stateInboundRules := types.ListNull(models.InboundRuleObjectType)
+		if state.Features != nil {
+			stateInboundRules = state.Features.InboundRules
+		}
+		if !plan.Features.InboundRules.IsUnknown() && !plan.Features.InboundRules.IsNull() && !plan.Features.InboundRules.Equal(stateInboundRules) {
+			planRules, planRuleDiags := models.InboundRuleListToModels(ctx, plan.Features.InboundRules)
+			diags.Append(planRuleDiags...)
+			inboundRules, ruleDiags := models.BuildInboundRuleParams(ctx, planRules)
+			diags.Append(ruleDiags...)
+			if diags.HasError() {
+				return updateParam, updatePlan, diags
+			}
+			features := ensureFeatures()
+			features.InboundRules = inboundRules
+			updatePlan.hasUpdate = true
+			updatePlan.shouldWait = true
+			updatePlan.inboundRulesChanged = true
+		}
+
+		stateFailoverObject := types.ObjectNull(models.S3FailoverObjectType.AttrTypes)
+		if state.Features != nil {
+			stateFailoverObject = state.Features.S3Failover
+		}
+		if !plan.Features.S3Failover.IsUnknown() && !plan.Features.S3Failover.IsNull() && !plan.Features.S3Failover.Equal(stateFailoverObject) {
+			planFailover, planFailoverDiags := models.S3FailoverObjectToModel(ctx, plan.Features.S3Failover)
+			diags.Append(planFailoverDiags...)
+			if diags.HasError() {
+				return updateParam, updatePlan, diags
+			}
+			features := ensureFeatures()
+			features.S3Failover = models.BuildS3FailoverParam(planFailover)
+			updatePlan.hasUpdate = true
+			updatePlan.shouldWait = true
+			updatePlan.s3FailoverChanged = true
+		}
+		// validateS3FailoverUpdateContract only lets a disabled block be removed.
+		if plan.Features.S3Failover.IsNull() && !stateFailoverObject.IsNull() && !stateFailoverObject.IsUnknown() {
+			updatePlan.s3FailoverRemoved = true
+		}
 	}
 
 	if plan.ComputeSpecs != nil {
@@ -1486,6 +2243,16 @@ func buildInstanceUpdateParam(ctx context.Context, plan, state models.KafkaInsta
 		}
 	}
 
+	if plan.ComputeSpecs != nil && state.ComputeSpecs != nil {
+		if scheduleSpec, ok := knownStringValue(plan.ComputeSpecs.ScheduleSpec); ok && !stringAttrEqual(plan.ComputeSpecs.ScheduleSpec, state.ComputeSpecs.ScheduleSpec) {
+			spec := ensureSpec()
+			spec.ScheduleSpec = &scheduleSpec
+			updatePlan.hasUpdate = true
+			updatePlan.shouldWait = true
+			updatePlan.scheduleSpecChanged = true
+		}
+	}
+
 	if plan.ComputeSpecs != nil {
 		if shouldUpdateInstanceTypes(plan.ComputeSpecs, state.ComputeSpecs) {
 			var instanceTypes []string
@@ -1559,6 +2326,14 @@ func metricsExporterChanged(plan, state *models.MetricsExporterModel) bool {
 	if !prometheusExporterEqual(plan.Prometheus, state.Prometheus) {
 		return true
 	}
+	if (plan.CloudWatch == nil) != (state.CloudWatch == nil) ||
+		(plan.CloudWatch != nil && !stringAttrEqual(plan.CloudWatch.Namespace, state.CloudWatch.Namespace)) {
+		return true
+	}
+	if (plan.Otlp == nil) != (state.Otlp == nil) ||
+		(plan.Otlp != nil && !stringAttrEqual(plan.Otlp.EndPoint, state.Otlp.EndPoint)) {
+		return true
+	}
 	return false
 }
 
@@ -1686,3 +2461,5 @@ func int64AttrEqual(plan, state types.Int64) bool {
 }
 
 var waitForKafkaClusterToProvisionFunc = framework.WaitForKafkaClusterToProvision
+
+var waitForKafkaInstanceUpgradeFunc = framework.WaitForKafkaClusterUpgrade
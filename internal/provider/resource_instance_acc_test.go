@@ -15,6 +15,8 @@ package provider
 // Configuration sources (priority order):
 //   1. JSON file provided via -acc.config flag (see accConfigFile struct for schema)
 //   2. Environment variables listed above (legacy names fall back to AUTMQ_TEST_*)
+//   3. -acc.fake, which runs the tests offline against the in-memory control plane
+//      of internal/fakeserver (see fakeAccConfig)
 //
 // The helper loadAccConfig consolidates these sources and skips tests when required
 // parameters are absent instead of using placeholder values.
@@ -52,6 +54,9 @@ type accConfig struct {
 	ConnectorPlugin accConnectorPluginConfig `json:"connector_plugin"`
 	Version         string                   `json:"version"`
 	UpgradeVersion  string                   `json:"upgrade_version"`
+	// WaitScale is passed to the API client as client.Client.WaitScale. It is
+	// only set for the fake control plane.
+	WaitScale float64 `json:"-"`
 }
 
 type accK8SConfig struct {
@@ -155,6 +160,10 @@ type accFileSystemParam struct {
 func loadAccConfig(t *testing.T) accConfig {
 	configOnce.Do(func() {
 		path := strings.TrimSpace(*accConfigPath)
+		if path == "" && *accFake {
+			configData = fakeAccConfig()
+			return
+		}
 		if path == "" {
 			configErr = fmt.Errorf("-acc.config is required for acceptance tests")
 			return
@@ -6,10 +6,15 @@ import (
 	"terraform-provider-automq/client"
 	"terraform-provider-automq/internal/models"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,6 +44,13 @@ func testTableTopicObject(t *testing.T, model *models.TableTopicModel) types.Obj
 	return value
 }
 
+func testS3FailoverObject(t *testing.T, model *models.S3FailoverModel) types.Object {
+	t.Helper()
+	value, diags := models.S3FailoverModelToObject(context.Background(), model)
+	require.False(t, diags.HasError(), "failed to build s3 failover object: %v", diags)
+	return value
+}
+
 func testFileSystemObject(t *testing.T, model *models.FileSystemParamModel) types.Object {
 	t.Helper()
 	value, diags := models.FileSystemParamModelToObject(context.Background(), model)
@@ -83,6 +95,10 @@ type stubKafkaInstanceAPI struct {
 	getInstanceErr   error
 	getEndpointsErr  error
 	getEndpointsCall int
+	// updateErrs are returned by successive UpdateKafkaInstance calls.
+	updateErrs  []error
+	updateCalls int
+	updates     []client.InstanceUpdateParam
 }
 
 func (s *stubKafkaInstanceAPI) CreateKafkaInstance(context.Context, client.InstanceCreateParam) (*client.InstanceSummaryVO, error) {
@@ -101,8 +117,15 @@ func (s *stubKafkaInstanceAPI) DeleteKafkaInstance(context.Context, string) erro
 	return errors.New("unexpected DeleteKafkaInstance call")
 }
 
-func (s *stubKafkaInstanceAPI) UpdateKafkaInstance(context.Context, string, client.InstanceUpdateParam) error {
-	return errors.New("unexpected UpdateKafkaInstance call")
+func (s *stubKafkaInstanceAPI) UpdateKafkaInstance(_ context.Context, _ string, param client.InstanceUpdateParam) error {
+	s.updateCalls++
+	s.updates = append(s.updates, param)
+	if len(s.updateErrs) == 0 {
+		return errors.New("unexpected UpdateKafkaInstance call")
+	}
+	err := s.updateErrs[0]
+	s.updateErrs = s.updateErrs[1:]
+	return err
 }
 
 func (s *stubKafkaInstanceAPI) GetInstanceEndpoints(context.Context, string) ([]client.InstanceAccessInfoVO, error) {
@@ -137,6 +160,21 @@ func TestInstanceContractValidation(t *testing.T) {
 		assert.Len(t, diags.Errors(), 4)
 	})
 
+	t.Run("schedule spec must be a yaml or json object", func(t *testing.T) {
+		for spec, valid := range map[string]bool{
+			"tolerations:\n  - key: dedicated\n    operator: Exists": true,
+			`{"nodeSelector": {"pool": "kafka"}}`:                    true,
+			"nodeSelector: [unclosed":                                false,
+			"- tolerations":                                          false,
+			"{}":                                                     false,
+		} {
+			plan := newValidUsageBasedK8SPlan()
+			plan.ComputeSpecs.ScheduleSpec = types.StringValue(spec)
+			diags := validateScheduleSpecContract(&plan)
+			assert.Equal(t, !valid, diags.HasError(), "schedule_spec %q: %v", spec, diags)
+		}
+	})
+
 	t.Run("usage based iaas requires reserved node count and instance types", func(t *testing.T) {
 		plan := models.KafkaInstanceResourceModel{
 			ComputeSpecs: &models.ComputeSpecsModel{
@@ -223,7 +261,7 @@ func TestInstanceContractValidation(t *testing.T) {
 }
 
 func TestInstanceUpdateContractValidation(t *testing.T) {
-	t.Run("schedule spec change is rejected", func(t *testing.T) {
+	t.Run("schedule spec change is allowed", func(t *testing.T) {
 		plan := newValidUsageBasedK8SPlan()
 		state := newValidUsageBasedK8SPlan()
 		plan.ComputeSpecs.ScheduleSpec = types.StringValue("nodeSelector: {workload: new}")
@@ -231,9 +269,20 @@ func TestInstanceUpdateContractValidation(t *testing.T) {
 
 		diags := testValidateInstanceUpdateContract(plan, state)
 
+		assert.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+	})
+
+	t.Run("schedule spec removal is rejected", func(t *testing.T) {
+		plan := newValidUsageBasedK8SPlan()
+		state := newValidUsageBasedK8SPlan()
+		plan.ComputeSpecs.ScheduleSpec = types.StringNull()
+		state.ComputeSpecs.ScheduleSpec = types.StringValue("nodeSelector: {workload: old}")
+
+		diags := testValidateInstanceUpdateContract(plan, state)
+
 		require.True(t, diags.HasError())
 		assert.Equal(t, "Schedule Spec Update Error", diags.Errors()[0].Summary())
-		assert.Contains(t, diags.Errors()[0].Detail(), "cannot currently be updated")
+		assert.Contains(t, diags.Errors()[0].Detail(), "cannot be removed")
 	})
 
 	t.Run("removing instance config key is rejected", func(t *testing.T) {
@@ -398,16 +447,35 @@ func TestInstanceUpdateParamBuilder(t *testing.T) {
 		assert.False(t, updatePlan.shouldWait)
 	})
 
-	t.Run("version change builds wait patch", func(t *testing.T) {
+	t.Run("version change plans an upgrade outside the patch", func(t *testing.T) {
 		plan := newValidUsageBasedIAASPlan()
 		state := newValidUsageBasedIAASPlan()
 		plan.Version = types.StringValue("2.0.0")
 
+		param, updatePlan := testBuildInstanceUpdateParam(t, plan, state)
+		require.True(t, updatePlan.hasUpdate)
+		assert.Equal(t, "2.0.0", updatePlan.upgradeVersion)
+		assert.Nil(t, param.Version)
+		assert.Equal(t, client.InstanceUpdateParam{}, param)
+	})
+
+	t.Run("schedule spec change builds wait patch", func(t *testing.T) {
+		plan := newValidUsageBasedK8SPlan()
+		state := newValidUsageBasedK8SPlan()
+		plan.ComputeSpecs.ScheduleSpec = types.StringValue("nodeSelector: {workload: new}")
+		state.ComputeSpecs.ScheduleSpec = types.StringValue("nodeSelector: {workload: old}")
+
 		param, updatePlan := testBuildInstanceUpdateParam(t, plan, state)
 		require.True(t, updatePlan.hasUpdate)
 		assert.True(t, updatePlan.shouldWait)
-		require.NotNil(t, param.Version)
-		assert.Equal(t, "2.0.0", *param.Version)
+		require.NotNil(t, param.Spec)
+		require.NotNil(t, param.Spec.ScheduleSpec)
+		assert.Equal(t, "nodeSelector: {workload: new}", *param.Spec.ScheduleSpec)
+
+		refreshed := state
+		refreshed.ComputeSpecs = &models.ComputeSpecsModel{ScheduleSpec: state.ComputeSpecs.ScheduleSpec}
+		testApplyUpdateStatePreservation(t, &refreshed, plan, updatePlan)
+		assert.Equal(t, plan.ComputeSpecs.ScheduleSpec, refreshed.ComputeSpecs.ScheduleSpec)
 	})
 
 	t.Run("reserved aku change builds spec patch", func(t *testing.T) {
@@ -680,6 +748,156 @@ func TestInstanceRefreshState(t *testing.T) {
 	})
 }
 
+// Update retry tests cover wait_for_running_before_update retrying a PATCH
+// rejected because the instance is still changing.
+func TestInstanceUpdateRetriesStateConflicts(t *testing.T) {
+	var waits []string
+	original := waitForKafkaClusterToProvisionFunc
+	waitForKafkaClusterToProvisionFunc = func(_ context.Context, _ *client.Client, _ string, pending string, _ time.Duration) error {
+		waits = append(waits, pending)
+		return nil
+	}
+	t.Cleanup(func() { waitForKafkaClusterToProvisionFunc = original })
+
+	stateConflict := &client.ErrorResponse{Code: 409, APIError: client.APIError{ErrorModel: client.ErrorModel{Code: "INSTANCE_INVALID_STATE"}}}
+	require.ErrorIs(t, stateConflict, client.ErrStateConflict)
+	deadline := time.Now().Add(time.Minute)
+	fastRetries := &client.Client{RetryDelay: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	t.Run("retried once Running", func(t *testing.T) {
+		waits = nil
+		api := &stubKafkaInstanceAPI{updateErrs: []error{stateConflict, nil}}
+		r := &KafkaInstanceResource{api: api, client: fastRetries}
+		require.NoError(t, r.updateKafkaInstance(context.Background(), "inst-1", client.InstanceUpdateParam{}, true, deadline))
+		assert.Equal(t, 2, api.updateCalls)
+		assert.Equal(t, []string{models.StateChanging}, waits)
+	})
+
+	t.Run("not retried without the option", func(t *testing.T) {
+		waits = nil
+		api := &stubKafkaInstanceAPI{updateErrs: []error{stateConflict, nil}}
+		r := &KafkaInstanceResource{api: api, client: fastRetries}
+		require.ErrorIs(t, r.updateKafkaInstance(context.Background(), "inst-1", client.InstanceUpdateParam{}, false, deadline), client.ErrStateConflict)
+		assert.Equal(t, 1, api.updateCalls)
+		assert.Empty(t, waits)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		api := &stubKafkaInstanceAPI{updateErrs: []error{errors.New("boom"), nil}}
+		r := &KafkaInstanceResource{api: api, client: fastRetries}
+		require.Error(t, r.updateKafkaInstance(context.Background(), "inst-1", client.InstanceUpdateParam{}, true, deadline))
+		assert.Equal(t, 1, api.updateCalls)
+	})
+
+	t.Run("not retried past the deadline", func(t *testing.T) {
+		api := &stubKafkaInstanceAPI{updateErrs: []error{stateConflict, nil}}
+		r := &KafkaInstanceResource{api: api, client: fastRetries}
+		require.ErrorIs(t, r.updateKafkaInstance(context.Background(), "inst-1", client.InstanceUpdateParam{}, true, time.Now()), client.ErrStateConflict)
+		assert.Equal(t, 1, api.updateCalls)
+	})
+
+	t.Run("retries are capped", func(t *testing.T) {
+		waits = nil
+		conflicts := make([]error, maxStateConflictRetries+2)
+		for i := range conflicts {
+			conflicts[i] = stateConflict
+		}
+		api := &stubKafkaInstanceAPI{updateErrs: conflicts}
+		r := &KafkaInstanceResource{api: api, client: fastRetries}
+		err := r.updateKafkaInstance(context.Background(), "inst-1", client.InstanceUpdateParam{}, true, deadline)
+		require.ErrorIs(t, err, client.ErrStateConflict)
+		assert.Contains(t, err.Error(), "INSTANCE_INVALID_STATE")
+		assert.Equal(t, maxStateConflictRetries+1, api.updateCalls)
+		assert.Len(t, waits, maxStateConflictRetries)
+	})
+}
+
+// testPrivateState is an in-memory privateState.
+type testPrivateState map[string][]byte
+
+func (p testPrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p testPrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	if len(value) == 0 {
+		delete(p, key)
+	} else {
+		p[key] = value
+	}
+	return nil
+}
+
+// Pending operation tests cover resuming the wait of an instance whose create
+// or update returned before it was Running.
+func TestInstancePendingOperation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("marker round trip", func(t *testing.T) {
+		private := testPrivateState{}
+		require.False(t, setPendingOperation(ctx, private, models.StateCreating).HasError())
+		pending, diags := getPendingOperation(ctx, private)
+		require.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+		assert.Equal(t, models.StateCreating, pending)
+
+		require.False(t, setPendingOperation(ctx, private, "").HasError())
+		pending, _ = getPendingOperation(ctx, private)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("still pending after a failed wait", func(t *testing.T) {
+		api := &stubKafkaInstanceAPI{instance: &client.InstanceVO{State: testStringPtr(models.StateCreating)}}
+		assert.True(t, instanceStillPending(ctx, api, "inst-1", models.StateCreating))
+		api.instance.State = testStringPtr(models.StateError)
+		assert.False(t, instanceStillPending(ctx, api, "inst-1", models.StateCreating))
+		api.getInstanceErr = errors.New("boom")
+		assert.False(t, instanceStillPending(ctx, api, "inst-1", models.StateCreating))
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.True(t, instanceStillPending(canceled, api, "inst-1", models.StateCreating))
+	})
+
+	s := getKafkaInstanceResourceSchema(t)
+	state := tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}
+	require.False(t, state.SetAttribute(ctx, path.Root("id"), "inst-1").HasError())
+	require.False(t, state.SetAttribute(ctx, path.Root("status"), models.StateCreating).HasError())
+	plan := tfsdk.Plan{Schema: s, Raw: state.Raw}
+
+	t.Run("pending instance plans an update", func(t *testing.T) {
+		private := testPrivateState{}
+		require.False(t, setPendingOperation(ctx, private, models.StateCreating).HasError())
+		resp := &resource.ModifyPlanResponse{Plan: plan}
+		planPendingOperation(ctx, private, resource.ModifyPlanRequest{Plan: plan, State: state}, resp)
+		require.False(t, resp.Diagnostics.HasError(), "unexpected diagnostics: %v", resp.Diagnostics)
+
+		var status types.String
+		var endpoints types.List
+		resp.Plan.GetAttribute(ctx, path.Root("status"), &status)
+		resp.Plan.GetAttribute(ctx, path.Root("endpoints"), &endpoints)
+		assert.True(t, status.IsUnknown())
+		assert.True(t, endpoints.IsUnknown())
+	})
+
+	t.Run("no marker leaves the plan alone", func(t *testing.T) {
+		resp := &resource.ModifyPlanResponse{Plan: plan}
+		planPendingOperation(ctx, testPrivateState{}, resource.ModifyPlanRequest{Plan: plan, State: state}, resp)
+		assert.True(t, resp.Plan.Raw.Equal(plan.Raw))
+	})
+
+	t.Run("unknown values are saved as null", func(t *testing.T) {
+		created := tfsdk.State{Schema: s, Raw: state.Raw}
+		require.False(t, created.SetAttribute(ctx, path.Root("endpoints"), types.ListUnknown(types.ObjectType{AttrTypes: map[string]attr.Type{
+			"display_name": types.StringType, "network_type": types.StringType, "protocol": types.StringType, "mechanisms": types.StringType, "bootstrap_servers": types.StringType,
+		}})).HasError())
+		require.False(t, nullUnknownValues(&created).HasError())
+		assert.True(t, created.Raw.IsFullyKnown())
+		var status types.String
+		created.GetAttribute(ctx, path.Root("status"), &status)
+		assert.Equal(t, models.StateCreating, status.ValueString())
+	})
+}
+
 func newValidUsageBasedIAASPlan() models.KafkaInstanceResourceModel {
 	return models.KafkaInstanceResourceModel{
 		Name:        types.StringValue("test-instance"),
@@ -776,3 +994,297 @@ func mustStringMap(values map[string]string) types.Map {
 	}
 	return types.MapValueMust(types.StringType, attrs)
 }
+
+func TestCheckVersionUpgrade(t *testing.T) {
+	ctx := context.Background()
+	s := getKafkaInstanceResourceSchema(t)
+	withVersion := func(version string) tftypes.Value {
+		state := tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}
+		require.False(t, state.SetAttribute(ctx, path.Root("version"), version).HasError())
+		return state.Raw
+	}
+
+	for _, tt := range []struct {
+		name    string
+		from    string
+		to      string
+		summary string
+	}{
+		{name: "upgrade", from: "1.2.0", to: "1.10.0"},
+		{name: "unchanged", from: "1.2.0", to: "1.2.0"},
+		{name: "downgrade", from: "1.10.0", to: "1.2.0", summary: "Kafka Instance Downgrade Not Supported"},
+		{name: "invalid target", from: "1.2.0", to: "latest", summary: "Invalid Kafka Instance Version"},
+		{name: "unparsable current version", from: "custom", to: "1.2.0"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := tfsdk.Plan{Schema: s, Raw: withVersion(tt.to)}
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			checkVersionUpgrade(ctx, resource.ModifyPlanRequest{
+				Plan:  plan,
+				State: tfsdk.State{Schema: s, Raw: withVersion(tt.from)},
+			}, resp)
+			if tt.summary == "" {
+				assert.False(t, resp.Diagnostics.HasError(), "unexpected diagnostics: %v", resp.Diagnostics)
+				return
+			}
+			require.True(t, resp.Diagnostics.HasError())
+			assert.Equal(t, tt.summary, resp.Diagnostics[0].Summary())
+		})
+	}
+}
+
+func TestInstanceUpgrade(t *testing.T) {
+	var waitErr error
+	original := waitForKafkaInstanceUpgradeFunc
+	waitForKafkaInstanceUpgradeFunc = func(context.Context, *client.Client, string, string, time.Duration) error {
+		return waitErr
+	}
+	t.Cleanup(func() { waitForKafkaInstanceUpgradeFunc = original })
+
+	for _, tt := range []struct {
+		name       string
+		state      string
+		version    string
+		upgradeErr error
+		waitErr    error
+		summary    string
+	}{
+		{name: "completed", state: models.StateRunning, version: "1.3.0"},
+		{name: "rolled back", state: models.StateRunning, version: "1.2.0", summary: "Kafka Instance Upgrade Rolled Back"},
+		{name: "failed", state: models.StateError, version: "1.3.0", waitErr: errors.New("unexpected state 'Error'"), summary: "Kafka Instance Upgrade Failed"},
+		{name: "timed out", state: models.StateChanging, version: "1.2.0", waitErr: errors.New("timeout"), summary: "Client Error"},
+		{name: "rejected", upgradeErr: errors.New("boom"), summary: "Kafka Instance Upgrade Error"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			waitErr = tt.waitErr
+			api := &stubKafkaInstanceAPI{
+				instance:   &client.InstanceVO{State: testStringPtr(tt.state), Version: testStringPtr(tt.version)},
+				updateErrs: []error{tt.upgradeErr},
+			}
+			r := &KafkaInstanceResource{api: api}
+			diags := r.upgradeKafkaInstance(context.Background(), "inst-1", "1.2.0", "1.3.0", time.Minute)
+			version := "1.3.0"
+			assert.Equal(t, []client.InstanceUpdateParam{{Version: &version}}, api.updates)
+			if tt.summary == "" {
+				assert.False(t, diags.HasError(), "unexpected diagnostics: %v", diags)
+				return
+			}
+			require.True(t, diags.HasError())
+			assert.Equal(t, tt.summary, diags[0].Summary())
+		})
+	}
+}
+
+func TestInstanceS3Failover(t *testing.T) {
+	failover := func(enabled bool, storageType string, size int64) *models.S3FailoverModel {
+		model := &models.S3FailoverModel{
+			Enabled:           types.BoolValue(enabled),
+			StorageType:       types.StringNull(),
+			EbsVolumeSizeInGB: types.Int64Null(),
+		}
+		if storageType != "" {
+			model.StorageType = types.StringValue(storageType)
+		}
+		if size != 0 {
+			model.EbsVolumeSizeInGB = types.Int64Value(size)
+		}
+		return model
+	}
+	instance := func(walMode string, model *models.S3FailoverModel) models.KafkaInstanceResourceModel {
+		plan := newValidUsageBasedIAASPlan()
+		plan.Features.WalMode = types.StringValue(walMode)
+		plan.Features.S3Failover = testS3FailoverObject(t, model)
+		return plan
+	}
+
+	t.Run("plan validation", func(t *testing.T) {
+		for _, tt := range []struct {
+			name    string
+			plan    models.KafkaInstanceResourceModel
+			invalid bool
+		}{
+			{name: "enabled on S3WAL", plan: instance("S3WAL", failover(true, "EBS", 100))},
+			{name: "disabled without storage", plan: instance("S3WAL", failover(false, "", 0))},
+			{name: "not S3WAL", plan: instance("EBSWAL", failover(true, "EBS", 100)), invalid: true},
+			{name: "enabled without storage type", plan: instance("S3WAL", failover(true, "", 0)), invalid: true},
+			{name: "EBS without volume size", plan: instance("S3WAL", failover(true, "EBS", 0)), invalid: true},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				diags := validateS3FailoverContract(context.Background(), &tt.plan)
+				assert.Equal(t, tt.invalid, diags.HasError(), "diagnostics: %v", diags)
+			})
+		}
+	})
+
+	t.Run("update contract", func(t *testing.T) {
+		for _, tt := range []struct {
+			name    string
+			plan    *models.S3FailoverModel
+			state   *models.S3FailoverModel
+			invalid bool
+		}{
+			{name: "enable", plan: failover(true, "EBS", 100), state: nil},
+			{name: "disable", plan: failover(false, "EBS", 100), state: failover(true, "EBS", 100)},
+			{name: "grow volume", plan: failover(true, "EBS", 200), state: failover(true, "EBS", 100)},
+			{name: "shrink volume", plan: failover(true, "EBS", 50), state: failover(true, "EBS", 100), invalid: true},
+			{name: "change storage type", plan: failover(true, "S3", 100), state: failover(true, "EBS", 100), invalid: true},
+			{name: "remove enabled", plan: nil, state: failover(true, "EBS", 100), invalid: true},
+			{name: "remove disabled", plan: nil, state: failover(false, "", 0)},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				plan, state := instance("S3WAL", tt.plan), instance("S3WAL", tt.state)
+				diags := testValidateInstanceUpdateContract(plan, state)
+				require.Equal(t, tt.invalid, diags.HasError(), "diagnostics: %v", diags)
+				if tt.invalid {
+					assert.Equal(t, "S3 Failover Update Error", diags.Errors()[0].Summary())
+				}
+			})
+		}
+	})
+
+	t.Run("change builds wait patch", func(t *testing.T) {
+		plan, state := instance("S3WAL", failover(true, "EBS", 200)), instance("S3WAL", failover(true, "EBS", 100))
+		param, updatePlan := testBuildInstanceUpdateParam(t, plan, state)
+		require.True(t, updatePlan.hasUpdate)
+		assert.True(t, updatePlan.shouldWait)
+		require.NotNil(t, param.Features)
+		require.NotNil(t, param.Features.S3Failover)
+		require.NotNil(t, param.Features.S3Failover.EbsVolumeSizeInGB)
+		assert.Equal(t, int32(200), *param.Features.S3Failover.EbsVolumeSizeInGB)
+
+		_, updatePlan = testBuildInstanceUpdateParam(t, state, state)
+		assert.False(t, updatePlan.s3FailoverChanged)
+	})
+
+	t.Run("removing disabled failover is terraform only", func(t *testing.T) {
+		plan, state := instance("S3WAL", nil), instance("S3WAL", failover(false, "EBS", 100))
+		require.False(t, testValidateInstanceUpdateContract(plan, state).HasError())
+		param, updatePlan := testBuildInstanceUpdateParam(t, plan, state)
+		assert.Equal(t, client.InstanceUpdateParam{}, param)
+		assert.False(t, updatePlan.hasUpdate)
+		assert.False(t, updatePlan.s3FailoverChanged)
+		assert.True(t, updatePlan.s3FailoverRemoved)
+
+		_, updatePlan = testBuildInstanceUpdateParam(t, state, state)
+		assert.False(t, updatePlan.s3FailoverRemoved)
+	})
+}
+
+func TestInstanceInboundRulesAndListeners(t *testing.T) {
+	ctx := context.Background()
+	rules := func(cidrs ...string) types.List {
+		elements := make([]attr.Value, len(cidrs))
+		for i, cidr := range cidrs {
+			elements[i] = types.StringValue(cidr)
+		}
+		list, diags := models.InboundRuleModelsToList(ctx, []models.InboundRuleModel{{
+			ListenerName: types.StringValue("EXTERNAL"),
+			Cidrs:        types.SetValueMust(types.StringType, elements),
+		}})
+		require.False(t, diags.HasError(), "failed to build inbound rules: %v", diags)
+		return list
+	}
+	instance := func(inboundRules types.List) models.KafkaInstanceResourceModel {
+		plan := newValidUsageBasedIAASPlan()
+		plan.Features.InboundRules = inboundRules
+		plan.Features.ExtendListeners = types.ListNull(models.ExtendListenerObjectType)
+		return plan
+	}
+
+	t.Run("plan validation", func(t *testing.T) {
+		plan := instance(rules("10.0.0.0/16"))
+		assert.False(t, validateListenerContract(ctx, plan.Features).HasError())
+
+		plan = instance(rules("10.0.0.0/16", "10.0.0.1"))
+		diags := validateListenerContract(ctx, plan.Features)
+		require.True(t, diags.HasError())
+		assert.Contains(t, diags.Errors()[0].Detail(), `"10.0.0.1" is not a valid CIDR block`)
+
+		duplicated := append(rules("10.0.0.0/16").Elements(), rules("10.1.0.0/16").Elements()...)
+		plan = instance(types.ListValueMust(models.InboundRuleObjectType, duplicated))
+		diags = validateListenerContract(ctx, plan.Features)
+		require.True(t, diags.HasError())
+		assert.Contains(t, diags.Errors()[0].Detail(), `listener "EXTERNAL" already has a rule`)
+	})
+
+	t.Run("cidr change builds wait patch", func(t *testing.T) {
+		plan, state := instance(rules("10.0.0.0/16", "10.1.0.0/16")), instance(rules("10.0.0.0/16"))
+		require.False(t, testValidateInstanceUpdateContract(plan, state).HasError())
+		param, updatePlan := testBuildInstanceUpdateParam(t, plan, state)
+		require.True(t, updatePlan.hasUpdate)
+		assert.True(t, updatePlan.shouldWait)
+		require.NotNil(t, param.Features)
+		require.Len(t, param.Features.InboundRules, 1)
+		assert.ElementsMatch(t, []string{"10.0.0.0/16", "10.1.0.0/16"}, param.Features.InboundRules[0].Cidrs)
+
+		refreshed := state
+		refreshed.Features = &models.FeaturesModel{InboundRules: state.Features.InboundRules}
+		testApplyUpdateStatePreservation(t, &refreshed, plan, updatePlan)
+		assert.Equal(t, plan.Features.InboundRules, refreshed.Features.InboundRules)
+	})
+
+	t.Run("removing all rules is rejected", func(t *testing.T) {
+		diags := testValidateInstanceUpdateContract(instance(types.ListNull(models.InboundRuleObjectType)), instance(rules("10.0.0.0/16")))
+		require.True(t, diags.HasError())
+		assert.Equal(t, "Inbound Rules Update Error", diags.Errors()[0].Summary())
+	})
+}
+
+func TestInstanceMetricsExporters(t *testing.T) {
+	instance := func(metrics *models.MetricsExporterModel) models.KafkaInstanceResourceModel {
+		plan := newValidUsageBasedIAASPlan()
+		plan.Features.MetricsExporter = testMetricsExporterObject(t, metrics)
+		return plan
+	}
+	cloudWatch := &models.CloudWatchExporterModel{Namespace: types.StringValue("AutoMQ/Kafka")}
+	otlp := &models.OtlpExporterModel{EndPoint: types.StringValue("https://otel.example.com:4318")}
+
+	t.Run("any combination of exporters is valid", func(t *testing.T) {
+		for _, metrics := range []*models.MetricsExporterModel{
+			{CloudWatch: cloudWatch},
+			{Otlp: otlp},
+			{CloudWatch: cloudWatch, Otlp: otlp},
+		} {
+			plan := instance(metrics)
+			assert.False(t, validateInstanceContract(context.Background(), &plan).HasError())
+		}
+
+		plan := instance(&models.MetricsExporterModel{})
+		diags := validateInstanceContract(context.Background(), &plan)
+		require.True(t, diags.HasError())
+		assert.Contains(t, diags.Errors()[0].Detail(), "at least one of the prometheus, cloudwatch or otlp blocks")
+	})
+
+	t.Run("adding an exporter builds wait patch", func(t *testing.T) {
+		plan, state := instance(&models.MetricsExporterModel{CloudWatch: cloudWatch, Otlp: otlp}), instance(&models.MetricsExporterModel{CloudWatch: cloudWatch})
+		param, updatePlan := testBuildInstanceUpdateParam(t, plan, state)
+		require.True(t, updatePlan.hasUpdate)
+		assert.True(t, updatePlan.shouldWait)
+		require.NotNil(t, param.Features)
+		require.NotNil(t, param.Features.MetricsExporter)
+		assert.Equal(t, "AutoMQ/Kafka", *param.Features.MetricsExporter.CloudWatch.Namespace)
+		require.NotNil(t, param.Features.MetricsExporter.Oltp)
+		assert.True(t, *param.Features.MetricsExporter.Oltp.Enabled)
+		assert.Equal(t, "https://otel.example.com:4318", *param.Features.MetricsExporter.Oltp.EndPoint)
+	})
+
+	t.Run("removed exporters are disabled", func(t *testing.T) {
+		plan, state := instance(&models.MetricsExporterModel{Otlp: otlp}), instance(&models.MetricsExporterModel{CloudWatch: cloudWatch, Otlp: otlp})
+		param, updatePlan := testBuildInstanceUpdateParam(t, plan, state)
+		require.True(t, updatePlan.hasUpdate)
+		require.NotNil(t, param.Features)
+		require.NotNil(t, param.Features.MetricsExporter)
+		require.NotNil(t, param.Features.MetricsExporter.CloudWatch)
+		assert.False(t, *param.Features.MetricsExporter.CloudWatch.Enabled)
+		assert.Nil(t, param.Features.MetricsExporter.CloudWatch.Namespace)
+		assert.True(t, *param.Features.MetricsExporter.Oltp.Enabled)
+		assert.Nil(t, param.Features.MetricsExporter.Prometheus)
+	})
+
+	t.Run("unchanged exporters build no patch", func(t *testing.T) {
+		metrics := &models.MetricsExporterModel{CloudWatch: cloudWatch, Otlp: otlp}
+		param, updatePlan := testBuildInstanceUpdateParam(t, instance(metrics), instance(metrics))
+		assert.False(t, updatePlan.hasUpdate)
+		assert.Nil(t, param.Features)
+	})
+}
@@ -86,8 +86,12 @@ func TestValidateConfigAcceptsUnknownNestedValues(t *testing.T) {
 				}),
 				MetricsExporter: types.ObjectUnknown(models.MetricsExporterObjectType.AttrTypes),
 				TableTopic:      types.ObjectUnknown(models.TableTopicObjectType.AttrTypes),
+				S3Failover:      types.ObjectUnknown(models.S3FailoverObjectType.AttrTypes),
+				InboundRules:    types.ListUnknown(models.InboundRuleObjectType),
+				ExtendListeners: types.ListUnknown(models.ExtendListenerObjectType),
 			},
-			Tags: types.MapNull(types.StringType),
+			Tags:    types.MapNull(types.StringType),
+			TagsAll: types.MapNull(types.StringType),
 			Timeouts: timeouts.Value{Object: types.ObjectNull(map[string]attr.Type{
 				"create": types.StringType,
 				"delete": types.StringType,
@@ -253,6 +257,7 @@ func TestSpecificationUpdateParamMatchesBackendPatchContract(t *testing.T) {
 		"ReservedNodeCount": {},
 		"NodeConfig":        {},
 		"FileSystem":        {},
+		"ScheduleSpec":      {},
 	}
 
 	if updateType.NumField() != len(expectedFields) {
@@ -376,8 +381,8 @@ func TestBuildInstanceUpdateParamOnlyIncludesBackendPatchFields(t *testing.T) {
 	if updateParam.Description == nil || *updateParam.Description != "new-description" {
 		t.Fatalf("expected description update, got %#v", updateParam.Description)
 	}
-	if updateParam.Version == nil || *updateParam.Version != "1.1.0" {
-		t.Fatalf("expected version update, got %#v", updateParam.Version)
+	if updateParam.Version != nil || updatePlan.upgradeVersion != "1.1.0" {
+		t.Fatalf("expected version upgrade outside the patch, got %#v and %q", updateParam.Version, updatePlan.upgradeVersion)
 	}
 	if updateParam.Spec == nil {
 		t.Fatalf("expected spec update")
@@ -447,6 +452,53 @@ func TestBuildInstanceUpdateParamReturnsNoUpdateForCreateOnlyChanges(t *testing.
 	}
 }
 
+func TestCheckTagsUpdate(t *testing.T) {
+	ctx := context.Background()
+	s := getKafkaInstanceResourceSchema(t)
+	tags := func(values map[string]string) types.Map {
+		elements := map[string]attr.Value{}
+		for key, value := range values {
+			elements[key] = types.StringValue(value)
+		}
+		return types.MapValueMust(types.StringType, elements)
+	}
+	withTags := func(tags, tagsAll types.Map) tftypes.Value {
+		state := tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}
+		if diags := state.SetAttribute(ctx, path.Root("tags"), tags); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if diags := state.SetAttribute(ctx, path.Root("tags_all"), tagsAll); diags.HasError() {
+			t.Fatal(diags)
+		}
+		return state.Raw
+	}
+	state := tfsdk.State{Schema: s, Raw: withTags(
+		tags(map[string]string{"team": "stremaing"}),
+		tags(map[string]string{"team": "stremaing", "cost-center": "cc-42"}),
+	)}
+
+	plan := tfsdk.Plan{Schema: s, Raw: withTags(
+		tags(map[string]string{"team": "streaming"}),
+		tags(map[string]string{"team": "streaming", "cost-center": "cc-42"}),
+	)}
+	resp := &resource.ModifyPlanResponse{Plan: plan}
+	checkTagsUpdate(ctx, resource.ModifyPlanRequest{Plan: plan, State: state}, resp)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Kafka Instance Tags Update Not Supported" {
+		t.Fatalf("changed tags: diagnostics %v", resp.Diagnostics)
+	}
+
+	// A default_tags change alone plans no change.
+	plan = tfsdk.Plan{Schema: s, Raw: withTags(
+		tags(map[string]string{"team": "stremaing"}),
+		tags(map[string]string{"team": "stremaing", "cost-center": "cc-43"}),
+	)}
+	resp = &resource.ModifyPlanResponse{Plan: plan}
+	checkTagsUpdate(ctx, resource.ModifyPlanRequest{Plan: plan, State: state}, resp)
+	if resp.Diagnostics.HasError() || !resp.Plan.Raw.Equal(state.Raw) {
+		t.Fatalf("default tags change: diagnostics %v, plan %s", resp.Diagnostics, resp.Plan.Raw)
+	}
+}
+
 func TestValidateInstanceUpdateContractRejectsInstanceConfigRemoval(t *testing.T) {
 	stateConfig := types.MapValueMust(types.StringType, map[string]attr.Value{
 		"retained": types.StringValue("value"),
@@ -25,6 +25,7 @@ import (
 
 var _ resource.Resource = &KafkaLinkResource{}
 var _ resource.ResourceWithImportState = &KafkaLinkResource{}
+var _ resource.ResourceWithModifyPlan = &KafkaLinkResource{}
 
 func NewKafkaLinkResource() resource.Resource {
 	return &KafkaLinkResource{}
@@ -33,6 +34,7 @@ func NewKafkaLinkResource() resource.Resource {
 // KafkaLinkResource implements automq_kafka_link.
 type KafkaLinkResource struct {
 	client *client.Client
+	framework.WithDefaultEnvironment
 }
 
 func (r *KafkaLinkResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -45,9 +47,10 @@ func (r *KafkaLinkResource) Schema(ctx context.Context, req resource.SchemaReque
 		MarkdownDescription: "Manage Kafka links for mirroring topics and consumer groups between AutoMQ instances and external Kafka clusters.",
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page.",
-				Required:            true,
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page. Defaults to the provider `environment_id`.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"instance_id": schema.StringAttribute{
 				MarkdownDescription: "Kafka instance identifier that owns the link.",
@@ -148,15 +151,19 @@ func (r *KafkaLinkResource) Configure(ctx context.Context, req resource.Configur
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *client.Client, got: %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *framework.ProviderData, got: %T", req.ProviderData))
 		return
 	}
-	r.client = client
+	r.client = data.Client
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
 }
 
 func (r *KafkaLinkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_link.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan models.KafkaLinkResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -173,7 +180,9 @@ func (r *KafkaLinkResource) Create(ctx context.Context, req resource.CreateReque
 
 	out, err := r.client.CreateKafkaLink(ctx, plan.InstanceID.ValueString(), param)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create Kafka link %q: %s", plan.LinkID.ValueString(), err))
+		framework.AddAPIError(&resp.Diagnostics, "Client Error", fmt.Sprintf("Unable to create Kafka link %q: %s", plan.LinkID.ValueString(), err), err, framework.APIErrorAttributes{
+			client.ErrConflict: path.Root("link_id"),
+		})
 		return
 	}
 
@@ -187,6 +196,9 @@ func (r *KafkaLinkResource) Create(ctx context.Context, req resource.CreateReque
 }
 
 func (r *KafkaLinkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_link.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaLinkResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -214,10 +226,16 @@ func (r *KafkaLinkResource) Read(ctx context.Context, req resource.ReadRequest,
 }
 
 func (r *KafkaLinkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_link.Update")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	resp.Diagnostics.AddError("Update Not Supported", "automq_kafka_link does not support in-place updates. Please recreate the resource after making configuration changes.")
 }
 
 func (r *KafkaLinkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_link.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaLinkResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
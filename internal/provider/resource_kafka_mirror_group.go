@@ -21,6 +21,7 @@ import (
 
 var _ resource.Resource = &KafkaMirrorGroupResource{}
 var _ resource.ResourceWithImportState = &KafkaMirrorGroupResource{}
+var _ resource.ResourceWithModifyPlan = &KafkaMirrorGroupResource{}
 
 func NewKafkaMirrorGroupResource() resource.Resource {
 	return &KafkaMirrorGroupResource{}
@@ -29,6 +30,7 @@ func NewKafkaMirrorGroupResource() resource.Resource {
 // KafkaMirrorGroupResource manages mirrored consumer groups on a Kafka link.
 type KafkaMirrorGroupResource struct {
 	client *client.Client
+	framework.WithDefaultEnvironment
 }
 
 func (r *KafkaMirrorGroupResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -40,9 +42,10 @@ func (r *KafkaMirrorGroupResource) Schema(ctx context.Context, req resource.Sche
 		MarkdownDescription: "Manage mirrored consumer groups within a Kafka link.",
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page.",
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page. Defaults to the provider `environment_id`.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"instance_id": schema.StringAttribute{
 				Required:            true,
@@ -80,15 +83,19 @@ func (r *KafkaMirrorGroupResource) Configure(ctx context.Context, req resource.C
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *client.Client, got: %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *framework.ProviderData, got: %T", req.ProviderData))
 		return
 	}
-	r.client = client
+	r.client = data.Client
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
 }
 
 func (r *KafkaMirrorGroupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_mirror_group.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan models.KafkaMirrorGroupResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -100,7 +107,9 @@ func (r *KafkaMirrorGroupResource) Create(ctx context.Context, req resource.Crea
 	in := models.BuildMirrorGroupCreateParam(&plan)
 	result, err := r.client.CreateKafkaLinkMirrorGroups(ctx, plan.InstanceID.ValueString(), plan.LinkID.ValueString(), in)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create mirror group %q: %s", plan.SourceGroupID.ValueString(), err))
+		framework.AddAPIError(&resp.Diagnostics, "Client Error", fmt.Sprintf("Unable to create mirror group %q: %s", plan.SourceGroupID.ValueString(), err), err, framework.APIErrorAttributes{
+			client.ErrConflict: path.Root("source_group_id"),
+		})
 		return
 	}
 
@@ -124,6 +133,9 @@ func (r *KafkaMirrorGroupResource) Create(ctx context.Context, req resource.Crea
 }
 
 func (r *KafkaMirrorGroupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_mirror_group.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaMirrorGroupResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -146,10 +158,16 @@ func (r *KafkaMirrorGroupResource) Read(ctx context.Context, req resource.ReadRe
 }
 
 func (r *KafkaMirrorGroupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_mirror_group.Update")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	resp.Diagnostics.AddError("Update Not Supported", "automq_kafka_mirror_group does not support in-place updates. Recreate the resource to apply changes.")
 }
 
 func (r *KafkaMirrorGroupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_mirror_group.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaMirrorGroupResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
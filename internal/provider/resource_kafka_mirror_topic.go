@@ -23,6 +23,7 @@ import (
 
 var _ resource.Resource = &KafkaMirrorTopicResource{}
 var _ resource.ResourceWithImportState = &KafkaMirrorTopicResource{}
+var _ resource.ResourceWithModifyPlan = &KafkaMirrorTopicResource{}
 
 func NewKafkaMirrorTopicResource() resource.Resource {
 	return &KafkaMirrorTopicResource{}
@@ -31,6 +32,7 @@ func NewKafkaMirrorTopicResource() resource.Resource {
 // KafkaMirrorTopicResource manages mirrored topics for a Kafka link.
 type KafkaMirrorTopicResource struct {
 	client *client.Client
+	framework.WithDefaultEnvironment
 }
 
 func (r *KafkaMirrorTopicResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -42,9 +44,10 @@ func (r *KafkaMirrorTopicResource) Schema(ctx context.Context, req resource.Sche
 		MarkdownDescription: "Manage mirrored topics within a Kafka link.",
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page.",
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page. Defaults to the provider `environment_id`.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"instance_id": schema.StringAttribute{
 				Required:            true,
@@ -89,15 +92,19 @@ func (r *KafkaMirrorTopicResource) Configure(ctx context.Context, req resource.C
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *client.Client, got: %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *framework.ProviderData, got: %T", req.ProviderData))
 		return
 	}
-	r.client = client
+	r.client = data.Client
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
 }
 
 func (r *KafkaMirrorTopicResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_mirror_topic.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan models.KafkaMirrorTopicResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -109,7 +116,9 @@ func (r *KafkaMirrorTopicResource) Create(ctx context.Context, req resource.Crea
 	in := models.BuildMirrorTopicCreateParam(&plan)
 	result, err := r.client.CreateKafkaLinkMirrorTopics(ctx, plan.InstanceID.ValueString(), plan.LinkID.ValueString(), in)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create mirror topic %q: %s", plan.SourceTopicName.ValueString(), err))
+		framework.AddAPIError(&resp.Diagnostics, "Client Error", fmt.Sprintf("Unable to create mirror topic %q: %s", plan.SourceTopicName.ValueString(), err), err, framework.APIErrorAttributes{
+			client.ErrConflict: path.Root("source_topic_name"),
+		})
 		return
 	}
 
@@ -140,6 +149,9 @@ func (r *KafkaMirrorTopicResource) Create(ctx context.Context, req resource.Crea
 }
 
 func (r *KafkaMirrorTopicResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_mirror_topic.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaMirrorTopicResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -162,6 +174,9 @@ func (r *KafkaMirrorTopicResource) Read(ctx context.Context, req resource.ReadRe
 }
 
 func (r *KafkaMirrorTopicResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_mirror_topic.Update")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan, state models.KafkaMirrorTopicResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -201,6 +216,9 @@ func (r *KafkaMirrorTopicResource) Update(ctx context.Context, req resource.Upda
 }
 
 func (r *KafkaMirrorTopicResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_mirror_topic.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaMirrorTopicResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
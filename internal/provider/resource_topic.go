@@ -25,6 +25,7 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &KafkaTopicResource{}
 var _ resource.ResourceWithImportState = &KafkaTopicResource{}
+var _ resource.ResourceWithModifyPlan = &KafkaTopicResource{}
 
 func NewKafkaTopicResource() resource.Resource {
 	return &KafkaTopicResource{}
@@ -33,6 +34,7 @@ func NewKafkaTopicResource() resource.Resource {
 // KafkaTopicResource defines the resource implementation.
 type KafkaTopicResource struct {
 	client *client.Client
+	framework.WithDefaultEnvironment
 }
 
 func (r *KafkaTopicResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -49,9 +51,10 @@ func (r *KafkaTopicResource) Schema(ctx context.Context, req resource.SchemaRequ
 
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page.",
-				Required:            true,
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page. Defaults to the provider `environment_id`.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"kafka_instance_id": schema.StringAttribute{
 				MarkdownDescription: "Target Kafka instance ID (e.g. `kf-xxxxx`). Each instance represents a Kafka cluster. Find this on the AutoMQ console instance list or detail page.",
@@ -83,6 +86,7 @@ func (r *KafkaTopicResource) Schema(ctx context.Context, req resource.SchemaRequ
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"deletion_protection": framework.DeletionProtectionAttribute("topic"),
 		},
 	}
 }
@@ -91,18 +95,27 @@ func (r *KafkaTopicResource) Configure(ctx context.Context, req resource.Configu
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *http.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *framework.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
-	r.client = client
+	r.client = data.Client
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
+}
+
+func (r *KafkaTopicResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.WithDefaultEnvironment.ModifyPlan(ctx, req, resp)
+	framework.CheckDeletionProtection(ctx, req, resp)
 }
 
 func (r *KafkaTopicResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_topic.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var topic models.KafkaTopicResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &topic)...)
@@ -118,7 +131,10 @@ func (r *KafkaTopicResource) Create(ctx context.Context, req resource.CreateRequ
 
 	out, err := r.client.CreateKafkaTopic(ctx, instanceId, in)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create Kafka topic %q, got error: %s", topic.Name.ValueString(), err))
+		framework.AddAPIError(&resp.Diagnostics, "Client Error", fmt.Sprintf("Unable to create Kafka topic %q, got error: %s", topic.Name.ValueString(), err), err, framework.APIErrorAttributes{
+			client.ErrConflict:         path.Root("name"),
+			client.ErrInvalidParameter: path.Root("configs"),
+		})
 		return
 	}
 
@@ -133,6 +149,9 @@ func (r *KafkaTopicResource) Create(ctx context.Context, req resource.CreateRequ
 }
 
 func (r *KafkaTopicResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_topic.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var data models.KafkaTopicResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -161,10 +180,18 @@ func (r *KafkaTopicResource) Read(ctx context.Context, req resource.ReadRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	// Imported topics and states written before deletion_protection existed
+	// have none.
+	if data.DeletionProtection.IsNull() {
+		data.DeletionProtection = types.BoolValue(false)
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *KafkaTopicResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_topic.Update")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan, state models.KafkaTopicResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -189,7 +216,9 @@ func (r *KafkaTopicResource) Update(ctx context.Context, req resource.UpdateRequ
 		in.Partition = planPartition
 		err := r.client.UpdateKafkaTopicPartition(ctx, instanceId, topicId, in)
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update Kafka topic %q, got error: %s", topicId, err))
+			framework.AddAPIError(&resp.Diagnostics, "Client Error", fmt.Sprintf("Unable to update Kafka topic %q, got error: %s", topicId, err), err, framework.APIErrorAttributes{
+				client.ErrInvalidParameter: path.Root("partition"),
+			})
 			return
 		}
 
@@ -220,7 +249,9 @@ func (r *KafkaTopicResource) Update(ctx context.Context, req resource.UpdateRequ
 
 		_, err := r.client.UpdateKafkaTopicConfig(ctx, instanceId, topicId, in)
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update Kafka topic %q, got error: %s", topicId, err))
+			framework.AddAPIError(&resp.Diagnostics, "Client Error", fmt.Sprintf("Unable to update Kafka topic %q, got error: %s", topicId, err), err, framework.APIErrorAttributes{
+				client.ErrInvalidParameter: path.Root("configs"),
+			})
 			return
 		}
 
@@ -234,9 +265,13 @@ func (r *KafkaTopicResource) Update(ctx context.Context, req resource.UpdateRequ
 }
 
 func (r *KafkaTopicResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_topic.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var state models.KafkaTopicResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(framework.DeletionProtectionError(state.DeletionProtection)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
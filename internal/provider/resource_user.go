@@ -22,6 +22,7 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &KafkaUserResource{}
 var _ resource.ResourceWithImportState = &KafkaUserResource{}
+var _ resource.ResourceWithModifyPlan = &KafkaUserResource{}
 
 func NewKafkaUserResource() resource.Resource {
 	return &KafkaUserResource{}
@@ -30,6 +31,7 @@ func NewKafkaUserResource() resource.Resource {
 // KafkaUserResource defines the resource implementation.
 type KafkaUserResource struct {
 	client *client.Client
+	framework.WithDefaultEnvironment
 }
 
 func (r *KafkaUserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -46,9 +48,10 @@ func (r *KafkaUserResource) Schema(ctx context.Context, req resource.SchemaReque
 
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page.",
-				Required:            true,
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				MarkdownDescription: "Target AutoMQ BYOC environment identifier (e.g. `env-xxxxx`). Find this on the AutoMQ console System Settings page. Defaults to the provider `environment_id`.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"kafka_instance_id": schema.StringAttribute{
 				MarkdownDescription: "Target Kafka instance ID (e.g. `kf-xxxxx`). Each instance represents a Kafka cluster. Find this on the AutoMQ console instance list or detail page.",
@@ -87,18 +90,22 @@ func (r *KafkaUserResource) Configure(ctx context.Context, req resource.Configur
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*framework.ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *http.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *framework.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
-	r.client = client
+	r.client = data.Client
+	r.SetDefaultEnvironmentID(data.EnvironmentID)
 }
 
 func (r *KafkaUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_user.Create")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var plan models.KafkaUserResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
@@ -115,7 +122,9 @@ func (r *KafkaUserResource) Create(ctx context.Context, req resource.CreateReque
 
 	out, err := r.client.CreateKafkaUser(ctx, instanceId, in)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create Kafka user", err.Error())
+		framework.AddAPIError(&resp.Diagnostics, "Failed to create Kafka user", err.Error(), err, framework.APIErrorAttributes{
+			client.ErrConflict: path.Root("username"),
+		})
 		return
 	}
 
@@ -125,6 +134,9 @@ func (r *KafkaUserResource) Create(ctx context.Context, req resource.CreateReque
 }
 
 func (r *KafkaUserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_user.Read")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var data models.KafkaUserResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -156,6 +168,9 @@ func (r *KafkaUserResource) Update(ctx context.Context, req resource.UpdateReque
 }
 
 func (r *KafkaUserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := framework.StartSpan(ctx, "automq_kafka_user.Delete")
+	defer framework.EndSpan(span, &resp.Diagnostics)
+
 	var data models.KafkaUserResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
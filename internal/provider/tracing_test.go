@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"terraform-provider-automq/client"
+	"terraform-provider-automq/internal/framework"
+	"terraform-provider-automq/internal/models"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpCollector is an in-process OTLP/HTTP trace receiver.
+type otlpCollector struct {
+	mu    sync.Mutex
+	spans map[string]*tracepb.Span
+}
+
+func (c *otlpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	export := &collectortrace.ExportTraceServiceRequest{}
+	if r.URL.Path != "/v1/traces" || proto.Unmarshal(body, export) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range export.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, span := range ss.Spans {
+				c.spans[span.Name] = span
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(nil)
+}
+
+func TestTracingExportsOperationAndRequestSpans(t *testing.T) {
+	collector := &otlpCollector{spans: map[string]*tracepb.Span{}}
+	collectorServer := httptest.NewServer(collector)
+	defer collectorServer.Close()
+
+	states := []string{models.StateCreating, models.StateRunning}
+	controlPlane := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		state := states[0]
+		if len(states) > 1 {
+			states = states[1:]
+		}
+		_, _ = w.Write([]byte(`{"instanceId":"kf-1","state":"` + state + `"}`))
+	}))
+	defer controlPlane.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collectorServer.URL)
+	ctx := context.Background()
+	shutdown, err := SetupTracing(ctx, "test")
+	if err != nil {
+		t.Fatalf("SetupTracing() error = %v", err)
+	}
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	c, _ := client.NewClient(ctx, controlPlane.URL, client.AuthCredentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"})
+	opCtx, span := framework.StartSpan(context.WithValue(ctx, client.EnvIdKey, "env-1"), "automq_kafka_instance.Create")
+	refresh := framework.KafkaClusterStatus(opCtx, c, "kf-1", models.StateRunning)
+	for i := 0; i < 2; i++ {
+		if _, _, err := refresh(); err != nil {
+			t.Fatalf("refresh() error = %v", err)
+		}
+	}
+	var diags diag.Diagnostics
+	framework.EndSpan(span, &diags)
+
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	operation, request := collector.spans["automq_kafka_instance.Create"], collector.spans["GET"]
+	if operation == nil || request == nil {
+		t.Fatalf("exported spans = %v, want the operation and request spans", collector.spans)
+	}
+	if string(request.ParentSpanId) != string(operation.SpanId) {
+		t.Error("request span is not a child of the operation span")
+	}
+	attrs := map[string]string{}
+	for _, kv := range request.Attributes {
+		attrs[kv.Key] = kv.Value.String()
+	}
+	if attrs["automq.request_id"] == "" || attrs["http.response.status_code"] == "" {
+		t.Errorf("request span attributes = %v", attrs)
+	}
+	if len(operation.Events) != 2 {
+		t.Fatalf("operation span has %d events, want one per state transition", len(operation.Events))
+	}
+}
+
+func TestSetupTracingDisabledWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	shutdown, err := SetupTracing(context.Background(), "test")
+	if err != nil || shutdown(context.Background()) != nil {
+		t.Fatalf("SetupTracing() error = %v", err)
+	}
+}
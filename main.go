@@ -46,8 +46,17 @@ func main() {
 		Debug:   debug,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	ctx := context.Background()
+	shutdownTracing, err := provider.SetupTracing(ctx, version)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
 
+	err = providerserver.Serve(ctx, provider.New(version), opts)
+
+	if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+		log.Printf("failed to flush traces: %s", shutdownErr)
+	}
 	if err != nil {
 		log.Fatal(err.Error())
 	}